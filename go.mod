@@ -4,13 +4,19 @@ go 1.25.4
 
 require (
 	gioui.org v0.9.0
+	github.com/cespare/xxhash/v2 v2.3.0
 	github.com/charlievieth/fastwalk v1.0.14
 	github.com/fsnotify/fsnotify v1.9.0
 	github.com/jdeng/goheif v0.0.0-20251001174315-babb64285736
 	github.com/justyntemme/organelle v0.0.0-20251202160832-7c93df124207
+	github.com/klauspost/compress v1.18.0
+	github.com/pkg/sftp v1.13.9
 	github.com/rodrigocfd/windigo v0.2.3
 	github.com/yuin/goldmark v1.7.13
+	golang.org/x/crypto v0.42.0
 	golang.org/x/image v0.26.0
+	golang.org/x/net v0.43.0
+	golang.org/x/sys v0.36.0
 	modernc.org/sqlite v1.40.1
 )
 
@@ -19,13 +25,13 @@ require (
 	github.com/dustin/go-humanize v1.0.1 // indirect
 	github.com/go-text/typesetting v0.3.0 // indirect
 	github.com/google/uuid v1.6.0 // indirect
+	github.com/kr/fs v0.1.0 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/ncruces/go-strftime v0.1.9 // indirect
 	github.com/remyoudompheng/bigfft v0.0.0-20230129092748-24d4a6f8daec // indirect
 	golang.org/x/exp v0.0.0-20250620022241-b7579e27df2b // indirect
 	golang.org/x/exp/shiny v0.0.0-20250408133849-7e4ce0ab07d0 // indirect
-	golang.org/x/sys v0.36.0 // indirect
-	golang.org/x/text v0.24.0 // indirect
+	golang.org/x/text v0.29.0 // indirect
 	modernc.org/libc v1.66.10 // indirect
 	modernc.org/mathutil v1.7.1 // indirect
 	modernc.org/memory v1.11.0 // indirect
@@ -0,0 +1,292 @@
+package app
+
+import (
+	"context"
+	"errors"
+	iofs "io/fs"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/justyntemme/razor/internal/debug"
+	"github.com/justyntemme/razor/internal/fs"
+	"github.com/justyntemme/razor/internal/store"
+	"github.com/justyntemme/razor/internal/ui"
+)
+
+// readOnlyDir reports whether dir is an archive or a folder inside one,
+// where nothing can be created, renamed or deleted
+func readOnlyDir(dir string) bool {
+	_, _, ok := fs.SplitArchivePath(dir)
+	return ok
+}
+
+// openFile opens a file with its default application. Archives are browsed
+// like folders instead, and files inside archives or on remote storage are
+// copied to the disk first.
+func (o *Orchestrator) openFile(path string) {
+	switch {
+	case fs.InArchive(path):
+		go o.openCopy(path)
+		return
+	case fs.IsRemote(path):
+		if info, err := fs.Stat(path); err == nil && !info.IsDir() {
+			go o.openCopy(path)
+			return
+		}
+	case fs.IsArchive(path):
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			o.resetUIState()
+			o.navCtrl.Navigate(path)
+			return
+		}
+	}
+	if err := platformOpen(path); err != nil {
+		log.Printf("Error opening file: %v", err)
+	}
+	// Track in recent files
+	o.store.RequestChan <- store.Request{Op: store.AddRecentFile, Path: path}
+}
+
+// openCopy copies a file inside an archive or on remote storage to a
+// temporary folder and opens the copy. Changes to the copy don't find their
+// way back.
+func (o *Orchestrator) openCopy(path string) {
+	dir, err := os.MkdirTemp("", "razor-open-*")
+	if err != nil {
+		o.ui.ShowError("Error opening file: " + err.Error())
+		return
+	}
+	dst := filepath.Join(dir, filepath.Base(path))
+	if err := fs.CopyTree(path, dst, func(int64) error { return nil }, func() {}); err != nil {
+		os.RemoveAll(dir)
+		o.ui.ShowError("Error opening file: " + err.Error())
+		return
+	}
+	debug.Log(debug.APP, "openCopy: %s copied to %s", path, dst)
+	if err := platformOpen(dst); err != nil {
+		log.Printf("Error opening file: %v", err)
+	}
+}
+
+// extractMember copies the file or folder src inside an archive to dst on
+// disk, counting progress on j
+func extractMember(j *Job, src, dst string) error {
+	archive, member, _ := fs.SplitArchivePath(src)
+	a, err := fs.OpenArchive(archive)
+	if err != nil {
+		return err
+	}
+	return a.Extract(member, dst, j.progress, j.fileDone)
+}
+
+// vfsTreeSize returns the total size and number of files of the file or
+// folder path inside an archive or on remote storage
+func vfsTreeSize(path string) (bytes, files int64) {
+	fsys, name, err := fs.Lookup(path)
+	if err != nil {
+		return 0, 0
+	}
+	iofs.WalkDir(fsys, name, func(_ string, d iofs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			bytes += info.Size()
+			files++
+		}
+		return nil
+	})
+	return bytes, files
+}
+
+// doCompress queues a job writing paths to a new archive named name in the
+// current directory, its format given by the extension
+func (o *Orchestrator) doCompress(paths []string, name string) {
+	if len(paths) == 0 || name == "" {
+		return
+	}
+	dir := o.state.CurrentPath
+	if readOnlyDir(dir) {
+		o.ui.ShowError("Cannot compress: " + fs.ErrReadOnly.Error())
+		return
+	}
+	if fs.IsRemote(dir) {
+		o.ui.ShowError("Cannot compress on remote storage; copy the files to this computer first")
+		return
+	}
+	for _, path := range paths {
+		if fs.InArchive(path) {
+			o.ui.ShowError("Cannot compress files inside an archive; copy them out first")
+			return
+		}
+	}
+	paths = append([]string(nil), paths...)
+	dst := filepath.Join(dir, name)
+	o.jobs.Submit(JobCompress, journalLabel("Compressing", paths)+" to "+name, func(j *Job) error {
+		return o.compressItems(j, paths, dst)
+	})
+}
+
+// compressItems writes paths to the archive dst as part of job j. The archive
+// is written under a hidden name next to dst first, so a name conflict can
+// be settled once its final size is known.
+func (o *Orchestrator) compressItems(j *Job, paths []string, dst string) error {
+	j.measure(paths, false)
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".razor-*-"+filepath.Base(dst))
+	if err != nil {
+		return err
+	}
+	tmp.Close()
+	if err := fs.CreateArchive(tmp.Name(), paths, j.progress, j.fileDone); err != nil {
+		return err
+	}
+
+	if dstInfo, err := os.Lstat(dst); err == nil {
+		info, err := os.Stat(tmp.Name())
+		if err != nil {
+			return err
+		}
+		session := &conflictSession{resolution: ui.ConflictAsk}
+		switch o.resolveConflict(j.ctx, session, tmp.Name(), dst, info, dstInfo, 1) {
+		case ui.ConflictReplaceAll:
+			// Compressing isn't journaled, but the old archive can still be
+			// restored from the trash
+			if _, err := replaceItem(dst); err != nil {
+				os.Remove(tmp.Name())
+				return err
+			}
+		case ui.ConflictKeepBothAll:
+			dst = keepBothPath(dst)
+		default:
+			os.Remove(tmp.Name())
+			return nil
+		}
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	debug.Log(debug.APP, "Compress: %d items to %s", len(paths), dst)
+	o.refreshCurrentDir()
+	o.ui.ShowSuccess("Created " + filepath.Base(dst))
+	return nil
+}
+
+// doExtract queues a job extracting archive next to it or, if toFolder, into
+// a folder named after it
+func (o *Orchestrator) doExtract(archive string, toFolder bool) {
+	if fs.IsRemote(archive) {
+		o.ui.ShowError("Cannot extract on remote storage; copy the archive to this computer first")
+		return
+	}
+	dir := filepath.Dir(archive)
+	if toFolder {
+		dir = filepath.Join(dir, fs.TrimArchiveExt(filepath.Base(archive)))
+	}
+	o.jobs.Submit(JobExtract, "Extracting "+filepath.Base(archive)+" to "+filepath.Base(dir), func(j *Job) error {
+		return o.extractArchive(j, archive, dir)
+	})
+}
+
+// extractArchive extracts the contents of archive into dir as part of job j,
+// asking how to resolve name conflicts of its top-level members. Members
+// whose names would leave dir (zip-slip) are never written; they are listed
+// as failures.
+func (o *Orchestrator) extractArchive(j *Job, archive, dir string) error {
+	a, err := fs.OpenArchive(archive)
+	if err != nil {
+		return err
+	}
+	top, err := a.ReadDir(".")
+	if err != nil {
+		return err
+	}
+	bytes, files := vfsTreeSize(archive)
+	j.bytesTotal.Add(bytes)
+	j.filesTotal.Add(files)
+
+	report := newBatchReport(len(top) + len(a.Unsafe()))
+	for _, name := range a.Unsafe() {
+		report.fail(name, fs.ErrUnsafePath)
+	}
+
+	var steps []store.JournalStep
+	created := !pathExists(dir)
+	if created {
+		if err := os.MkdirAll(dir, DirPermission); err != nil {
+			return err
+		}
+		steps = append(steps, store.JournalStep{Op: store.JournalCreate, Dst: dir, IsDir: true})
+	}
+
+	// Settle every conflict up front, so the archive is read only once
+	session := &conflictSession{resolution: ui.ConflictAsk}
+	targets := make(map[string]string, len(top))
+	var extracted []iofs.DirEntry
+	for i, d := range top {
+		src := filepath.Join(archive, d.Name())
+		dst := filepath.Join(dir, d.Name())
+		if session.abort {
+			report.skip(src, "operation stopped")
+			continue
+		}
+		if dstInfo, err := os.Lstat(dst); err == nil {
+			srcInfo, _ := d.Info()
+			switch o.resolveConflict(j.ctx, session, src, dst, srcInfo, dstInfo, len(top)-i) {
+			case ui.ConflictReplaceAll:
+				replaced, err := replaceItem(dst)
+				if err != nil {
+					report.fail(src, err)
+					continue
+				}
+				steps = append(steps, replaced...)
+			case ui.ConflictKeepBothAll:
+				dst = keepBothPath(dst)
+			case ui.ConflictSkipAll:
+				report.skip(src, "already exists")
+				continue
+			case ui.ConflictAsk:
+				report.skip(src, "operation stopped")
+				continue
+			}
+		}
+		targets[d.Name()] = dst
+		extracted = append(extracted, d)
+	}
+
+	j.setCurrent(filepath.Base(archive))
+	if len(targets) > 0 {
+		err = a.ExtractMembers(targets, j.progress, j.fileDone)
+	}
+	if errors.Is(err, context.Canceled) {
+		// Remove the partial extraction
+		for _, dst := range targets {
+			deleteItem(dst)
+		}
+		if created {
+			os.Remove(dir)
+		} else {
+			// Keep replaced items restorable
+			o.journal.record("Extract "+filepath.Base(archive), steps)
+		}
+		o.refreshCurrentDir()
+		return err
+	}
+	if err != nil {
+		report.fail(archive, err)
+	} else {
+		for _, d := range extracted {
+			report.success()
+			steps = append(steps, store.JournalStep{Op: store.JournalCopy, Src: filepath.Join(archive, d.Name()), Dst: targets[d.Name()], IsDir: d.IsDir()})
+		}
+	}
+	o.journal.record("Extract "+filepath.Base(archive), steps)
+	o.refreshCurrentDir()
+
+	if err := j.checkpoint(); err != nil {
+		return err
+	}
+	o.showBatchReport("Extract Finished with Errors", "", report, nil)
+	return report.err()
+}
@@ -0,0 +1,177 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/justyntemme/razor/internal/debug"
+	"github.com/justyntemme/razor/internal/fs"
+	"github.com/justyntemme/razor/internal/ui"
+)
+
+// compareState is the folder comparison of the compare view. The result is
+// written on the processEvents goroutine and read by the sync actions.
+type compareState struct {
+	mu          sync.Mutex
+	active      bool
+	left, right string
+	byContent   bool
+	entries     []fs.Entry    // Result of the latest comparison
+	plan        []fs.SyncStep // Dry run waiting to be confirmed
+}
+
+// syncTitles names the dry-run dialog of each sync direction
+var syncTitles = map[fs.SyncMode]string{
+	fs.SyncLeftToRight: "Sync Left → Right",
+	fs.SyncRightToLeft: "Sync Right → Left",
+	fs.SyncBothWays:    "Sync Both Ways",
+}
+
+// compareDirs compares left with right on the fs goroutine; the result
+// replaces the listing in the compare view
+func (o *Orchestrator) compareDirs(left, right string, byContent bool) {
+	if left == "" || right == "" || left == right {
+		return
+	}
+	if fs.IsRemote(left) || fs.IsRemote(right) {
+		o.ui.ShowError("Cannot compare folders on remote storage")
+		return
+	}
+	debug.Log(debug.APP, "Comparing %s with %s (byContent=%v)", left, right, byContent)
+	o.resetUIState()
+	o.ui.SetCompareView(true)
+
+	c := &o.compare
+	c.mu.Lock()
+	c.active, c.left, c.right, c.byContent = true, left, right, byContent
+	c.entries, c.plan = nil, nil
+	c.mu.Unlock()
+
+	o.stateMu.Lock()
+	o.state.IsSearchResult = true
+	o.state.SearchQuery = ""
+	o.state.Compare = ui.CompareView{Left: left, Right: right, ByContent: byContent}
+	o.stateMu.Unlock()
+
+	o.requestCompare(left, right, byContent)
+}
+
+func (o *Orchestrator) requestCompare(left, right string, byContent bool) {
+	o.setProgress(true, "Comparing folders...", 0, 0)
+	gen := o.searchGen.Add(1)
+	o.fs.RequestChan <- fs.Request{Op: fs.CompareDirs, Path: left, Other: right, ByContent: byContent, Gen: gen}
+	o.window.Invalidate()
+}
+
+// recompare runs the comparison again if the compare view still shows left and right
+func (o *Orchestrator) recompare(left, right string) {
+	c := &o.compare
+	c.mu.Lock()
+	current := c.active && c.left == left && c.right == right
+	byContent := c.byContent
+	c.mu.Unlock()
+	if current {
+		o.requestCompare(left, right, byContent)
+	}
+}
+
+// clearCompare forgets the comparison when the compare view is left
+func (o *Orchestrator) clearCompare() {
+	c := &o.compare
+	c.mu.Lock()
+	c.active, c.entries, c.plan = false, nil, nil
+	c.mu.Unlock()
+}
+
+// setCompareResult keeps the entries of a finished comparison for the sync actions
+func (o *Orchestrator) setCompareResult(entries []fs.Entry) {
+	c := &o.compare
+	c.mu.Lock()
+	if !c.active {
+		c.mu.Unlock()
+		return
+	}
+	c.entries = entries
+	c.mu.Unlock()
+
+	for _, e := range entries {
+		if e.Compare != fs.CompareIdentical {
+			return
+		}
+	}
+	o.ui.ShowSuccess("The folders are identical")
+}
+
+// planSync shows the dry run of syncing the compared paths in paths (all of
+// them if empty) in direction mode. Nothing is copied until it is confirmed.
+func (o *Orchestrator) planSync(paths []string, mode fs.SyncMode) {
+	c := &o.compare
+	c.mu.Lock()
+	entries := c.entries
+	if len(paths) > 0 {
+		selected := make(map[string]bool, len(paths))
+		for _, path := range paths {
+			selected[path] = true
+		}
+		entries = nil
+		for _, e := range c.entries {
+			if selected[e.Path] {
+				entries = append(entries, e)
+			}
+		}
+	}
+	steps, skipped := fs.PlanSync(c.left, c.right, entries, mode)
+	c.plan = steps
+	c.mu.Unlock()
+	debug.Log(debug.APP, "Sync plan: mode=%d %d copies, %d left alone", mode, len(steps), len(skipped))
+
+	plan := ui.SyncPlan{Active: true, Title: syncTitles[mode]}
+	for _, step := range steps {
+		side := "left"
+		if step.ToRight {
+			side = "right"
+		}
+		plan.Copies = append(plan.Copies, ui.ReportItem{Path: step.Name, Reason: step.Status.String() + " · copy to " + side})
+	}
+	for _, e := range skipped {
+		plan.Skipped = append(plan.Skipped, ui.ReportItem{Path: e.Name, Reason: e.Compare.String()})
+	}
+	switch {
+	case len(steps) == 0:
+		plan.Message = "Nothing to copy; the folders are already in sync."
+	case mode == fs.SyncBothWays:
+		plan.Message = fmt.Sprintf("%d items will be copied, each toward the side with the older or missing copy. Nothing is deleted.", len(steps))
+	default:
+		plan.Message = fmt.Sprintf("%d items will be copied, replacing what is there. Nothing is deleted.", len(steps))
+	}
+
+	o.stateMu.Lock()
+	o.state.SyncPlan = plan
+	o.stateMu.Unlock()
+	o.window.Invalidate()
+}
+
+// runSync queues the confirmed dry run as a copy job. Replacing a file on
+// the other side asks first, like any copy conflict.
+func (o *Orchestrator) runSync() {
+	c := &o.compare
+	c.mu.Lock()
+	steps, left, right := c.plan, c.left, c.right
+	c.plan = nil
+	c.mu.Unlock()
+	if len(steps) == 0 {
+		return
+	}
+
+	items := make([]transfer, len(steps))
+	for i, step := range steps {
+		items[i] = transfer{src: step.Src, dstDir: filepath.Dir(step.Dst)}
+	}
+	o.submitTransfers(items, false, o.verifyAlgorithm(false), func(error) {
+		o.recompare(left, right)
+		// The other pane may show one of the folders
+		o.refreshOtherPane(left)
+		o.refreshOtherPane(right)
+	})
+}
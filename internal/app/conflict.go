@@ -1,18 +1,21 @@
 package app
 
 import (
+	"context"
 	"os"
 
 	"github.com/justyntemme/razor/internal/ui"
 )
 
+// conflictSession tracks conflict choices for one multi-file operation
+type conflictSession struct {
+	resolution ui.ConflictResolution // Remembered "Apply to All" choice (ConflictAsk = ask each time)
+	abort      bool                  // Set when the user clicks Stop or the operation is cancelled
+}
+
 // handleConflictResolution is called when user responds to conflict dialog
 func (o *Orchestrator) handleConflictResolution(resolution ui.ConflictResolution) {
-	// If "Apply to All" was checked, update the resolution mode
-	if o.state.Conflict.ApplyToAll {
-		o.conflictResolution = resolution
-	}
-	// Send response to waiting paste operation
+	// Send response to waiting operation
 	select {
 	case o.conflictResponse <- resolution:
 	default:
@@ -21,17 +24,27 @@ func (o *Orchestrator) handleConflictResolution(resolution ui.ConflictResolution
 
 // resolveConflict shows the conflict dialog and waits for user response
 // remainingConflicts is the number of remaining files that may have conflicts (including current)
-func (o *Orchestrator) resolveConflict(src, dst string, srcInfo, dstInfo os.FileInfo, remainingConflicts int) ui.ConflictResolution {
+// Dialogs from concurrent jobs are shown one at a time; cancelling ctx dismisses the dialog
+func (o *Orchestrator) resolveConflict(ctx context.Context, session *conflictSession, src, dst string, srcInfo, dstInfo os.FileInfo, remainingConflicts int) ui.ConflictResolution {
 	// If we have a remembered resolution from "Apply to All", use it
-	if o.conflictResolution != ui.ConflictAsk {
-		return o.conflictResolution
+	if session.resolution != ui.ConflictAsk {
+		return session.resolution
 	}
 
 	// If abort was requested, return immediately
-	if o.conflictAbort {
+	if session.abort {
 		return ui.ConflictAsk
 	}
 
+	o.conflictMu.Lock()
+	defer o.conflictMu.Unlock()
+
+	// Discard an answer meant for a dialog that was dismissed by cancellation
+	select {
+	case <-o.conflictResponse:
+	default:
+	}
+
 	// Set up the conflict state and show dialog
 	o.state.Conflict = ui.ConflictState{
 		Active:             true,
@@ -48,6 +61,21 @@ func (o *Orchestrator) resolveConflict(src, dst string, srcInfo, dstInfo os.File
 	o.window.Invalidate()
 
 	// Wait for user response
-	resolution := <-o.conflictResponse
+	var resolution ui.ConflictResolution
+	select {
+	case resolution = <-o.conflictResponse:
+	case <-ctx.Done():
+		o.state.Conflict.Active = false
+		o.window.Invalidate()
+		session.abort = true
+		return ui.ConflictAsk
+	}
+
+	if resolution == ui.ConflictAsk {
+		// Stop button
+		session.abort = true
+	} else if o.state.Conflict.ApplyToAll {
+		session.resolution = resolution
+	}
 	return resolution
 }
@@ -46,6 +46,7 @@ type SearchController struct {
 	SelectedEngine    search.SearchEngine // Currently selected engine
 	SelectedEngineCmd string              // Command for the selected engine
 	DefaultDepth      int                 // Default recursive search depth
+	RespectIgnore     bool                // Skip .gitignore/.ignore'd paths unless ignore:off
 }
 
 // NewSearchController creates a search controller with the given dependencies.
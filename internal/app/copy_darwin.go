@@ -0,0 +1,56 @@
+//go:build darwin
+
+package app
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/justyntemme/razor/internal/debug"
+)
+
+// fileAccessTime returns the last access time recorded in info
+func fileAccessTime(info os.FileInfo) time.Time {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return time.Unix(st.Atimespec.Unix())
+	}
+	return info.ModTime()
+}
+
+// copyOwner gives dst the owner and group described by info. Only root may change the
+// owner, so unprivileged copies still try to keep the group.
+func copyOwner(dst string, info os.FileInfo) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return
+	}
+	if err := os.Lchown(dst, int(st.Uid), int(st.Gid)); err == nil {
+		return
+	}
+	if err := os.Lchown(dst, -1, int(st.Gid)); err != nil {
+		debug.Log(debug.APP, "copyOwner: cannot set group on %s: %v", dst, err)
+	}
+}
+
+// copyXattrs is a no-op on macOS; the standard library has no xattr syscalls there
+func copyXattrs(src, dst string) {}
+
+// sameDevice reports whether src and the directory dstDir are on the same filesystem,
+// so a rename can move src without copying
+func sameDevice(src, dstDir string) bool {
+	srcInfo, err1 := os.Lstat(src)
+	dstInfo, err2 := os.Stat(dstDir)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	srcStat, ok1 := srcInfo.Sys().(*syscall.Stat_t)
+	dstStat, ok2 := dstInfo.Sys().(*syscall.Stat_t)
+	return ok1 && ok2 && srcStat.Dev == dstStat.Dev
+}
+
+// isCrossDevice reports whether a rename failed because it crossed filesystems
+func isCrossDevice(err error) bool {
+	return errors.Is(err, syscall.EXDEV)
+}
@@ -0,0 +1,115 @@
+//go:build linux
+
+package app
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/justyntemme/razor/internal/debug"
+)
+
+// fileAccessTime returns the last access time recorded in info
+func fileAccessTime(info os.FileInfo) time.Time {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return time.Unix(st.Atim.Unix())
+	}
+	return info.ModTime()
+}
+
+// copyOwner gives dst the owner and group described by info. Only root may change the
+// owner, so unprivileged copies still try to keep the group.
+func copyOwner(dst string, info os.FileInfo) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return
+	}
+	if err := os.Lchown(dst, int(st.Uid), int(st.Gid)); err == nil {
+		return
+	}
+	if err := os.Lchown(dst, -1, int(st.Gid)); err != nil {
+		debug.Log(debug.APP, "copyOwner: cannot set group on %s: %v", dst, err)
+	}
+}
+
+// copyXattrs copies the extended attributes of src to dst. POSIX ACLs are stored as
+// system.posix_acl_* attributes, so they are carried over as well. Attributes the
+// destination refuses (unsupported filesystem, security.* without privilege) are skipped.
+func copyXattrs(src, dst string) {
+	names, err := listXattrs(src)
+	if err != nil {
+		if !errors.Is(err, syscall.ENOTSUP) {
+			debug.Log(debug.APP, "copyXattrs: cannot list attributes of %s: %v", src, err)
+		}
+		return
+	}
+
+	for _, name := range names {
+		value, err := getXattr(src, name)
+		if err != nil {
+			continue
+		}
+		if err := syscall.Setxattr(dst, name, value, 0); err != nil {
+			debug.Log(debug.APP, "copyXattrs: cannot set %s on %s: %v", name, dst, err)
+		}
+	}
+}
+
+// listXattrs returns the names of the extended attributes of path
+func listXattrs(path string) ([]string, error) {
+	for {
+		size, err := syscall.Listxattr(path, nil)
+		if err != nil || size == 0 {
+			return nil, err
+		}
+		buf := make([]byte, size)
+		n, err := syscall.Listxattr(path, buf)
+		if errors.Is(err, syscall.ERANGE) {
+			continue // Attributes were added since the size query
+		}
+		if err != nil {
+			return nil, err
+		}
+		return strings.Split(strings.TrimRight(string(buf[:n]), "\x00"), "\x00"), nil
+	}
+}
+
+// getXattr returns the value of the extended attribute name of path
+func getXattr(path, name string) ([]byte, error) {
+	for {
+		size, err := syscall.Getxattr(path, name, nil)
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size)
+		n, err := syscall.Getxattr(path, name, buf)
+		if errors.Is(err, syscall.ERANGE) {
+			continue // Value grew since the size query
+		}
+		if err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	}
+}
+
+// sameDevice reports whether src and the directory dstDir are on the same filesystem,
+// so a rename can move src without copying
+func sameDevice(src, dstDir string) bool {
+	srcInfo, err1 := os.Lstat(src)
+	dstInfo, err2 := os.Stat(dstDir)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	srcStat, ok1 := srcInfo.Sys().(*syscall.Stat_t)
+	dstStat, ok2 := dstInfo.Sys().(*syscall.Stat_t)
+	return ok1 && ok2 && srcStat.Dev == dstStat.Dev
+}
+
+// isCrossDevice reports whether a rename failed because it crossed filesystems
+func isCrossDevice(err error) bool {
+	return errors.Is(err, syscall.EXDEV)
+}
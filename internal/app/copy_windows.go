@@ -0,0 +1,45 @@
+//go:build windows
+
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// fileAccessTime returns the last access time recorded in info
+func fileAccessTime(info os.FileInfo) time.Time {
+	if data, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
+		return time.Unix(0, data.LastAccessTime.Nanoseconds())
+	}
+	return info.ModTime()
+}
+
+// copyOwner is a no-op on Windows; new files inherit the destination folder's ACL
+func copyOwner(dst string, info os.FileInfo) {}
+
+// copyXattrs is a no-op on Windows, which has no POSIX extended attributes
+func copyXattrs(src, dst string) {}
+
+// errorNotSameDevice is ERROR_NOT_SAME_DEVICE, returned by MoveFileEx across volumes
+const errorNotSameDevice syscall.Errno = 17
+
+// sameDevice reports whether src and the directory dstDir are on the same volume,
+// so a rename can move src without copying
+func sameDevice(src, dstDir string) bool {
+	srcAbs, err1 := filepath.Abs(src)
+	dstAbs, err2 := filepath.Abs(dstDir)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	return strings.EqualFold(filepath.VolumeName(srcAbs), filepath.VolumeName(dstAbs))
+}
+
+// isCrossDevice reports whether a rename failed because it crossed volumes
+func isCrossDevice(err error) bool {
+	return errors.Is(err, errorNotSameDevice)
+}
@@ -1,20 +1,23 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"io"
 	iofs "io/fs"
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
-	"sync/atomic"
 
 	"github.com/charlievieth/fastwalk"
 	"github.com/justyntemme/razor/internal/debug"
+	"github.com/justyntemme/razor/internal/fs"
+	"github.com/justyntemme/razor/internal/store"
 	"github.com/justyntemme/razor/internal/trash"
 	"github.com/justyntemme/razor/internal/ui"
 )
@@ -29,15 +32,36 @@ const (
 	FilePermission = 0o644 // Standard file permissions
 )
 
-// pathExists checks if a path exists on the filesystem.
+// pathExists checks if a path exists on the filesystem (a dangling symlink counts).
 func pathExists(path string) bool {
-	_, err := os.Stat(path)
+	_, err := lstat(path)
 	return err == nil
 }
 
+// lstat is os.Lstat, except that remote paths, which have no symlinks of
+// their own, are described by fs.Stat
+func lstat(path string) (os.FileInfo, error) {
+	if fs.IsRemote(path) {
+		return fs.Stat(path)
+	}
+	return os.Lstat(path)
+}
+
+// mkdir is os.Mkdir for paths on any file system
+func mkdir(path string) error {
+	if fs.IsRemote(path) {
+		return fs.MkdirAll(path)
+	}
+	return os.Mkdir(path, DirPermission)
+}
+
 // deleteItem removes a file or directory (recursively for directories).
+// Symlinks are removed themselves, never their targets.
 func deleteItem(path string) error {
-	info, err := os.Stat(path)
+	if fs.IsRemote(path) {
+		return fs.RemoveAll(path)
+	}
+	info, err := os.Lstat(path)
 	if err != nil {
 		return err
 	}
@@ -47,14 +71,36 @@ func deleteItem(path string) error {
 	return os.Remove(path)
 }
 
-// newProgressWriter creates a progress-tracking writer that updates state atomically.
-func (o *Orchestrator) newProgressWriter(w io.Writer) io.Writer {
-	return &progressWriter{
-		w: w,
-		onWrite: func(n int64) {
-			atomic.AddInt64(&o.state.Progress.Current, n)
-			o.window.Invalidate()
-		},
+// replaceItem makes way for an item replacing dst. dst goes to the trash where it can be
+// restored, and the returned step lets undo bring it back; otherwise it is deleted.
+func replaceItem(dst string) ([]store.JournalStep, error) {
+	if !trash.IsAvailable() || fs.IsRemote(dst) {
+		return nil, deleteItem(dst)
+	}
+	if err := trash.MoveToTrash(dst); err != nil {
+		return nil, err
+	}
+	if !trash.CanRestore() {
+		return nil, nil
+	}
+	return []store.JournalStep{{Op: store.JournalTrash, Src: dst}}, nil
+}
+
+// keepBothPath returns a non-existing sibling of dst for "Keep Both" conflict resolution,
+// e.g. "report_copy1.txt" for "report.txt".
+func keepBothPath(dst string) string {
+	dstDir := filepath.Dir(dst)
+	dstName := filepath.Base(dst)
+	ext := filepath.Ext(dstName)
+	base := strings.TrimSuffix(dstName, ext)
+	if trimmed := fs.TrimArchiveExt(dstName); trimmed != dstName {
+		base, ext = trimmed, dstName[len(trimmed):] // site_copy1.tar.gz, not site.tar_copy1.gz
+	}
+	for j := 1; ; j++ {
+		candidate := filepath.Join(dstDir, base+"_copy"+strconv.Itoa(j)+ext)
+		if !pathExists(candidate) {
+			return candidate
+		}
 	}
 }
 
@@ -112,19 +158,26 @@ func (o *Orchestrator) doCreateFile(name string) {
 		return
 	}
 
+	if readOnlyDir(o.state.CurrentPath) {
+		o.ui.ShowError("Error creating file: " + fs.ErrReadOnly.Error())
+		return
+	}
 	path := filepath.Join(o.state.CurrentPath, name)
 	if pathExists(path) {
 		o.ui.ShowError("File already exists: " + name)
 		return
 	}
 
-	file, err := os.Create(path)
+	file, err := fs.Create(path)
+	if err == nil {
+		err = file.Close()
+	}
 	if err != nil {
 		o.ui.ShowError("Error creating file: " + err.Error())
 		return
 	}
-	file.Close()
 
+	o.journal.record("New File "+name, []store.JournalStep{{Op: store.JournalCreate, Dst: path}})
 	o.refreshCurrentDir()
 }
 
@@ -134,17 +187,22 @@ func (o *Orchestrator) doCreateFolder(name string) {
 		return
 	}
 
+	if readOnlyDir(o.state.CurrentPath) {
+		o.ui.ShowError("Error creating folder: " + fs.ErrReadOnly.Error())
+		return
+	}
 	path := filepath.Join(o.state.CurrentPath, name)
 	if pathExists(path) {
 		o.ui.ShowError("Folder already exists: " + name)
 		return
 	}
 
-	if err := os.Mkdir(path, DirPermission); err != nil {
+	if err := mkdir(path); err != nil {
 		o.ui.ShowError("Error creating folder: " + err.Error())
 		return
 	}
 
+	o.journal.record("New Folder "+name, []store.JournalStep{{Op: store.JournalCreate, Dst: path, IsDir: true}})
 	o.refreshCurrentDir()
 }
 
@@ -153,94 +211,166 @@ func (o *Orchestrator) doRename(oldPath, newPath string) {
 	if oldPath == "" || newPath == "" || oldPath == newPath {
 		return
 	}
+	if fs.InArchive(oldPath) {
+		o.ui.ShowError("Error renaming: " + fs.ErrReadOnly.Error())
+		return
+	}
 
 	if pathExists(newPath) {
 		o.ui.ShowError("Cannot rename: a file with that name already exists")
 		return
 	}
 
-	if err := os.Rename(oldPath, newPath); err != nil {
+	if err := fs.Rename(oldPath, newPath); err != nil {
 		o.ui.ShowError("Error renaming: " + err.Error())
 		return
 	}
 
 	log.Printf("Renamed %s to %s", oldPath, newPath)
+	info, _ := fs.Stat(newPath)
+	o.journal.record(journalLabel("Rename", []string{oldPath}), []store.JournalStep{
+		{Op: store.JournalMove, Src: oldPath, Dst: newPath, IsDir: info != nil && info.IsDir()},
+	})
 	o.refreshCurrentDir()
 }
 
-// doDelete moves a file or folder to trash (or permanently deletes if trash unavailable)
-func (o *Orchestrator) doDelete(path string) {
-	if !pathExists(path) {
-		o.ui.ShowError("Delete error: file does not exist")
-		return
-	}
-
-	var err error
-	if trash.IsAvailable() {
-		o.setProgress(true, "Moving to "+trash.DisplayName()+": "+filepath.Base(path), 0, 0)
-		err = trash.MoveToTrash(path)
-	} else {
-		o.setProgress(true, "Deleting "+filepath.Base(path), 0, 0)
-		err = deleteItem(path)
+// doBatchRename renames several items as one undoable operation. The plan is
+// rebuilt from disk rather than trusted from the preview, and a failed rename
+// rolls back the ones before it so the batch is all or nothing.
+func (o *Orchestrator) doBatchRename(paths []string, rules fs.RenameRules) {
+	items := make([]fs.RenameItem, 0, len(paths))
+	for _, path := range paths {
+		if fs.InArchive(path) {
+			o.ui.ShowError("Cannot rename: " + fs.ErrReadOnly.Error())
+			return
+		}
+		info, err := lstat(path)
+		if err != nil {
+			o.ui.ShowError("Cannot rename: " + err.Error())
+			return
+		}
+		items = append(items, fs.RenameItem{Path: path, IsDir: info.IsDir(), ModTime: info.ModTime()})
 	}
-	o.setProgress(false, "", 0, 0)
 
+	plan, err := fs.PlanRename(items, rules, nil)
 	if err != nil {
-		o.ui.ShowError("Delete error: " + err.Error())
+		o.ui.ShowError("Cannot rename: " + err.Error())
 		return
 	}
+	for _, e := range plan.Entries {
+		if e.Problem != "" {
+			o.ui.ShowError("Cannot rename " + filepath.Base(e.OldPath) + ": " + e.Problem)
+			return
+		}
+	}
 
-	// Remove from StateOwner (preserves expansion state)
-	o.stateOwner.RemoveEntry(path)
+	var done []fs.RenameStep
+	for _, step := range plan.Steps() {
+		// os.Rename replaces files on unix, so check again right before each step.
+		// Case-only renames find the item itself on case-insensitive filesystems.
+		if pathExists(step.To) && !strings.EqualFold(step.From, step.To) {
+			err = fmt.Errorf("%s already exists", filepath.Base(step.To))
+		} else {
+			err = fs.Rename(step.From, step.To)
+		}
+		if err != nil {
+			for i := len(done) - 1; i >= 0; i-- {
+				if rerr := fs.Rename(done[i].To, done[i].From); rerr != nil {
+					log.Printf("Batch rename rollback failed for %s: %v", done[i].To, rerr)
+				}
+			}
+			o.ui.ShowError("Error renaming " + filepath.Base(step.From) + ": " + err.Error())
+			o.refreshCurrentDir()
+			return
+		}
+		done = append(done, step)
+	}
 
-	// Sync to state for UI
-	snapshot := o.stateOwner.GetSnapshot()
-	o.stateMu.Lock()
-	o.state.Entries = snapshot.Entries
-	o.state.SelectedIndex = -1
-	o.state.SelectedIndices = nil
-	o.stateMu.Unlock()
-	o.ui.ResetMultiSelect()
+	steps := make([]store.JournalStep, len(done))
+	for i, step := range done {
+		steps[i] = store.JournalStep{Op: store.JournalMove, Src: step.From, Dst: step.To, IsDir: step.IsDir}
+	}
+	log.Printf("Batch renamed %d items", plan.Changes)
+	o.journal.record(journalLabel("Rename", paths), steps)
+	o.ui.ShowSuccess(fmt.Sprintf("Renamed %d items", plan.Changes))
+	o.refreshCurrentDir()
+}
 
-	o.window.Invalidate()
+// doDelete moves a file or folder to trash (or permanently deletes if trash unavailable)
+func (o *Orchestrator) doDelete(path string) {
+	o.doDeleteMultiple([]string{path})
 }
 
-// doDeleteMultiple moves multiple files or folders to trash
+// doDeleteMultiple queues a job that moves files or folders to trash
 func (o *Orchestrator) doDeleteMultiple(paths []string) {
+	paths = append([]string(nil), paths...)
+	label := journalLabel("Deleting", paths)
+	if trash.IsAvailable() && !slices.ContainsFunc(paths, fs.IsRemote) {
+		label = journalLabel("Moving", paths) + " to " + trash.DisplayName()
+	}
+	o.jobs.Submit(JobDelete, label, func(j *Job) error {
+		return o.deleteItems(j, paths, false)
+	})
+}
+
+// doPermanentDelete permanently deletes a file or folder (bypassing trash)
+func (o *Orchestrator) doPermanentDelete(path string) {
+	o.doPermanentDeleteMultiple([]string{path})
+}
+
+// doPermanentDeleteMultiple queues a job that permanently deletes multiple files (bypassing trash)
+func (o *Orchestrator) doPermanentDeleteMultiple(paths []string) {
+	paths = append([]string(nil), paths...)
+	o.jobs.Submit(JobDelete, journalLabel("Permanently deleting", paths), func(j *Job) error {
+		return o.deleteItems(j, paths, true)
+	})
+}
+
+// deleteItems trashes (or, when permanent or trash is unavailable, deletes) paths as part of job j
+func (o *Orchestrator) deleteItems(j *Job, paths []string, permanent bool) error {
 	total := len(paths)
 	deletedPaths := make([]string, 0, total)
-	useTrash := trash.IsAvailable()
+	useTrash := !permanent && trash.IsAvailable()
+	j.filesTotal.Add(int64(total))
+	report := newBatchReport(total)
 
-	var errorCount int
-	for i, path := range paths {
-		if !pathExists(path) {
-			errorCount++
-			continue
+	for _, path := range paths {
+		if j.checkpoint() != nil {
+			break
 		}
+		j.setCurrent(filepath.Base(path))
 
-		var label string
 		var err error
-		if useTrash {
-			label = fmt.Sprintf("Moving to %s (%d/%d) %s", trash.DisplayName(), i+1, total, filepath.Base(path))
-			o.setProgress(true, label, 0, 0)
+		if fs.InArchive(path) {
+			err = fs.ErrReadOnly
+		} else if !pathExists(path) {
+			err = fmt.Errorf("%s does not exist", filepath.Base(path))
+		} else if fs.IsRemote(path) && !permanent {
+			err = errors.New("remote files cannot be moved to the " + trash.DisplayName() + "; delete them permanently instead")
+		} else if useTrash {
 			err = trash.MoveToTrash(path)
 		} else {
-			label = fmt.Sprintf("Deleting (%d/%d) %s", i+1, total, filepath.Base(path))
-			o.setProgress(true, label, 0, 0)
 			err = deleteItem(path)
 		}
 
 		if err != nil {
 			log.Printf("Delete error for %s: %v", path, err)
-			errorCount++
+			report.fail(path, err)
 		} else {
+			report.success()
 			deletedPaths = append(deletedPaths, path)
 		}
+		j.fileDone()
 	}
-	if errorCount > 0 {
-		o.ui.ShowError(fmt.Sprintf("Failed to delete %d of %d items", errorCount, total))
+
+	// Trashing is only undoable where the platform can restore
+	if useTrash && trash.CanRestore() && len(deletedPaths) > 0 {
+		steps := make([]store.JournalStep, len(deletedPaths))
+		for i, path := range deletedPaths {
+			steps[i] = store.JournalStep{Op: store.JournalTrash, Src: path}
+		}
+		o.journal.record(journalLabel(trash.VerbPhrase(), deletedPaths), steps)
 	}
-	o.setProgress(false, "", 0, 0)
 
 	// Remove deleted entries from StateOwner (preserves expansion state)
 	o.stateOwner.RemoveEntries(deletedPaths)
@@ -255,116 +385,159 @@ func (o *Orchestrator) doDeleteMultiple(paths []string) {
 	o.ui.ResetMultiSelect()
 
 	o.window.Invalidate()
+
+	if err := j.checkpoint(); err != nil {
+		return err
+	}
+	o.showBatchReport("Delete Finished with Errors", "", report, func(paths []string) {
+		if permanent {
+			o.doPermanentDeleteMultiple(paths)
+		} else {
+			o.doDeleteMultiple(paths)
+		}
+	})
+	return report.err()
 }
 
-// doPermanentDelete permanently deletes a file or folder (bypassing trash)
-func (o *Orchestrator) doPermanentDelete(path string) {
-	if !pathExists(path) {
-		o.ui.ShowError("Delete error: file does not exist")
+// doPaste queues a job that pastes the clipboard contents to the current directory.
+// verify checks every copied file's checksum even when behavior.verifyCopies is off.
+func (o *Orchestrator) doPaste(verify bool) {
+	clip := o.state.Clipboard
+	if clip == nil || len(clip.Paths) == 0 {
 		return
 	}
 
-	o.setProgress(true, "Permanently deleting "+filepath.Base(path), 0, 0)
-	err := deleteItem(path)
-	o.setProgress(false, "", 0, 0)
+	isCut := clip.Op == ui.ClipCut
+	o.submitTransfer(clip.Paths, o.state.CurrentPath, isCut, o.verifyAlgorithm(verify), func(err error) {
+		// Clear clipboard after cut operation completes
+		if isCut && !errors.Is(err, context.Canceled) {
+			o.state.Clipboard = nil
+		}
+	})
+}
 
-	if err != nil {
-		o.ui.ShowError("Delete error: " + err.Error())
+// doMove queues a job that moves files/folders to a destination directory (drag-and-drop)
+func (o *Orchestrator) doMove(sources []string, dstDir string) {
+	if len(sources) == 0 {
 		return
 	}
+	o.submitTransfer(sources, dstDir, true, o.verifyAlgorithm(false), nil)
+}
 
-	// Remove from StateOwner (preserves expansion state)
-	o.stateOwner.RemoveEntry(path)
-
-	// Sync to state for UI
-	snapshot := o.stateOwner.GetSnapshot()
-	o.stateMu.Lock()
-	o.state.Entries = snapshot.Entries
-	o.state.SelectedIndex = -1
-	o.state.SelectedIndices = nil
-	o.stateMu.Unlock()
-	o.ui.ResetMultiSelect()
+// doCopyExternal queues a job that copies files from external sources (e.g., Finder) to the destination directory
+func (o *Orchestrator) doCopyExternal(sources []string, dstDir string) {
+	debug.Log(debug.APP, "doCopyExternal: %d files to %s", len(sources), dstDir)
+	if len(sources) == 0 || dstDir == "" {
+		return
+	}
+	o.submitTransfer(sources, dstDir, false, o.verifyAlgorithm(false), nil)
+}
 
-	o.window.Invalidate()
+// transfer is one item of a copy or move job: src goes into dstDir under its own name
+type transfer struct {
+	src, dstDir string
 }
 
-// doPermanentDeleteMultiple permanently deletes multiple files (bypassing trash)
-func (o *Orchestrator) doPermanentDeleteMultiple(paths []string) {
-	total := len(paths)
-	deletedPaths := make([]string, 0, total)
+// submitTransfer queues a job copying (or moving) sources into dstDir, verifying copies
+// with algorithm unless it is empty. done, if set, runs on the job goroutine afterwards.
+func (o *Orchestrator) submitTransfer(sources []string, dstDir string, move bool, algorithm string, done func(err error)) {
+	items := make([]transfer, len(sources))
+	for i, src := range sources {
+		items[i] = transfer{src: src, dstDir: dstDir}
+	}
+	o.submitTransfers(items, move, algorithm, done)
+}
 
-	var errorCount int
-	for i, path := range paths {
-		if !pathExists(path) {
-			errorCount++
-			continue
+// submitTransfers is submitTransfer for items that may each go into a different directory
+func (o *Orchestrator) submitTransfers(items []transfer, move bool, algorithm string, done func(err error)) {
+	items = append([]transfer(nil), items...)
+	kind, verb := JobCopy, "Copying"
+	if move {
+		kind, verb = JobMove, "Moving"
+	}
+	o.jobs.Submit(kind, transferLabel(verb, items), func(j *Job) error {
+		j.verify = algorithm
+		err := o.transferItems(j, items, move)
+		if done != nil {
+			done(err)
 		}
+		return err
+	})
+}
 
-		label := fmt.Sprintf("Permanently deleting (%d/%d) %s", i+1, total, filepath.Base(path))
-		o.setProgress(true, label, 0, 0)
-		err := deleteItem(path)
-
-		if err != nil {
-			log.Printf("Permanent delete error for %s: %v", path, err)
-			errorCount++
-		} else {
-			deletedPaths = append(deletedPaths, path)
-		}
+// transferLabel names a copy or move job, with its destination if all items share one
+func transferLabel(verb string, items []transfer) string {
+	sources := make([]string, len(items))
+	sameDir := true
+	for i, item := range items {
+		sources[i] = item.src
+		sameDir = sameDir && item.dstDir == items[0].dstDir
 	}
-	o.setProgress(false, "", 0, 0)
-	if errorCount > 0 {
-		o.ui.ShowError(fmt.Sprintf("Failed to delete %d of %d items", errorCount, total))
+	if len(items) == 0 || !sameDir {
+		return journalLabel(verb, sources)
 	}
-
-	// Remove deleted entries from StateOwner (preserves expansion state)
-	o.stateOwner.RemoveEntries(deletedPaths)
-
-	// Sync to state for UI
-	snapshot := o.stateOwner.GetSnapshot()
-	o.stateMu.Lock()
-	o.state.Entries = snapshot.Entries
-	o.state.SelectedIndex = -1
-	o.state.SelectedIndices = nil
-	o.stateMu.Unlock()
-	o.ui.ResetMultiSelect()
-
-	o.window.Invalidate()
+	return journalLabel(verb, sources) + " to " + filepath.Base(items[0].dstDir)
 }
 
-// doPaste pastes the clipboard contents to the current directory
-func (o *Orchestrator) doPaste() {
-	clip := o.state.Clipboard
-	if clip == nil || len(clip.Paths) == 0 {
-		return
+// transferItems copies (or moves) each item into its directory as part of job j, asking
+// how to resolve name conflicts. Failures are collected into a summary report rather than
+// shown one by one. It returns the context error if the job was cancelled.
+func (o *Orchestrator) transferItems(j *Job, items []transfer, move bool) error {
+	session := &conflictSession{resolution: ui.ConflictAsk}
+	totalFiles := len(items)
+	sources := make([]string, len(items))
+	for i, item := range items {
+		sources[i] = item.src
 	}
+	sizes := j.measure(sources, o.followSymlinks())
+	report := newBatchReport(totalFiles)
 
-	// Reset conflict resolution state
-	o.conflictResolution = ui.ConflictAsk
-	o.conflictAbort = false
-
-	dstDir := o.state.CurrentPath
-	isCut := clip.Op == ui.ClipCut
-	totalFiles := len(clip.Paths)
-	var lastErr error
+	var steps []store.JournalStep
+	var done []string
+	var mismatches int
 
-	for i, src := range clip.Paths {
-		if o.conflictAbort {
+	for i, item := range items {
+		src := item.src
+		if j.checkpoint() != nil {
 			break
 		}
+		if session.abort {
+			report.skip(src, "operation stopped")
+			continue
+		}
 
 		dstName := filepath.Base(src)
-		dst := filepath.Join(dstDir, dstName)
+		dst := filepath.Join(item.dstDir, dstName)
+
+		// Moving onto itself is a no-op
+		if move && src == dst {
+			debug.Log(debug.APP, "Move: skipping %s, same location", src)
+			report.skip(src, "already in this folder")
+			continue
+		}
+
+		// Skip if trying to copy or move into itself (for directories)
+		if strings.HasPrefix(dst, src+string(filepath.Separator)) {
+			debug.Log(debug.APP, "Transfer: skipping %s, cannot copy into itself", src)
+			report.skip(src, "cannot copy a folder into itself")
+			continue
+		}
+
+		// Archives can be copied out of, but not into or moved out of
+		if readOnlyDir(item.dstDir) || move && fs.InArchive(src) {
+			report.fail(src, fs.ErrReadOnly)
+			continue
+		}
 
-		srcInfo, err := os.Stat(src)
+		srcInfo, err := o.statSource(src)
 		if err != nil {
-			o.ui.ShowError("Cannot access: " + filepath.Base(src))
-			lastErr = err
+			report.fail(src, err)
 			continue
 		}
 
 		// Check for conflict (including pasting to same directory)
 		sameFile := src == dst
-		dstInfo, err := os.Stat(dst)
+		dstInfo, err := lstat(dst)
 		if err == nil || sameFile {
 			// Use srcInfo as dstInfo when pasting to same location
 			if sameFile {
@@ -372,129 +545,301 @@ func (o *Orchestrator) doPaste() {
 			}
 			// Destination exists - need to resolve conflict
 			remainingFiles := totalFiles - i
-			resolution := o.resolveConflict(src, dst, srcInfo, dstInfo, remainingFiles)
+			resolution := o.resolveConflict(j.ctx, session, src, dst, srcInfo, dstInfo, remainingFiles)
 
 			switch resolution {
 			case ui.ConflictReplaceAll:
-				// Replace - delete destination first (skip if same file)
+				// Replace - trash destination first (skip if same file)
 				if sameFile {
-					o.ui.ShowError("Cannot replace a file with itself")
+					report.skip(src, "cannot replace a file with itself")
+					continue
+				}
+				replaced, err := replaceItem(dst)
+				if err != nil {
+					report.fail(src, err)
 					continue
 				}
-				deleteItem(dst)
+				steps = append(steps, replaced...)
 			case ui.ConflictKeepBothAll:
 				// Keep both - rename destination
-				ext := filepath.Ext(dstName)
-				base := strings.TrimSuffix(dstName, ext)
-				for j := 1; ; j++ {
-					dst = filepath.Join(dstDir, base+"_copy"+strconv.Itoa(j)+ext)
-					if !pathExists(dst) {
-						break
-					}
-				}
+				dst = keepBothPath(dst)
 			case ui.ConflictSkipAll:
 				// Skip this file
+				report.skip(src, "already exists")
 				continue
 			case ui.ConflictAsk:
-				// User clicked Stop or dialog was aborted
-				o.conflictAbort = true
+				// User clicked Stop, dialog was aborted or job was cancelled
+				report.skip(src, "operation stopped")
 				continue
 			}
 		}
 
-		// Check if abort was triggered (by Stop button)
-		if o.conflictAbort {
-			break
+		j.setCurrent(filepath.Base(src))
+		switch {
+		case move:
+			err = o.moveItem(j, src, dst, srcInfo, sizes[i])
+		case fs.IsRemote(src) || fs.IsRemote(dst):
+			err = copyRemote(j, src, dst)
+		case fs.InArchive(src):
+			err = extractMember(j, src, dst)
+		case srcInfo.IsDir():
+			err = o.copyDir(j, src, dst)
+		default:
+			err = o.copyFile(j, src, dst)
 		}
 
-		label := "Copying"
-		if isCut {
-			label = "Moving"
+		if errors.Is(err, context.Canceled) {
+			// Remove the partial copy; the source is only removed after a complete, verified copy
+			deleteItem(dst)
+			break
 		}
-
-		// Show progress with file count for multiple files
-		progressLabel := label + " " + filepath.Base(src)
-		if totalFiles > 1 {
-			progressLabel = fmt.Sprintf("%s (%d/%d) %s", label, i+1, totalFiles, filepath.Base(src))
+		if err != nil {
+			if errors.Is(err, errChecksumMismatch) {
+				mismatches++
+				if srcInfo.IsDir() {
+					err = fmt.Errorf("%w (%s)", err, strings.Join(j.mismatchesUnder(dst), ", "))
+				}
+			}
+			report.fail(src, err)
+			continue
 		}
+		report.success()
 
-		if srcInfo.IsDir() {
-			o.setProgress(true, progressLabel, 0, 0)
-			err = o.copyDir(src, dst, isCut)
-		} else {
-			o.setProgress(true, progressLabel, 0, srcInfo.Size())
-			err = o.copyFile(src, dst, isCut)
+		op := store.JournalCopy
+		if move {
+			op = store.JournalMove
 		}
+		steps = append(steps, store.JournalStep{Op: op, Src: src, Dst: dst, IsDir: srcInfo.IsDir()})
+		done = append(done, src)
+	}
 
-		if err != nil {
-			o.ui.ShowError("Error copying " + filepath.Base(src) + ": " + err.Error())
-			lastErr = err
-		}
+	if move {
+		o.journal.record(journalLabel("Move", done), steps)
+	} else {
+		o.journal.record(journalLabel("Paste", done), steps)
 	}
 
-	o.setProgress(false, "", 0, 0)
+	o.refreshCurrentDir()
 
-	// Note: We don't show a summary toast here since individual errors are already shown
-	_ = lastErr
+	if err := j.checkpoint(); err != nil {
+		return err
+	}
 
-	// Clear clipboard after cut operation completes
-	if isCut && !o.conflictAbort {
-		o.state.Clipboard = nil
+	title, note := "Copy Finished with Errors", ""
+	if move {
+		title = "Move Finished with Errors"
 	}
+	if mismatches > 0 {
+		note = fmt.Sprintf("%d failed %s verification.", mismatches, j.verify)
+		if move {
+			note += " Their originals were not deleted."
+		}
+	} else if j.verifying() && report.succeeded > 0 {
+		o.ui.ShowSuccess(fmt.Sprintf("Verified %d files (%s)", j.verified.Load(), j.verify))
+	}
+	o.showBatchReport(title, note, report, func(paths []string) {
+		retry := make(map[string]bool, len(paths))
+		for _, path := range paths {
+			retry[path] = true
+		}
+		var again []transfer
+		for _, item := range items {
+			if retry[item.src] {
+				again = append(again, item)
+			}
+		}
+		o.submitTransfers(again, move, j.verify, nil)
+	})
+	return report.err()
+}
 
-	o.refreshCurrentDir()
+// followSymlinks reports whether copies should follow symlinks rather than reproduce them
+func (o *Orchestrator) followSymlinks() bool {
+	return o.config.GetBehaviorConfig().FollowSymlinks
 }
 
-// copyFile copies a single file with progress tracking
-func (o *Orchestrator) copyFile(src, dst string, move bool) error {
-	srcFile, err := os.Open(src)
-	if err != nil {
-		return err
+// statSource describes a file about to be copied: the link itself unless symlinks are
+// followed, in which case dangling links still fall back to the link
+func (o *Orchestrator) statSource(path string) (os.FileInfo, error) {
+	if fs.InArchive(path) || fs.IsRemote(path) {
+		return fs.Stat(path)
 	}
-	defer srcFile.Close()
+	if o.followSymlinks() {
+		if info, err := os.Stat(path); err == nil {
+			return info, nil
+		}
+	}
+	return os.Lstat(path)
+}
 
-	info, err := srcFile.Stat()
+// moveItem moves src (described by info, with the given size) to dst as part of job j.
+// Items on the same filesystem are renamed in place. Across filesystems, or when the rename
+// reports EXDEV, they are copied, and the source is only removed once the copy is verified.
+func (o *Orchestrator) moveItem(j *Job, src, dst string, info os.FileInfo, size itemSize) error {
+	if fs.IsRemote(src) || fs.IsRemote(dst) {
+		return o.moveRemote(j, src, dst, size)
+	}
+	if sameDevice(src, filepath.Dir(dst)) {
+		err := os.Rename(src, dst)
+		if err == nil {
+			j.advance(size)
+			return nil
+		}
+		if !isCrossDevice(err) {
+			return err
+		}
+		debug.Log(debug.APP, "Move: rename %s crossed devices, copying instead", src)
+	}
+
+	var err error
+	if info.IsDir() {
+		err = o.copyDir(j, src, dst)
+	} else {
+		err = o.copyFile(j, src, dst)
+	}
 	if err != nil {
 		return err
 	}
 
-	dstFile, err := os.Create(dst)
-	if err != nil {
+	if err := verifyCopy(src, dst, o.followSymlinks()); err != nil {
+		return fmt.Errorf("copy not verified, %s was kept: %w", filepath.Base(src), err)
+	}
+	return deleteItem(src)
+}
+
+// moveRemote moves src to dst when either is on remote storage. Items on the
+// same server are renamed there; otherwise they are copied, then deleted.
+func (o *Orchestrator) moveRemote(j *Job, src, dst string, size itemSize) error {
+	err := fs.Rename(src, dst)
+	if err == nil {
+		j.advance(size)
+		return nil
+	}
+	if !errors.Is(err, errors.ErrUnsupported) {
 		return err
 	}
-	defer dstFile.Close()
+	if err := copyRemote(j, src, dst); err != nil {
+		return err
+	}
+	return deleteItem(src)
+}
+
+// copyRemote copies the file or folder src to dst when either is on remote
+// storage, counting progress on j
+func copyRemote(j *Job, src, dst string) error {
+	return fs.CopyTree(src, dst, j.progress, j.fileDone)
+}
+
+// verifyCopy checks that dst holds everything the walk of src would copy: the same
+// entries, with matching types, file sizes and link targets
+func verifyCopy(src, dst string, follow bool) error {
+	check := func(srcPath, dstPath string, srcInfo os.FileInfo) error {
+		dstInfo, err := os.Lstat(dstPath)
+		if err != nil {
+			return err
+		}
+		srcLink := srcInfo.Mode()&os.ModeSymlink != 0
+		switch {
+		case srcLink != (dstInfo.Mode()&os.ModeSymlink != 0), srcInfo.IsDir() != dstInfo.IsDir():
+			return fmt.Errorf("%s has the wrong type", dstPath)
+		case srcLink:
+			srcTarget, err1 := os.Readlink(srcPath)
+			dstTarget, err2 := os.Readlink(dstPath)
+			if err := errors.Join(err1, err2); err != nil {
+				return err
+			}
+			if srcTarget != dstTarget {
+				return fmt.Errorf("%s points to %s instead of %s", dstPath, dstTarget, srcTarget)
+			}
+		case !srcInfo.IsDir() && srcInfo.Size() != dstInfo.Size():
+			return fmt.Errorf("%s is %d bytes instead of %d", dstPath, dstInfo.Size(), srcInfo.Size())
+		}
+		return nil
+	}
 
-	if _, err := io.Copy(o.newProgressWriter(dstFile), srcFile); err != nil {
+	rootInfo, err := os.Lstat(src)
+	if err != nil {
 		return err
 	}
+	if follow && rootInfo.Mode()&os.ModeSymlink != 0 {
+		if info, err := os.Stat(src); err == nil {
+			rootInfo = info
+		}
+	}
+	if !rootInfo.IsDir() {
+		return check(src, dst, rootInfo)
+	}
 
-	if err := os.Chmod(dst, info.Mode()); err != nil {
+	// Walk the same way copyDir does; anything unreadable now was not copied either
+	srcLen := len(src)
+	conf := &fastwalk.Config{Follow: follow}
+	return fastwalk.Walk(conf, src, func(fullPath string, d iofs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		relPath := fullPath[srcLen:]
+		if len(relPath) > 0 && (relPath[0] == '/' || relPath[0] == '\\') {
+			relPath = relPath[1:]
+		}
+		if relPath == "" {
+			return nil
+		}
+
+		var info os.FileInfo
+		var err error
+		if follow {
+			info, err = fastwalk.StatDirEntry(fullPath, d)
+		}
+		if !follow || (err != nil && d.Type()&os.ModeSymlink != 0) {
+			info, err = d.Info()
+		}
+		if err != nil {
+			return err
+		}
+		return check(fullPath, filepath.Join(dst, relPath), info)
+	})
+}
+
+// copyFile copies a single file or symlink, counting progress on j
+func (o *Orchestrator) copyFile(j *Job, src, dst string) error {
+	info, err := o.statSource(src)
+	if err != nil {
 		return err
 	}
 
-	if move {
-		return os.Remove(src)
+	if info.Mode()&os.ModeSymlink != 0 {
+		if err := copySymlink(src, dst, info); err != nil {
+			return err
+		}
+		j.fileDone()
+		return nil
 	}
-	return nil
+	return o.copyFileWithProgress(j, src, dst)
 }
 
-// copyDir copies a directory recursively with progress tracking
-func (o *Orchestrator) copyDir(src, dst string, move bool) error {
-	// Single-pass copy using fastwalk: count total size while building file list
-	var totalSize atomic.Int64
+// copyDir copies a directory recursively, counting progress on j.
+// Symlinks inside are reproduced as links unless FollowSymlinks is set; fastwalk skips
+// followed links that would loop back into a directory already being copied.
+func (o *Orchestrator) copyDir(j *Job, src, dst string) error {
+	// Single-pass walk using fastwalk to build the file list
 	type copyItem struct {
 		srcPath string
 		dstPath string
-		isDir   bool
-		mode    iofs.FileMode
+		info    os.FileInfo
 	}
 	var items []copyItem
 	var itemsMu sync.Mutex
 
-	conf := &fastwalk.Config{Follow: true}
+	rootInfo, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	follow := o.followSymlinks()
+	conf := &fastwalk.Config{Follow: follow}
 	srcLen := len(src)
 
-	err := fastwalk.Walk(conf, src, func(fullPath string, d iofs.DirEntry, walkErr error) error {
+	err = fastwalk.Walk(conf, src, func(fullPath string, d iofs.DirEntry, walkErr error) error {
 		if walkErr != nil {
 			return nil // Skip errors, continue walking
 		}
@@ -509,21 +854,22 @@ func (o *Orchestrator) copyDir(src, dst string, move bool) error {
 		}
 
 		dstPath := filepath.Join(dst, relPath)
-		info, err := fastwalk.StatDirEntry(fullPath, d)
+		var info os.FileInfo
+		var err error
+		if follow {
+			info, err = fastwalk.StatDirEntry(fullPath, d)
+		}
+		if !follow || (err != nil && d.Type()&os.ModeSymlink != 0) {
+			// Describe the entry itself; dangling links are copied as links even when following
+			info, err = d.Info()
+		}
 		if err != nil {
 			return nil // Skip files we can't stat
 		}
 
-		if info.IsDir() {
-			itemsMu.Lock()
-			items = append(items, copyItem{srcPath: fullPath, dstPath: dstPath, isDir: true, mode: info.Mode()})
-			itemsMu.Unlock()
-		} else {
-			totalSize.Add(info.Size())
-			itemsMu.Lock()
-			items = append(items, copyItem{srcPath: fullPath, dstPath: dstPath, isDir: false, mode: info.Mode()})
-			itemsMu.Unlock()
-		}
+		itemsMu.Lock()
+		items = append(items, copyItem{srcPath: fullPath, dstPath: dstPath, info: info})
+		itemsMu.Unlock()
 		return nil
 	})
 
@@ -531,12 +877,6 @@ func (o *Orchestrator) copyDir(src, dst string, move bool) error {
 		return err
 	}
 
-	// Set up progress with counted total
-	o.progressMu.Lock()
-	o.state.Progress.Total = totalSize.Load()
-	o.state.Progress.Current = 0
-	o.progressMu.Unlock()
-
 	// Create destination root
 	if err := os.MkdirAll(dst, DirPermission); err != nil {
 		return err
@@ -546,33 +886,63 @@ func (o *Orchestrator) copyDir(src, dst string, move bool) error {
 	// then files
 	sort.Slice(items, func(i, j int) bool {
 		// Directories before files
-		if items[i].isDir != items[j].isDir {
-			return items[i].isDir
+		if items[i].info.IsDir() != items[j].info.IsDir() {
+			return items[i].info.IsDir()
 		}
 		// Shorter paths first (parents before children)
 		return len(items[i].dstPath) < len(items[j].dstPath)
 	})
 
+	var mismatched int
 	for _, item := range items {
-		if item.isDir {
-			if err := os.MkdirAll(item.dstPath, item.mode); err != nil {
+		if err := j.checkpoint(); err != nil {
+			return err
+		}
+		switch {
+		case item.info.IsDir():
+			// Writable until its contents are copied; the real mode is applied afterwards
+			if err := os.MkdirAll(item.dstPath, DirPermission); err != nil {
 				return err
 			}
-		} else {
-			if err := o.copyFileWithProgress(item.srcPath, item.dstPath); err != nil {
+		case item.info.Mode()&os.ModeSymlink != 0:
+			if err := copySymlink(item.srcPath, item.dstPath, item.info); err != nil {
+				return err
+			}
+			j.fileDone()
+		default:
+			err := o.copyFileWithProgress(j, item.srcPath, item.dstPath)
+			if errors.Is(err, errChecksumMismatch) {
+				// Keep going so the report lists every bad copy
+				mismatched++
+				continue
+			}
+			if err != nil {
 				return err
 			}
 		}
 	}
 
-	if move {
-		return os.RemoveAll(src)
+	// Apply directory metadata deepest first, so writing children doesn't bump parent mtimes
+	for i := len(items) - 1; i >= 0; i-- {
+		if items[i].info.IsDir() {
+			if err := copyMetadata(items[i].srcPath, items[i].dstPath, items[i].info); err != nil {
+				return err
+			}
+		}
+	}
+	if err := copyMetadata(src, dst, rootInfo); err != nil {
+		return err
+	}
+	if mismatched > 0 {
+		return fmt.Errorf("%d files in %s: %w", mismatched, filepath.Base(src), errChecksumMismatch)
 	}
 	return nil
 }
 
-// copyFileWithProgress copies a single file with atomic progress updates
-func (o *Orchestrator) copyFileWithProgress(src, dst string) error {
+// copyFileWithProgress copies a single file, reporting to j so the copy can be paused or cancelled.
+// Reflinks and copy_file_range are tried before a buffered copy (see fs.CopyContents).
+// A partially written destination is removed on failure.
+func (o *Orchestrator) copyFileWithProgress(j *Job, src, dst string) error {
 	srcFile, err := os.Open(src)
 	if err != nil {
 		return err
@@ -588,226 +958,143 @@ func (o *Orchestrator) copyFileWithProgress(src, dst string) error {
 	if err != nil {
 		return err
 	}
-	defer dstFile.Close()
 
-	if _, err := io.Copy(o.newProgressWriter(dstFile), srcFile); err != nil {
+	strategy, err := fs.CopyContents(dstFile, srcFile, fs.CopyAuto, j.progress)
+	if err != nil {
+		dstFile.Close()
+		os.Remove(dst)
 		return err
 	}
+	debug.Log(debug.APP, "copyFile: %s copied with %s", src, strategy)
+	if err := dstFile.Close(); err != nil {
+		return err
+	}
+	j.fileDone()
 
-	return os.Chmod(dst, info.Mode())
-}
-
-// progressWriter wraps an io.Writer and calls onWrite after each write
-type progressWriter struct {
-	w       io.Writer
-	onWrite func(int64)
-}
-
-func (pw *progressWriter) Write(p []byte) (int, error) {
-	n, err := pw.w.Write(p)
-	if n > 0 && pw.onWrite != nil {
-		pw.onWrite(int64(n))
+	// Verify before copying metadata, since reading dst afterwards could bump its access time
+	if j.verifying() {
+		if err := verifyChecksum(j, src, dst); err != nil {
+			return err
+		}
 	}
-	return n, err
+	return copyMetadata(src, dst, info)
 }
 
-// doMove moves files/folders to a destination directory (drag-and-drop)
-func (o *Orchestrator) doMove(sources []string, dstDir string) {
-	if len(sources) == 0 {
-		return
+// copySymlink recreates the symlink src at dst with the same (possibly relative) target
+func copySymlink(src, dst string, info os.FileInfo) error {
+	target, err := os.Readlink(src)
+	if err != nil {
+		return err
 	}
+	if err := os.Symlink(target, dst); err != nil {
+		return err
+	}
+	copyOwner(dst, info)
+	return nil
+}
 
-	// Reset conflict resolution state
-	o.conflictResolution = ui.ConflictAsk
-	o.conflictAbort = false
+// copyMetadata carries ownership, extended attributes, permissions and timestamps of src
+// (described by info) over to dst. Only the permissions are required to succeed: the
+// destination filesystem or an unprivileged user may not support the rest.
+func copyMetadata(src, dst string, info os.FileInfo) error {
+	// Ownership first, since chown clears setuid/setgid bits
+	copyOwner(dst, info)
+	copyXattrs(src, dst)
 
-	totalFiles := len(sources)
-	var lastErr error
+	if err := os.Chmod(dst, info.Mode()); err != nil {
+		return err
+	}
 
-	for i, src := range sources {
-		if o.conflictAbort {
-			break
-		}
+	// Timestamps last, after everything else that could touch the file
+	if err := os.Chtimes(dst, fileAccessTime(info), info.ModTime()); err != nil {
+		debug.Log(debug.APP, "copyMetadata: cannot set times on %s: %v", dst, err)
+	}
+	return nil
+}
 
-		dstName := filepath.Base(src)
-		dst := filepath.Join(dstDir, dstName)
+// resolveDuplicates keeps one file of each duplicate group with a selected
+// file in it and trashes the others, or replaces them with hardlinks to it
+func (o *Orchestrator) resolveDuplicates(paths []string, mode ui.DedupeMode) {
+	want := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		want[p] = true
+	}
 
-		// Skip if source and destination are the same
-		if src == dst {
-			debug.Log(debug.APP, "Move: skipping %s, same location", src)
+	o.stateMu.RLock()
+	groups := make(map[int][]ui.UIEntry)
+	selected := make(map[int]bool)
+	for _, e := range o.state.Entries {
+		if e.DupGroup == 0 {
 			continue
 		}
-
-		// Skip if trying to move into itself (for directories)
-		if strings.HasPrefix(dst, src+string(filepath.Separator)) {
-			debug.Log(debug.APP, "Move: skipping %s, cannot move into itself", src)
-			continue
+		groups[e.DupGroup] = append(groups[e.DupGroup], e)
+		if want[e.Path] {
+			selected[e.DupGroup] = true
 		}
-
-		srcInfo, err := os.Stat(src)
-		if err != nil {
-			o.ui.ShowError("Cannot access: " + filepath.Base(src))
-			lastErr = err
+	}
+	o.stateMu.RUnlock()
+
+	// keepers maps each file to remove to the file kept in its place
+	keepers := make(map[string]string)
+	var victims []string
+	for group := range selected {
+		files := groups[group]
+		if len(files) < 2 {
 			continue
 		}
-
-		// Check for conflict
-		dstInfo, err := os.Stat(dst)
-		if err == nil {
-			// Destination exists - need to resolve conflict
-			remainingFiles := totalFiles - i
-			resolution := o.resolveConflict(src, dst, srcInfo, dstInfo, remainingFiles)
-
-			switch resolution {
-			case ui.ConflictReplaceAll:
-				// Replace - delete destination first
-				deleteItem(dst)
-			case ui.ConflictKeepBothAll:
-				// Keep both - rename destination
-				ext := filepath.Ext(dstName)
-				base := strings.TrimSuffix(dstName, ext)
-				for j := 1; ; j++ {
-					dst = filepath.Join(dstDir, base+"_copy"+strconv.Itoa(j)+ext)
-					if !pathExists(dst) {
-						break
-					}
-				}
-			case ui.ConflictSkipAll:
-				// Skip this file
-				continue
-			case ui.ConflictAsk:
-				// User clicked Stop or dialog was aborted
-				o.conflictAbort = true
-				continue
+		keep := files[0]
+		for _, f := range files[1:] {
+			if mode == ui.DedupeKeepNewest && f.ModTime.After(keep.ModTime) ||
+				mode != ui.DedupeKeepNewest && f.ModTime.Before(keep.ModTime) {
+				keep = f
 			}
 		}
-
-		// Check if abort was triggered (by Stop button)
-		if o.conflictAbort {
-			break
-		}
-
-		// Show progress
-		progressLabel := fmt.Sprintf("Moving (%d/%d) %s", i+1, totalFiles, filepath.Base(src))
-		if totalFiles == 1 {
-			progressLabel = "Moving " + filepath.Base(src)
-		}
-
-		if srcInfo.IsDir() {
-			o.setProgress(true, progressLabel, 0, 0)
-			if err := o.copyDir(src, dst, true); err != nil {
-				o.ui.ShowError("Error moving " + filepath.Base(src) + ": " + err.Error())
-				lastErr = err
-			}
-		} else {
-			size := srcInfo.Size()
-			o.setProgress(true, progressLabel, 0, size)
-			if err := o.copyFile(src, dst, true); err != nil {
-				o.ui.ShowError("Error moving " + filepath.Base(src) + ": " + err.Error())
-				lastErr = err
+		for _, f := range files {
+			if f.Path != keep.Path {
+				keepers[f.Path] = keep.Path
+				victims = append(victims, f.Path)
 			}
 		}
 	}
-
-	o.setProgress(false, "", 0, 0)
-
-	// Note: We don't show a summary toast here since individual errors are already shown
-	_ = lastErr
-
-	// Refresh directory to show changes
-	o.navCtrl.RequestDir(o.state.CurrentPath)
-}
-
-// doCopyExternal copies files from external sources (e.g., Finder) to the destination directory
-func (o *Orchestrator) doCopyExternal(sources []string, dstDir string) {
-	if len(sources) == 0 || dstDir == "" {
+	if len(victims) == 0 {
 		return
 	}
+	sort.Strings(victims)
 
-	debug.Log(debug.APP, "doCopyExternal: %d files to %s", len(sources), dstDir)
-
-	// Reset conflict resolution state
-	o.conflictResolution = ui.ConflictAsk
-	o.conflictAbort = false
-
-	totalFiles := len(sources)
-	var lastErr error
-
-	for i, src := range sources {
-		if o.conflictAbort {
-			break
-		}
-
-		dstName := filepath.Base(src)
-		dst := filepath.Join(dstDir, dstName)
-
-		srcInfo, err := os.Stat(src)
-		if err != nil {
-			o.ui.ShowError("Cannot access: " + filepath.Base(src))
-			lastErr = err
-			continue
-		}
-
-		// Check for conflict
-		dstInfo, err := os.Stat(dst)
-		if err == nil {
-			// Destination exists - need to resolve conflict
-			remainingFiles := totalFiles - i
-			resolution := o.resolveConflict(src, dst, srcInfo, dstInfo, remainingFiles)
+	if mode != ui.DedupeHardlink {
+		o.doDeleteMultiple(victims)
+		return
+	}
+	o.doHardlinkDuplicates(victims, keepers)
+}
 
-			switch resolution {
-			case ui.ConflictReplaceAll:
-				// Replace - delete destination first
-				deleteItem(dst)
-			case ui.ConflictKeepBothAll:
-				// Keep both - rename destination
-				ext := filepath.Ext(dstName)
-				base := strings.TrimSuffix(dstName, ext)
-				for j := 1; ; j++ {
-					dst = filepath.Join(dstDir, base+"_copy"+strconv.Itoa(j)+ext)
-					if !pathExists(dst) {
-						break
-					}
-				}
-			case ui.ConflictSkipAll:
-				// Skip this file
-				continue
-			case ui.ConflictAsk:
-				// User clicked Stop or dialog was aborted
-				o.conflictAbort = true
-				continue
+// doHardlinkDuplicates queues a job that replaces each of dups with a hardlink
+// to its file in keepers. Replacements aren't recorded in the journal, since
+// the duplicate's own copy of the data is gone.
+func (o *Orchestrator) doHardlinkDuplicates(dups []string, keepers map[string]string) {
+	o.jobs.Submit(JobLink, journalLabel("Hardlinking", dups), func(j *Job) error {
+		j.filesTotal.Add(int64(len(dups)))
+		report := newBatchReport(len(dups))
+		for _, dup := range dups {
+			if j.checkpoint() != nil {
+				break
 			}
+			j.setCurrent(filepath.Base(dup))
+			if err := fs.ReplaceWithHardlink(keepers[dup], dup); err != nil {
+				log.Printf("Hardlink error for %s: %v", dup, err)
+				report.fail(dup, err)
+			} else {
+				report.success()
+			}
+			j.fileDone()
 		}
 
-		// Check if abort was triggered (by Stop button)
-		if o.conflictAbort {
-			break
-		}
-
-		// Show progress with file count for multiple files
-		progressLabel := "Copying " + filepath.Base(src)
-		if totalFiles > 1 {
-			progressLabel = fmt.Sprintf("Copying (%d/%d) %s", i+1, totalFiles, filepath.Base(src))
-		}
-
-		if srcInfo.IsDir() {
-			o.setProgress(true, progressLabel, 0, 0)
-			err = o.copyDir(src, dst, false)
-		} else {
-			o.setProgress(true, progressLabel, 0, srcInfo.Size())
-			err = o.copyFile(src, dst, false)
-		}
-
-		if err != nil {
-			o.ui.ShowError("Error copying " + filepath.Base(src) + ": " + err.Error())
-			lastErr = err
+		if err := j.checkpoint(); err != nil {
+			return err
 		}
-	}
-
-	o.setProgress(false, "", 0, 0)
-
-	// Note: We don't show a summary toast here since individual errors are already shown
-	_ = lastErr
-
-	o.refreshCurrentDir()
+		o.showBatchReport("Hardlinking Finished with Errors", "", report, func(paths []string) {
+			o.doHardlinkDuplicates(paths, keepers)
+		})
+		return report.err()
+	})
 }
@@ -0,0 +1,444 @@
+package app
+
+import (
+	"context"
+	"errors"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/charlievieth/fastwalk"
+	"github.com/justyntemme/razor/internal/debug"
+	"github.com/justyntemme/razor/internal/fs"
+	"github.com/justyntemme/razor/internal/ui"
+)
+
+// maxConcurrentJobs limits how many file operations run at once; the rest wait in the queue
+const maxConcurrentJobs = 2
+
+// JobKind identifies the file operation a job performs
+type JobKind int
+
+const (
+	JobCopy JobKind = iota
+	JobMove
+	JobDelete
+	JobLink
+	JobCompress
+	JobExtract
+)
+
+// Job is a file operation running in the background.
+// Its body calls checkpoint between units of work (and reports copied bytes through progress) so it can be
+// paused and cancelled; all methods are safe to call on a nil Job, which tracks nothing.
+type Job struct {
+	ID    int64
+	Kind  JobKind
+	Label string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu          sync.Mutex
+	status      ui.JobStatus
+	resume      chan struct{} // Closed when a paused job is resumed (nil while not paused)
+	current     string        // Name of the item being processed
+	err         error
+	started     time.Time
+	pausedAt    time.Time
+	pausedTotal time.Duration
+
+	bytesDone, bytesTotal atomic.Int64
+	filesDone, filesTotal atomic.Int64
+
+	verify     string       // Checksum algorithm copies are verified with ("" = off)
+	verified   atomic.Int64 // Files whose checksum matched
+	mismatches []string     // Copies whose checksum differed (guarded by mu)
+}
+
+// checkpoint blocks while the job is paused and returns an error once it is cancelled
+func (j *Job) checkpoint() error {
+	if j == nil {
+		return nil
+	}
+	for {
+		j.mu.Lock()
+		resume := j.resume
+		j.mu.Unlock()
+		if resume == nil {
+			return j.ctx.Err()
+		}
+		select {
+		case <-resume:
+		case <-j.ctx.Done():
+			return j.ctx.Err()
+		}
+	}
+}
+
+// progress counts n copied bytes and honours pause/cancel; it is passed to fs.CopyContents
+func (j *Job) progress(n int64) error {
+	if j == nil {
+		return nil
+	}
+	j.bytesDone.Add(n)
+	return j.checkpoint()
+}
+
+// itemSize is the total size and file count of a file or directory tree
+type itemSize struct {
+	bytes, files int64
+}
+
+// measure adds the size and file count of paths to the job totals and returns them per path
+func (j *Job) measure(paths []string, follow bool) []itemSize {
+	sizes := make([]itemSize, len(paths))
+	if j == nil {
+		return sizes
+	}
+	for i, path := range paths {
+		bytes, files := treeSize(path, follow)
+		sizes[i] = itemSize{bytes: bytes, files: files}
+		j.bytesTotal.Add(bytes)
+		j.filesTotal.Add(files)
+	}
+	return sizes
+}
+
+// advance counts an item as done without streaming it, e.g. after a rename
+func (j *Job) advance(size itemSize) {
+	if j == nil {
+		return
+	}
+	j.bytesDone.Add(size.bytes)
+	j.filesDone.Add(size.files)
+}
+
+// setCurrent records the name of the item being processed
+func (j *Job) setCurrent(name string) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	j.current = name
+	j.mu.Unlock()
+}
+
+// fileDone counts a finished file
+func (j *Job) fileDone() {
+	if j == nil {
+		return
+	}
+	j.filesDone.Add(1)
+}
+
+// verifying reports whether copies made by the job are checked against their source
+func (j *Job) verifying() bool {
+	return j != nil && j.verify != ""
+}
+
+// addMismatch records a copy that failed verification
+func (j *Job) addMismatch(path string) {
+	j.mu.Lock()
+	j.mismatches = append(j.mismatches, path)
+	j.mu.Unlock()
+}
+
+// mismatchesUnder returns the copies inside dir that failed verification, relative to dir
+func (j *Job) mismatchesUnder(dir string) []string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	var rel []string
+	for _, path := range j.mismatches {
+		r, err := filepath.Rel(dir, path)
+		if err == nil && r != ".." && !strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+			rel = append(rel, r)
+		}
+	}
+	return rel
+}
+
+// info returns a snapshot of the job for the jobs panel
+func (j *Job) info() ui.JobInfo {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	info := ui.JobInfo{
+		ID:         j.ID,
+		Label:      j.Label,
+		Status:     j.status,
+		Current:    j.current,
+		BytesDone:  j.bytesDone.Load(),
+		BytesTotal: j.bytesTotal.Load(),
+		FilesDone:  int(j.filesDone.Load()),
+		FilesTotal: int(j.filesTotal.Load()),
+	}
+	if j.err != nil {
+		info.Err = j.err.Error()
+	}
+
+	// Throughput only counts time spent running, not paused
+	if !j.started.IsZero() {
+		active := time.Since(j.started) - j.pausedTotal
+		if !j.pausedAt.IsZero() {
+			active -= time.Since(j.pausedAt)
+		}
+		if active > 0 {
+			info.Throughput = float64(info.BytesDone) / active.Seconds()
+		}
+		if info.Throughput > 0 && info.BytesTotal > info.BytesDone {
+			info.ETA = time.Duration(float64(info.BytesTotal-info.BytesDone) / info.Throughput * float64(time.Second))
+		}
+	}
+	return info
+}
+
+// treeSize returns the total size and number of files under path.
+// Unless follow is set, symlinks count as files with no size.
+func treeSize(path string, follow bool) (bytes, files int64) {
+	if fs.InArchive(path) || fs.IsRemote(path) {
+		return vfsTreeSize(path)
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		return 0, 0
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		if !follow {
+			return 0, 1
+		}
+		if info, err = os.Stat(path); err != nil {
+			return 0, 1
+		}
+	}
+	if !info.IsDir() {
+		return info.Size(), 1
+	}
+
+	var total, count atomic.Int64
+	conf := &fastwalk.Config{Follow: follow}
+	fastwalk.Walk(conf, path, func(fullPath string, d iofs.DirEntry, walkErr error) error {
+		if walkErr != nil || d.IsDir() {
+			return nil
+		}
+		isLink := d.Type()&os.ModeSymlink != 0
+		if isLink && !follow {
+			count.Add(1)
+			return nil
+		}
+		info, err := fastwalk.StatDirEntry(fullPath, d)
+		if err != nil {
+			if isLink {
+				count.Add(1) // Dangling links are copied as links
+			}
+			return nil
+		}
+		if !info.IsDir() {
+			total.Add(info.Size())
+			count.Add(1)
+		}
+		return nil
+	})
+	return total.Load(), count.Load()
+}
+
+// JobManager runs file operations in the background, a few at a time.
+// Finished and cancelled jobs are dropped; failed jobs stay listed until dismissed.
+type JobManager struct {
+	mu       sync.Mutex
+	jobs     []*Job // Queued, running, paused and failed jobs in submission order
+	nextID   int64
+	slots    chan struct{}
+	onChange func() // Called (without locks held) whenever job state changes
+}
+
+// NewJobManager creates a manager running at most maxConcurrent jobs at once
+func NewJobManager(maxConcurrent int, onChange func()) *JobManager {
+	return &JobManager{
+		slots:    make(chan struct{}, maxConcurrent),
+		onChange: onChange,
+	}
+}
+
+// Submit queues a job; run performs the work and returns nil, a context error when
+// cancelled, or the reason the job failed
+func (m *JobManager) Submit(kind JobKind, label string, run func(j *Job) error) *Job {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	m.mu.Lock()
+	m.nextID++
+	j := &Job{ID: m.nextID, Kind: kind, Label: label, ctx: ctx, cancel: cancel, status: ui.JobQueued}
+	m.jobs = append(m.jobs, j)
+	m.mu.Unlock()
+
+	debug.Log(debug.APP, "Job %d queued: %s", j.ID, label)
+	m.onChange()
+
+	go m.run(j, run)
+	return j
+}
+
+// run waits for a free slot, executes the job and publishes progress while it runs.
+// A job paused while queued holds no slot until it is resumed.
+func (m *JobManager) run(j *Job, run func(j *Job) error) {
+	for {
+		if err := j.checkpoint(); err != nil {
+			m.finish(j, err)
+			return
+		}
+		select {
+		case m.slots <- struct{}{}:
+		case <-j.ctx.Done():
+			m.finish(j, j.ctx.Err())
+			return
+		}
+
+		j.mu.Lock()
+		start := j.status == ui.JobQueued
+		if start {
+			j.started = time.Now()
+			j.status = ui.JobRunning
+		}
+		j.mu.Unlock()
+		if start {
+			break
+		}
+		<-m.slots // Paused while waiting for the slot
+	}
+	defer func() { <-m.slots }()
+	m.onChange()
+
+	// Refresh the panel periodically so byte counts, throughput and ETA stay current
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(progressThrottleTime)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.onChange()
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	err := run(j)
+	close(stop)
+	m.finish(j, err)
+}
+
+// finish removes a completed or cancelled job, or marks it failed
+func (m *JobManager) finish(j *Job, err error) {
+	j.cancel()
+
+	m.mu.Lock()
+	if err != nil && !errors.Is(err, context.Canceled) {
+		j.mu.Lock()
+		j.status = ui.JobFailed
+		j.err = err
+		j.resume = nil
+		j.mu.Unlock()
+		debug.Log(debug.APP, "Job %d failed: %v", j.ID, err)
+	} else {
+		m.removeLocked(j.ID)
+		debug.Log(debug.APP, "Job %d finished (err=%v)", j.ID, err)
+	}
+	m.mu.Unlock()
+
+	m.onChange()
+}
+
+// Pause suspends a queued or running job at its next checkpoint
+func (m *JobManager) Pause(id int64) {
+	if j := m.find(id); j != nil {
+		j.mu.Lock()
+		if j.status == ui.JobQueued || j.status == ui.JobRunning {
+			if !j.started.IsZero() {
+				j.pausedAt = time.Now()
+			}
+			j.status = ui.JobPaused
+			j.resume = make(chan struct{})
+		}
+		j.mu.Unlock()
+		m.onChange()
+	}
+}
+
+// Resume continues a paused job
+func (m *JobManager) Resume(id int64) {
+	if j := m.find(id); j != nil {
+		j.mu.Lock()
+		if j.status == ui.JobPaused {
+			if j.started.IsZero() {
+				j.status = ui.JobQueued
+			} else {
+				j.status = ui.JobRunning
+				j.pausedTotal += time.Since(j.pausedAt)
+				j.pausedAt = time.Time{}
+			}
+			close(j.resume)
+			j.resume = nil
+		}
+		j.mu.Unlock()
+		m.onChange()
+	}
+}
+
+// Cancel stops a job; it is removed from the list once its body returns
+func (m *JobManager) Cancel(id int64) {
+	if j := m.find(id); j != nil {
+		debug.Log(debug.APP, "Job %d cancelled", id)
+		j.cancel()
+	}
+}
+
+// Dismiss removes a failed job from the list
+func (m *JobManager) Dismiss(id int64) {
+	m.mu.Lock()
+	for _, j := range m.jobs {
+		if j.ID == id && j.status == ui.JobFailed {
+			m.removeLocked(id)
+			break
+		}
+	}
+	m.mu.Unlock()
+	m.onChange()
+}
+
+// Snapshot returns the current jobs for display
+func (m *JobManager) Snapshot() []ui.JobInfo {
+	m.mu.Lock()
+	jobs := append([]*Job(nil), m.jobs...)
+	m.mu.Unlock()
+
+	infos := make([]ui.JobInfo, len(jobs))
+	for i, j := range jobs {
+		infos[i] = j.info()
+	}
+	return infos
+}
+
+func (m *JobManager) find(id int64) *Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, j := range m.jobs {
+		if j.ID == id {
+			return j
+		}
+	}
+	return nil
+}
+
+func (m *JobManager) removeLocked(id int64) {
+	for i, j := range m.jobs {
+		if j.ID == id {
+			m.jobs = append(m.jobs[:i], m.jobs[i+1:]...)
+			return
+		}
+	}
+}
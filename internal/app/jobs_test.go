@@ -0,0 +1,209 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/justyntemme/razor/internal/ui"
+)
+
+// testJob is a job body that runs until released, passing checkpoints so it
+// can be paused and cancelled
+type testJob struct {
+	release chan error
+	ticks   chan struct{} // Receives after each checkpoint passed
+}
+
+func newTestJob() *testJob {
+	return &testJob{release: make(chan error), ticks: make(chan struct{}, 100)}
+}
+
+func (b *testJob) run(j *Job) error {
+	for {
+		if err := j.checkpoint(); err != nil {
+			return err
+		}
+		select {
+		case b.ticks <- struct{}{}:
+		default:
+		}
+		select {
+		case err := <-b.release:
+			return err
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+// statuses describes the listed jobs, e.g. "1:running 2:queued"
+func statuses(m *JobManager) string {
+	names := map[ui.JobStatus]string{ui.JobQueued: "queued", ui.JobRunning: "running", ui.JobPaused: "paused", ui.JobFailed: "failed"}
+	s := ""
+	for _, info := range m.Snapshot() {
+		if s != "" {
+			s += " "
+		}
+		s += fmt.Sprintf("%d:%s", info.ID, names[info.Status])
+	}
+	return s
+}
+
+// waitStatuses waits for the jobs to reach want
+func waitStatuses(t *testing.T, m *JobManager, want string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for statuses(m) != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("jobs = %q, want %q", statuses(m), want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// drain empties the checkpoint ticks of a job body
+func (b *testJob) drain() {
+	for {
+		select {
+		case <-b.ticks:
+		default:
+			return
+		}
+	}
+}
+
+// submit queues jobs one at a time, each once the last has taken a slot or
+// is queued, so which job gets a free slot doesn't depend on scheduling
+func submit(t *testing.T, m *JobManager, bodies []*testJob, want ...string) {
+	t.Helper()
+	for i, b := range bodies {
+		m.Submit(JobCopy, "job", b.run)
+		waitStatuses(t, m, want[i])
+	}
+}
+
+func TestJobManagerSlots(t *testing.T) {
+	m := NewJobManager(2, func() {})
+	bodies := []*testJob{newTestJob(), newTestJob(), newTestJob()}
+	submit(t, m, bodies, "1:running", "1:running 2:running", "1:running 2:running 3:queued")
+
+	// A finished job is dropped and frees its slot
+	bodies[0].release <- nil
+	waitStatuses(t, m, "2:running 3:running")
+	bodies[1].release <- nil
+	bodies[2].release <- nil
+	waitStatuses(t, m, "")
+}
+
+func TestJobManagerPausedQueuedJobsHoldNoSlot(t *testing.T) {
+	m := NewJobManager(2, func() {})
+	bodies := []*testJob{newTestJob(), newTestJob(), newTestJob(), newTestJob(), newTestJob()}
+	submit(t, m, bodies[:2], "1:running", "1:running 2:running")
+	m.Submit(JobCopy, "job", bodies[2].run)
+	m.Submit(JobCopy, "job", bodies[3].run)
+	m.Pause(3)
+	m.Pause(4)
+	submit(t, m, bodies[4:], "1:running 2:running 3:paused 4:paused 5:queued")
+
+	// Later jobs run while the paused ones wait
+	bodies[0].release <- nil
+	bodies[1].release <- nil
+	waitStatuses(t, m, "3:paused 4:paused 5:running")
+
+	// Resumed, a queued job runs once a slot is free
+	m.Resume(3)
+	waitStatuses(t, m, "3:running 4:paused 5:running")
+	bodies[2].release <- nil
+	bodies[4].release <- nil
+	waitStatuses(t, m, "4:paused")
+	m.Resume(4)
+	waitStatuses(t, m, "4:running")
+	bodies[3].release <- nil
+	waitStatuses(t, m, "")
+}
+
+func TestJobManagerPauseResume(t *testing.T) {
+	m := NewJobManager(2, func() {})
+	b := newTestJob()
+	j := m.Submit(JobCopy, "job", b.run)
+	<-b.ticks
+
+	m.Pause(j.ID)
+	waitStatuses(t, m, "1:paused")
+	time.Sleep(5 * time.Millisecond) // Let the body reach its checkpoint
+	b.drain()
+	select {
+	case <-b.ticks:
+		t.Fatal("paused job passed a checkpoint")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	m.Resume(j.ID)
+	waitStatuses(t, m, "1:running")
+	select {
+	case <-b.ticks:
+	case <-time.After(5 * time.Second):
+		t.Fatal("resumed job did not continue")
+	}
+	b.release <- nil
+	waitStatuses(t, m, "")
+}
+
+func TestJobManagerCancel(t *testing.T) {
+	m := NewJobManager(1, func() {})
+	running, paused, queued := newTestJob(), newTestJob(), newTestJob()
+	submit(t, m, []*testJob{running, paused, queued}, "1:running", "1:running 2:queued", "1:running 2:queued 3:queued")
+	m.Pause(2)
+	waitStatuses(t, m, "1:running 2:paused 3:queued")
+
+	// Cancelled jobs are dropped, whether running, paused or queued
+	m.Cancel(2)
+	m.Cancel(3)
+	waitStatuses(t, m, "1:running")
+	m.Pause(1)
+	waitStatuses(t, m, "1:paused")
+	m.Cancel(1)
+	waitStatuses(t, m, "")
+}
+
+func TestJobManagerFailed(t *testing.T) {
+	m := NewJobManager(2, func() {})
+	b := newTestJob()
+	j := m.Submit(JobDelete, "job", b.run)
+	b.release <- errors.New("disk full")
+
+	// Failed jobs stay listed with their error until dismissed
+	waitStatuses(t, m, "1:failed")
+	if info := m.Snapshot()[0]; info.Err != "disk full" {
+		t.Errorf("error = %q", info.Err)
+	}
+	m.Pause(j.ID)
+	m.Resume(j.ID)
+	waitStatuses(t, m, "1:failed")
+	m.Dismiss(j.ID)
+	waitStatuses(t, m, "")
+
+	// Only failed jobs can be dismissed
+	b = newTestJob()
+	j = m.Submit(JobDelete, "job", b.run)
+	waitStatuses(t, m, "2:running")
+	m.Dismiss(j.ID)
+	waitStatuses(t, m, "2:running")
+	b.release <- nil
+	waitStatuses(t, m, "")
+}
+
+func TestMismatchesUnder(t *testing.T) {
+	dir := filepath.Join("/", "dst", "photos")
+	j := &Job{}
+	for _, p := range []string{"a.jpg", "..notes", "sub/..b.jpg", "../other.jpg", "../photos2/c.jpg"} {
+		j.addMismatch(filepath.Join(dir, filepath.FromSlash(p)))
+	}
+	want := strings.Join([]string{"a.jpg", "..notes", filepath.Join("sub", "..b.jpg")}, ",")
+	if got := strings.Join(j.mismatchesUnder(dir), ","); got != want {
+		t.Errorf("mismatchesUnder = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,293 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/justyntemme/razor/internal/debug"
+	"github.com/justyntemme/razor/internal/fs"
+	"github.com/justyntemme/razor/internal/store"
+	"github.com/justyntemme/razor/internal/trash"
+)
+
+// journal holds the undo and redo stacks for file operations.
+// Every change is mirrored to the store so history survives restarts.
+type journal struct {
+	mu     sync.Mutex
+	undo   []store.JournalEntry // Last entry is undone next
+	redo   []store.JournalEntry // Last entry is redone next
+	db     *store.DB
+	lastID int64
+}
+
+func newJournal(db *store.DB) *journal {
+	return &journal{db: db}
+}
+
+// load seeds the stacks with entries fetched from the store (oldest first).
+// Operations recorded before the fetch completed stay on top of the undo stack.
+func (j *journal) load(entries []store.JournalEntry) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var undo, redo []store.JournalEntry
+	for _, e := range entries {
+		if e.Undone {
+			// Undo walks backwards, so the oldest undone entry is redone first
+			redo = append([]store.JournalEntry{e}, redo...)
+		} else {
+			undo = append(undo, e)
+		}
+		j.lastID = max(j.lastID, e.ID)
+	}
+
+	if len(j.undo) > 0 {
+		// A new operation already invalidated the persisted redo stack
+		redo = nil
+	}
+	j.undo = append(undo, j.undo...)
+	j.redo = redo
+	debug.Log(debug.APP, "Journal loaded: %d undo, %d redo", len(j.undo), len(j.redo))
+}
+
+// record adds a completed operation and clears the redo stack
+func (j *journal) record(label string, steps []store.JournalStep) {
+	if len(steps) == 0 {
+		return
+	}
+
+	j.mu.Lock()
+	id := max(time.Now().UnixNano(), j.lastID+1)
+	j.lastID = id
+	entry := store.JournalEntry{ID: id, Label: label, Steps: steps, Timestamp: time.Now()}
+	j.undo = append(j.undo, entry)
+	j.redo = nil
+	j.mu.Unlock()
+
+	debug.Log(debug.APP, "Journal: recorded %q (%d steps)", label, len(steps))
+	j.db.RequestChan <- store.Request{Op: store.AddJournalEntry, Entry: entry}
+}
+
+// popUndo removes and returns the next entry to undo
+func (j *journal) popUndo() (store.JournalEntry, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if len(j.undo) == 0 {
+		return store.JournalEntry{}, false
+	}
+	entry := j.undo[len(j.undo)-1]
+	j.undo = j.undo[:len(j.undo)-1]
+	return entry, true
+}
+
+// popRedo removes and returns the next entry to redo
+func (j *journal) popRedo() (store.JournalEntry, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if len(j.redo) == 0 {
+		return store.JournalEntry{}, false
+	}
+	entry := j.redo[len(j.redo)-1]
+	j.redo = j.redo[:len(j.redo)-1]
+	return entry, true
+}
+
+// pushUndo puts a redone entry back on the undo stack
+func (j *journal) pushUndo(entry store.JournalEntry) {
+	j.mu.Lock()
+	entry.Undone = false
+	j.undo = append(j.undo, entry)
+	j.mu.Unlock()
+	j.db.RequestChan <- store.Request{Op: store.SetJournalUndone, Entry: entry}
+}
+
+// pushRedo puts an undone entry on the redo stack
+func (j *journal) pushRedo(entry store.JournalEntry) {
+	j.mu.Lock()
+	entry.Undone = true
+	j.redo = append(j.redo, entry)
+	j.mu.Unlock()
+	j.db.RequestChan <- store.Request{Op: store.SetJournalUndone, Entry: entry}
+}
+
+// discard forgets an entry that could not be undone or redone
+func (j *journal) discard(entry store.JournalEntry) {
+	j.db.RequestChan <- store.Request{Op: store.DeleteJournalEntry, Entry: entry}
+}
+
+// journalLabel describes an operation on one or more paths, e.g. "Rename notes.txt" or "Paste 3 items"
+func journalLabel(verb string, paths []string) string {
+	if len(paths) == 1 {
+		return verb + " " + filepath.Base(paths[0])
+	}
+	return fmt.Sprintf("%s %d items", verb, len(paths))
+}
+
+// undo reverts the most recent file operation
+func (o *Orchestrator) undo() {
+	entry, ok := o.journal.popUndo()
+	if !ok {
+		debug.Log(debug.APP, "Undo: nothing to undo")
+		return
+	}
+
+	o.setProgress(true, "Undoing "+entry.Label, 0, 0)
+	replaced := replacedPaths(entry.Steps)
+	var err error
+	for i := len(entry.Steps) - 1; i >= 0 && err == nil; i-- {
+		err = o.revertJournalStep(entry.Steps[i], replaced)
+	}
+	o.setProgress(false, "", 0, 0)
+
+	if err != nil {
+		o.journal.discard(entry)
+		o.ui.ShowError("Cannot undo " + entry.Label + ": " + err.Error())
+	} else {
+		o.journal.pushRedo(entry)
+		o.ui.ShowSuccess("Undid " + entry.Label)
+	}
+	o.refreshCurrentDir()
+}
+
+// redo re-applies the most recently undone file operation
+func (o *Orchestrator) redo() {
+	entry, ok := o.journal.popRedo()
+	if !ok {
+		debug.Log(debug.APP, "Redo: nothing to redo")
+		return
+	}
+
+	o.setProgress(true, "Redoing "+entry.Label, 0, 0)
+	var err error
+	for i := 0; i < len(entry.Steps) && err == nil; i++ {
+		err = o.applyJournalStep(entry.Steps[i])
+	}
+	o.setProgress(false, "", 0, 0)
+
+	if err != nil {
+		o.journal.discard(entry)
+		o.ui.ShowError("Cannot redo " + entry.Label + ": " + err.Error())
+	} else {
+		o.journal.pushUndo(entry)
+		o.ui.ShowSuccess("Redid " + entry.Label)
+	}
+	o.refreshCurrentDir()
+}
+
+// replacedPaths returns the paths an operation trashed, among them items replaced by a
+// copy or move
+func replacedPaths(steps []store.JournalStep) map[string]bool {
+	replaced := make(map[string]bool)
+	for _, step := range steps {
+		if step.Op == store.JournalTrash {
+			replaced[step.Src] = true
+		}
+	}
+	return replaced
+}
+
+// revertJournalStep undoes a single step. replaced holds the paths the operation trashed.
+func (o *Orchestrator) revertJournalStep(step store.JournalStep, replaced map[string]bool) error {
+	switch step.Op {
+	case store.JournalMove:
+		return o.moveBack(step.Dst, step.Src)
+	case store.JournalCopy, store.JournalCreate:
+		if replaced[step.Dst] {
+			// A copy that replaced an item is deleted: trashing it would make it the
+			// latest item from that path, restored in place of the one it replaced
+			return deleteItem(step.Dst)
+		}
+		return discardItem(step.Dst)
+	case store.JournalTrash:
+		return restoreTrashed(step.Src)
+	}
+	return fmt.Errorf("unknown journal operation %q", step.Op)
+}
+
+// applyJournalStep redoes a single step
+func (o *Orchestrator) applyJournalStep(step store.JournalStep) error {
+	switch step.Op {
+	case store.JournalMove:
+		return o.moveBack(step.Src, step.Dst)
+	case store.JournalCopy:
+		if pathExists(step.Dst) {
+			return fmt.Errorf("%s already exists", filepath.Base(step.Dst))
+		}
+		if fs.IsRemote(step.Src) || fs.IsRemote(step.Dst) {
+			return copyRemote(nil, step.Src, step.Dst)
+		}
+		if fs.InArchive(step.Src) {
+			return extractMember(nil, step.Src, step.Dst)
+		}
+		if step.IsDir {
+			return o.copyDir(nil, step.Src, step.Dst)
+		}
+		return o.copyFile(nil, step.Src, step.Dst)
+	case store.JournalTrash:
+		return trash.MoveToTrash(step.Src)
+	case store.JournalCreate:
+		if step.IsDir {
+			return mkdir(step.Dst)
+		}
+		if fs.IsRemote(step.Dst) {
+			if pathExists(step.Dst) {
+				return fmt.Errorf("%s already exists", filepath.Base(step.Dst))
+			}
+			file, err := fs.Create(step.Dst)
+			if err != nil {
+				return err
+			}
+			return file.Close()
+		}
+		file, err := os.OpenFile(step.Dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, FilePermission)
+		if err != nil {
+			return err
+		}
+		return file.Close()
+	}
+	return fmt.Errorf("unknown journal operation %q", step.Op)
+}
+
+// moveBack moves src to dst like a paste would, but never overwrites dst
+func (o *Orchestrator) moveBack(src, dst string) error {
+	if pathExists(dst) {
+		return fmt.Errorf("%s already exists", filepath.Base(dst))
+	}
+	info, err := o.statSource(src)
+	if err != nil {
+		return err
+	}
+	return o.moveItem(nil, src, dst, info, itemSize{})
+}
+
+// discardItem removes an item created by an operation, preferring the trash so nothing is lost.
+// Remote items can't be trashed and are deleted.
+func discardItem(path string) error {
+	if trash.IsAvailable() && !fs.IsRemote(path) {
+		return trash.MoveToTrash(path)
+	}
+	return deleteItem(path)
+}
+
+// restoreTrashed restores the most recently trashed item that came from originalPath
+func restoreTrashed(originalPath string) error {
+	items, err := trash.List()
+	if err != nil {
+		return err
+	}
+
+	var latest *trash.Item
+	for i := range items {
+		if items[i].OriginalPath == originalPath && (latest == nil || items[i].DeletedAt.After(latest.DeletedAt)) {
+			latest = &items[i]
+		}
+	}
+	if latest == nil {
+		return errors.New(filepath.Base(originalPath) + " is no longer in the " + trash.DisplayName())
+	}
+	return trash.RestoreTo(*latest, originalPath)
+}
@@ -0,0 +1,202 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/justyntemme/razor/internal/config"
+	"github.com/justyntemme/razor/internal/store"
+	"github.com/justyntemme/razor/internal/trash"
+)
+
+// newJournalTest returns an orchestrator able to apply journal steps, and a
+// folder on the same device as its trash
+func newJournalTest(t *testing.T) (*Orchestrator, string) {
+	if runtime.GOOS != "linux" {
+		t.Skip("needs a trash that can be moved to a temporary folder")
+	}
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(dir, "data"))
+	work := filepath.Join(dir, "work")
+	os.Mkdir(work, 0o755)
+	return &Orchestrator{config: config.NewManager()}, work
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestJournalMoveStep(t *testing.T) {
+	o, dir := newJournalTest(t)
+	src, dst := filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")
+	writeFile(t, dst, "moved")
+	step := store.JournalStep{Op: store.JournalMove, Src: src, Dst: dst}
+
+	if err := o.revertJournalStep(step, nil); err != nil {
+		t.Fatal(err)
+	}
+	if pathExists(dst) || readFile(t, src) != "moved" {
+		t.Error("undo did not move the file back")
+	}
+	if err := o.applyJournalStep(step); err != nil {
+		t.Fatal(err)
+	}
+	if pathExists(src) || readFile(t, dst) != "moved" {
+		t.Error("redo did not move the file again")
+	}
+
+	// Neither direction overwrites what is in the way
+	writeFile(t, src, "in the way")
+	if err := o.revertJournalStep(step, nil); err == nil {
+		t.Error("undo replaced an existing file")
+	}
+	if readFile(t, src) != "in the way" || readFile(t, dst) != "moved" {
+		t.Error("a refused undo changed the files")
+	}
+}
+
+func TestJournalCopyStep(t *testing.T) {
+	o, dir := newJournalTest(t)
+	src, dst := filepath.Join(dir, "a.txt"), filepath.Join(dir, "copy.txt")
+	writeFile(t, src, "copied")
+	writeFile(t, dst, "copied")
+	step := store.JournalStep{Op: store.JournalCopy, Src: src, Dst: dst}
+
+	// Undoing a copy trashes it, so edits to it aren't lost
+	if err := o.revertJournalStep(step, nil); err != nil {
+		t.Fatal(err)
+	}
+	if pathExists(dst) || !pathExists(src) {
+		t.Fatal("undo did not remove just the copy")
+	}
+	if !inTrash(t, dst) {
+		t.Error("undone copy is not in the trash")
+	}
+
+	if err := o.applyJournalStep(step); err != nil {
+		t.Fatal(err)
+	}
+	if readFile(t, dst) != "copied" {
+		t.Error("redo did not copy again")
+	}
+	if err := o.applyJournalStep(step); err == nil {
+		t.Error("redo replaced an existing copy")
+	}
+}
+
+func TestJournalTrashStep(t *testing.T) {
+	o, dir := newJournalTest(t)
+	path := filepath.Join(dir, "a.txt")
+	writeFile(t, path, "trashed")
+	if err := trash.MoveToTrash(path); err != nil {
+		t.Fatal(err)
+	}
+	step := store.JournalStep{Op: store.JournalTrash, Src: path}
+
+	if err := o.revertJournalStep(step, nil); err != nil {
+		t.Fatal(err)
+	}
+	if readFile(t, path) != "trashed" || inTrash(t, path) {
+		t.Error("undo did not restore the file from the trash")
+	}
+	if err := o.applyJournalStep(step); err != nil {
+		t.Fatal(err)
+	}
+	if pathExists(path) || !inTrash(t, path) {
+		t.Error("redo did not trash the file again")
+	}
+}
+
+func TestJournalCreateStep(t *testing.T) {
+	o, dir := newJournalTest(t)
+	file := store.JournalStep{Op: store.JournalCreate, Dst: filepath.Join(dir, "new.txt")}
+	folder := store.JournalStep{Op: store.JournalCreate, Dst: filepath.Join(dir, "new"), IsDir: true}
+
+	for _, step := range []store.JournalStep{file, folder} {
+		if err := o.applyJournalStep(step); err != nil {
+			t.Fatal(err)
+		}
+		info, err := os.Stat(step.Dst)
+		if err != nil || info.IsDir() != step.IsDir {
+			t.Fatalf("redo created %v, %v", info, err)
+		}
+		if err := o.applyJournalStep(step); err == nil {
+			t.Errorf("redo of %s replaced it", step.Dst)
+		}
+		if err := o.revertJournalStep(step, nil); err != nil {
+			t.Fatal(err)
+		}
+		if pathExists(step.Dst) {
+			t.Errorf("undo left %s", step.Dst)
+		}
+	}
+}
+
+func TestJournalReplace(t *testing.T) {
+	o, dir := newJournalTest(t)
+	src, dst := filepath.Join(dir, "new", "report.txt"), filepath.Join(dir, "report.txt")
+	os.Mkdir(filepath.Dir(src), 0o755)
+	writeFile(t, src, "new")
+	writeFile(t, dst, "old")
+
+	// A paste replacing dst, as transferItems records it
+	replaced, err := replaceItem(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.copyFile(nil, src, dst); err != nil {
+		t.Fatal(err)
+	}
+	steps := append(replaced, store.JournalStep{Op: store.JournalCopy, Src: src, Dst: dst})
+	if len(steps) != 2 || steps[0].Op != store.JournalTrash {
+		t.Fatalf("steps = %+v", steps)
+	}
+
+	// Undo gets the replaced file back, not the copy
+	for i := len(steps) - 1; i >= 0; i-- {
+		if err := o.revertJournalStep(steps[i], replacedPaths(steps)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if readFile(t, dst) != "old" {
+		t.Errorf("undo left %q in place of the replaced file", readFile(t, dst))
+	}
+
+	for _, step := range steps {
+		if err := o.applyJournalStep(step); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if readFile(t, dst) != "new" || !inTrash(t, dst) {
+		t.Error("redo did not replace the file again")
+	}
+}
+
+// inTrash reports whether something that came from path is in the trash
+func inTrash(t *testing.T, path string) bool {
+	t.Helper()
+	items, err := trash.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range items {
+		if item.OriginalPath == path {
+			return true
+		}
+	}
+	return false
+}
@@ -1,7 +1,6 @@
 package app
 
 import (
-	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -11,6 +10,7 @@ import (
 
 // Navigate adds a path to history and requests directory contents.
 func (n *NavigationController) Navigate(path string) {
+	path = fs.StripPassword(path) // Favorites and -path may hold remote passwords
 	// Truncate forward history if we're not at the end
 	if n.HistoryIndex >= 0 && n.HistoryIndex < len(n.History)-1 {
 		n.History = n.History[:n.HistoryIndex+1]
@@ -50,6 +50,9 @@ func (n *NavigationController) GoBack(searchGen *int64) {
 	currentPath := n.state.State.CurrentPath
 	n.state.Mu.RUnlock()
 
+	if _, name, ok := fs.SplitRemotePath(currentPath); ok && name == "." {
+		return // Already at the root of remote storage
+	}
 	parent := filepath.Dir(currentPath)
 	if parent == currentPath {
 		return // Already at root
@@ -93,6 +96,7 @@ func (n *NavigationController) RequestDir(path string) {
 // - Absolute paths
 // - Windows drive letters (C:, D:, etc.)
 // - Root path (/)
+// - Remote storage URLs (sftp://host/path and the like), without their password
 func (n *NavigationController) ExpandPath(input string) string {
 	input = strings.TrimSpace(input)
 	if input == "" {
@@ -114,6 +118,9 @@ func (n *NavigationController) ExpandPath(input string) string {
 	}
 
 	// Check if it's an absolute path
+	if fs.IsRemote(input) {
+		return fs.StripPassword(input)
+	}
 	if isAbsolutePath(input) {
 		return filepath.Clean(input)
 	}
@@ -126,12 +133,14 @@ func (n *NavigationController) ExpandPath(input string) string {
 }
 
 // ValidatePath checks if a path exists and returns info about it.
+// Archives and the folders inside them count as directories, except for
+// archives on remote storage, which can't be browsed.
 func (n *NavigationController) ValidatePath(path string) (exists bool, isDir bool) {
-	info, err := os.Stat(path)
+	info, err := fs.Stat(path)
 	if err != nil {
 		return false, false
 	}
-	return true, info.IsDir()
+	return true, info.IsDir() || info.Mode().IsRegular() && fs.IsArchive(path) && !fs.InArchive(path) && !fs.IsRemote(path)
 }
 
 // OpenFileLocation navigates to the directory containing the given file.
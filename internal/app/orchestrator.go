@@ -1,6 +1,8 @@
 package app
 
 import (
+	"context"
+	"fmt"
 	"image"
 	"log"
 	"os"
@@ -35,15 +37,22 @@ type Orchestrator struct {
 	window  *app.Window
 	fs      *fs.System
 	store   *store.DB
+	indexer *fs.Indexer // Filename index for index: searches (nil when off)
 	config  *config.Manager
 	ui      *ui.Renderer
 	watcher *DirectoryWatcher // Directory change watcher
 
+	contentIndexer *fs.ContentIndexer // FTS5 content index for the fts engine (nil when off)
+
 	// Shared state (protected by stateMu)
 	stateMu    sync.RWMutex
 	state      ui.State
 	stateOwner *StateOwner   // Single source of truth for entries
 	searchGen  atomic.Int64  // Search generation counter
+	streamGen  int64         // Search whose results are being streamed into the list (event loop only)
+
+	// Saved search to run once its directory has loaded (guarded by stateMu)
+	pendingSearch *config.SavedSearch
 
 	// Controllers (own their domain-specific state, share deps/state via pointers)
 	searchCtrl *SearchController
@@ -54,6 +63,12 @@ type Orchestrator struct {
 	sortAsc      bool
 	showDotfiles bool
 
+	// Dual-pane mode (state.DualPane): the pane without focus
+	otherPane paneState
+
+	// Compare view: the compared folders and their latest comparison
+	compare compareState
+
 	// Tab state
 	tabs           []TabState
 	activeTabIndex int
@@ -65,9 +80,15 @@ type Orchestrator struct {
 	progressThrottleMu sync.Mutex
 
 	// Conflict resolution state
-	conflictResolution ui.ConflictResolution
-	conflictResponse   chan ui.ConflictResolution
-	conflictAbort      bool
+	conflictResponse chan ui.ConflictResolution
+	conflictMu       sync.Mutex // Serializes conflict dialogs across concurrent jobs
+
+	// Background file operations (copy, move, delete)
+	jobs        *JobManager
+	reportRetry func() // Retries the failed items of state.Report (guarded by stateMu)
+
+	// Undo/redo history of file operations
+	journal *journal
 
 	// Shared dependencies for controllers (set during init)
 	sharedDeps  *SharedDeps
@@ -84,8 +105,13 @@ func NewOrchestrator() *Orchestrator {
 	}
 	cfg := cfgMgr.Get()
 
-	// Detect available search engines
+	// Detect available search engines; the content index needs configured roots
 	engines := search.DetectEngines()
+	for i := range engines {
+		if engines[i].Engine == search.EngineFTS && len(cfg.Search.ContentIndexRoots) > 0 {
+			engines[i].Name, engines[i].Available = "Content index", true
+		}
+	}
 
 	// Convert to UI format
 	uiEngines := make([]ui.SearchEngineInfo, len(engines))
@@ -116,6 +142,10 @@ func NewOrchestrator() *Orchestrator {
 		showDotfiles:     cfg.UI.FileList.ShowDotfiles,
 		conflictResponse: make(chan ui.ConflictResolution, 1),
 	}
+	o.otherPane.owner = NewStateOwner(window, cfg.UI.FileList.ShowDotfiles)
+
+	o.journal = newJournal(o.store)
+	o.jobs = NewJobManager(maxConcurrentJobs, o.syncJobs)
 
 	// Create shared dependencies and state for controllers
 	o.sharedDeps = &SharedDeps{
@@ -134,6 +164,7 @@ func NewOrchestrator() *Orchestrator {
 	// Create controllers with shared dependencies
 	o.searchCtrl = NewSearchController(o.sharedDeps, o.sharedState, engines)
 	o.searchCtrl.DefaultDepth = cfg.Search.DefaultDepth
+	o.searchCtrl.RespectIgnore = cfg.Search.RespectIgnoreFiles
 
 	o.navCtrl = NewNavigationController(o.sharedDeps, o.sharedState)
 
@@ -178,7 +209,7 @@ func NewOrchestrator() *Orchestrator {
 	}
 
 	// Set hotkeys from config
-	o.ui.SetHotkeys(cfg.Hotkeys)
+	o.ui.SetHotkeys(o.config.GetHotkeys())
 
 	// Set search engine from config
 	o.searchCtrl.ChangeEngine(cfg.Search.Engine)
@@ -221,6 +252,16 @@ func (o *Orchestrator) loadFavoritesFromConfig() {
 		})
 	}
 
+	// Saved searches follow the favorites
+	for _, saved := range o.config.GetSavedSearches() {
+		o.state.FavList = append(o.state.FavList, ui.FavoriteItem{
+			Path:  o.expandHome(saved.Path),
+			Name:  saved.Name,
+			Type:  ui.FavoriteTypeSearch,
+			Query: saved.Query,
+		})
+	}
+
 	// Always add Trash at the end if available
 	if trash.IsAvailable() {
 		o.state.FavList = append(o.state.FavList, ui.FavoriteItem{
@@ -241,8 +282,9 @@ func (o *Orchestrator) Run(startPath string) error {
 	// Use ~/.config/razor/ on all platforms for consistency
 	dbPath := filepath.Join(o.sharedDeps.HomePath, ".config", "razor", "razor.db")
 	debug.Log(debug.APP, "Opening database: %s", dbPath)
-	if err := o.store.Open(dbPath); err != nil {
-		log.Printf("Failed to open DB: %v", err)
+	dbErr := o.store.Open(dbPath)
+	if dbErr != nil {
+		log.Printf("Failed to open DB: %v", dbErr)
 	}
 	defer o.store.Close()
 
@@ -255,10 +297,26 @@ func (o *Orchestrator) Run(startPath string) error {
 		defer o.watcher.Close()
 	}
 
+	// Start the indexers before the fs goroutine that searches with them
+	if dbErr == nil {
+		o.startIndexers()
+		if o.indexer != nil {
+			defer o.indexer.Close()
+		}
+		if o.contentIndexer != nil {
+			defer o.contentIndexer.Close()
+		}
+	}
+
 	go o.fs.Start()
 	go o.store.Start()
 	go o.processEvents()
 
+	// Load undo/redo history from the database
+	if dbErr == nil {
+		o.store.RequestChan <- store.Request{Op: store.FetchJournal}
+	}
+
 	// Favorites are now loaded from config.json in NewOrchestrator
 	// Settings are also loaded from config.json
 
@@ -273,6 +331,7 @@ func (o *Orchestrator) Run(startPath string) error {
 	}
 
 	// Initialize tabs with the starting path
+	startPath = fs.StripPassword(startPath)
 	o.initializeTabs(startPath)
 
 	o.navCtrl.Navigate(startPath)
@@ -336,7 +395,7 @@ func (o *Orchestrator) Run(startPath string) error {
 			o.stateMu.RUnlock()
 
 			if evt.Action != ui.ActionNone {
-				debug.Log(debug.UI_EVENT, "Action: %d Path: %s Index: %d", evt.Action, evt.Path, evt.NewIndex)
+				debug.Log(debug.UI_EVENT, "Action: %d Path: %s Index: %d", evt.Action, fs.StripPassword(evt.Path), evt.NewIndex)
 			}
 
 			o.handleUIEvent(evt)
@@ -360,13 +419,16 @@ func (o *Orchestrator) refreshDrives() {
 	}()
 }
 
-// resetUIState cancels any active rename, hides preview, exits recent/trash view,
-// and clears multi-select mode. Called before navigation operations to ensure clean state.
+// resetUIState cancels any active rename, hides preview, exits recent/trash/duplicates/compare
+// view, and clears multi-select mode. Called before navigation operations to ensure clean state.
 func (o *Orchestrator) resetUIState() {
 	o.ui.CancelRename()
 	o.ui.HidePreview()
 	o.ui.SetRecentView(false)
 	o.ui.SetTrashView(false)
+	o.ui.SetDuplicatesView(false)
+	o.ui.SetCompareView(false)
+	o.clearCompare()
 	o.ui.ResetMultiSelect()
 	o.state.SelectedIndices = nil
 	o.state.SelectedIndex = -1
@@ -398,12 +460,9 @@ func (o *Orchestrator) handleUIEvent(evt ui.UIEvent) {
 			o.navCtrl.Navigate(expandedPath)
 		} else if exists && !isDir {
 			// It's a file, open it instead
-			if err := platformOpen(expandedPath); err != nil {
-				log.Printf("Error opening file: %v", err)
-			}
-			o.store.RequestChan <- store.Request{Op: store.AddRecentFile, Path: expandedPath}
+			o.openFile(expandedPath)
 		} else {
-			log.Printf("Path does not exist: %s (expanded from: %s)", expandedPath, evt.Path)
+			log.Printf("Path does not exist: %s (expanded from: %s)", expandedPath, fs.StripPassword(evt.Path))
 		}
 	case ui.ActionBack:
 		o.resetUIState()
@@ -424,7 +483,7 @@ func (o *Orchestrator) handleUIEvent(evt ui.UIEvent) {
 		if evt.NewIndex >= 0 && evt.NewIndex < len(o.state.Entries) {
 			entry := &o.state.Entries[evt.NewIndex]
 			if !entry.IsDir {
-				o.ui.ShowPreview(entry.Path)
+				o.ui.ShowPreviewHits(entry.Path, entry.Hits)
 			} else {
 				o.ui.HidePreview()
 			}
@@ -474,7 +533,7 @@ func (o *Orchestrator) handleUIEvent(evt ui.UIEvent) {
 			if o.state.SelectedIndex < len(o.state.Entries) {
 				entry := &o.state.Entries[o.state.SelectedIndex]
 				if !entry.IsDir {
-					o.ui.ShowPreview(entry.Path)
+					o.ui.ShowPreviewHits(entry.Path, entry.Hits)
 				}
 			}
 		}
@@ -502,11 +561,7 @@ func (o *Orchestrator) handleUIEvent(evt ui.UIEvent) {
 	case ui.ActionSearch:
 		o.searchCtrl.DoSearch(evt.Path, evt.SearchSubmitted, o.restoreDirectory, o.setProgress)
 	case ui.ActionOpen:
-		if err := platformOpen(evt.Path); err != nil {
-			log.Printf("Error opening file: %v", err)
-		}
-		// Track in recent files
-		o.store.RequestChan <- store.Request{Op: store.AddRecentFile, Path: evt.Path}
+		o.openFile(evt.Path)
 	case ui.ActionOpenWith:
 		// Show the system "Open With" dialog
 		if err := platformOpenWith(evt.Path, ""); err != nil {
@@ -537,9 +592,53 @@ func (o *Orchestrator) handleUIEvent(evt ui.UIEvent) {
 		o.config.RemoveFavorite(evt.Path)
 		o.loadFavoritesFromConfig()
 		o.window.Invalidate()
+	case ui.ActionSaveSearch:
+		// Save the query with the current directory and engine to config.json
+		o.config.AddSavedSearch(config.SavedSearch{
+			Name:   evt.SavedSearch,
+			Query:  evt.Path,
+			Path:   o.state.CurrentPath,
+			Engine: o.searchCtrl.SelectedEngine.String(),
+		})
+		o.loadFavoritesFromConfig()
+		o.window.Invalidate()
+	case ui.ActionRunSavedSearch:
+		o.runSavedSearch(evt.SavedSearch)
+	case ui.ActionRemoveSavedSearch:
+		o.config.RemoveSavedSearch(evt.SavedSearch)
+		o.loadFavoritesFromConfig()
+		o.window.Invalidate()
+	case ui.ActionFindDuplicates:
+		o.findDuplicates(evt.Path)
+	case ui.ActionResolveDuplicates:
+		go o.resolveDuplicates(evt.Paths, evt.Dedupe)
+	case ui.ActionCompareDirs:
+		if len(evt.Paths) == 2 {
+			o.compareDirs(evt.Paths[0], evt.Paths[1], evt.CompareContents)
+		}
+	case ui.ActionPlanSync:
+		o.planSync(evt.Paths, evt.Sync)
+	case ui.ActionRunSync:
+		o.runSync()
+	case ui.ActionCompress:
+		o.doCompress(evt.Paths, evt.FileName)
+	case ui.ActionExtractHere:
+		o.doExtract(evt.Path, false)
+	case ui.ActionExtractToFolder:
+		o.doExtract(evt.Path, true)
+	case ui.ActionToggleDualPane:
+		o.toggleDualPane()
+	case ui.ActionSwitchPane:
+		o.switchPane()
+	case ui.ActionCopyToPane:
+		o.transferToOtherPane(evt.Paths, false)
+	case ui.ActionMoveToPane:
+		o.transferToOtherPane(evt.Paths, true)
 	case ui.ActionSort:
 		o.sortColumn, o.sortAsc = evt.SortColumn, evt.SortAscending
 		o.stateOwner.SetSort(evt.SortColumn, evt.SortAscending)
+		o.otherPane.owner.SetSort(evt.SortColumn, evt.SortAscending)
+		o.syncOtherPane()
 		o.applyFilterAndSort()
 		o.window.Invalidate()
 	case ui.ActionToggleDotfiles:
@@ -547,6 +646,8 @@ func (o *Orchestrator) handleUIEvent(evt ui.UIEvent) {
 		o.showDotfiles = evt.ShowDotfiles
 		o.config.SetShowDotfiles(o.showDotfiles)
 		o.stateOwner.ToggleDotfiles(evt.ShowDotfiles)
+		o.otherPane.owner.ToggleDotfiles(evt.ShowDotfiles)
+		o.syncOtherPane()
 		o.applyFilterAndSort()
 		o.window.Invalidate()
 	case ui.ActionCopy:
@@ -565,7 +666,7 @@ func (o *Orchestrator) handleUIEvent(evt ui.UIEvent) {
 		o.window.Invalidate()
 	case ui.ActionPaste:
 		if o.state.Clipboard != nil {
-			go o.doPaste()
+			go o.doPaste(evt.Verify)
 		}
 	case ui.ActionMove:
 		// Drag-and-drop move: evt.Paths = sources, evt.Path = destination directory
@@ -585,11 +686,19 @@ func (o *Orchestrator) handleUIEvent(evt ui.UIEvent) {
 		go o.doCreateFolder(evt.FileName)
 	case ui.ActionRename:
 		go o.doRename(evt.OldPath, evt.Path)
+	case ui.ActionBatchRename:
+		go o.doBatchRename(evt.Paths, evt.Rename)
 	case ui.ActionClearSearch:
 		debug.Log(debug.APP, "ClearSearch: cancelling search")
 		o.searchCtrl.CancelSearch(o.setProgress)
-		// Restore from StateOwner (no disk access needed if cached)
-		if !o.restoreDirectory() {
+		if o.ui.IsDuplicatesView() || o.ui.IsCompareView() {
+			// Duplicates or a comparison replaced the listing; read the directory again
+			o.ui.SetDuplicatesView(false)
+			o.ui.SetCompareView(false)
+			o.clearCompare()
+			o.navCtrl.RequestDir(o.state.CurrentPath)
+		} else if !o.restoreDirectory() {
+			// Restore from StateOwner (no disk access needed if cached)
 			// Fallback: re-fetch if entries are empty
 			o.navCtrl.RequestDir(o.state.CurrentPath)
 		}
@@ -602,7 +711,6 @@ func (o *Orchestrator) handleUIEvent(evt ui.UIEvent) {
 		o.handleConflictResolution(ui.ConflictSkipAll)
 	case ui.ActionConflictStop:
 		o.handleConflictResolution(ui.ConflictAsk) // Stop uses Ask to signal abort
-		o.conflictAbort = true
 	case ui.ActionChangeSearchEngine:
 		o.searchCtrl.ChangeEngine(evt.SearchEngine)
 		// Save setting to config.json
@@ -643,6 +751,24 @@ func (o *Orchestrator) handleUIEvent(evt ui.UIEvent) {
 		} else if evt.Path != "" {
 			go o.doPermanentDelete(evt.Path)
 		}
+	case ui.ActionRestoreFromTrash:
+		if len(evt.Paths) > 0 {
+			go o.restoreFromTrash(evt.Paths)
+		}
+	case ui.ActionUndo:
+		go o.undo()
+	case ui.ActionRedo:
+		go o.redo()
+	case ui.ActionPauseJob:
+		o.jobs.Pause(evt.JobID)
+	case ui.ActionResumeJob:
+		o.jobs.Resume(evt.JobID)
+	case ui.ActionCancelJob:
+		o.jobs.Cancel(evt.JobID)
+	case ui.ActionDismissJob:
+		o.jobs.Dismiss(evt.JobID)
+	case ui.ActionRetryReport:
+		o.retryReport()
 	case ui.ActionOpenFileLocation:
 		// Navigate to the directory containing the file (with file selection)
 		o.openFileLocation(evt.Path)
@@ -744,8 +870,57 @@ func (o *Orchestrator) processEvents() {
 	}
 }
 
+// startIndexers starts the background filename and content indexers for the
+// roots configured in indexRoots and contentIndexRoots
+func (o *Orchestrator) startIndexers() {
+	cfg := o.config.Get().Search
+	interval := time.Duration(cfg.IndexRescanMinutes) * time.Minute
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	if roots := o.expandRoots(cfg.IndexRoots); len(roots) > 0 {
+		o.indexer = fs.NewIndexer(o.store.FileIndex(), roots, interval)
+		o.fs.Indexer = o.indexer
+		go o.indexer.Start()
+	}
+	if roots := o.expandRoots(cfg.ContentIndexRoots); len(roots) > 0 {
+		o.contentIndexer = fs.NewContentIndexer(o.store.ContentIndex(), roots, interval)
+		o.fs.ContentIndexer = o.contentIndexer
+		go o.contentIndexer.Start()
+	}
+}
+
+// expandRoots expands a leading ~ in configured index roots
+func (o *Orchestrator) expandRoots(paths []string) []string {
+	roots := make([]string, 0, len(paths))
+	for _, root := range paths {
+		roots = append(roots, o.expandHome(root))
+	}
+	return roots
+}
+
+// expandHome expands a leading ~ in a configured path
+func (o *Orchestrator) expandHome(path string) string {
+	if len(path) > 0 && path[0] == '~' {
+		return filepath.Join(o.sharedDeps.HomePath, path[1:])
+	}
+	return path
+}
+
 // handleDirectoryChange refreshes the display if the changed directory is currently visible
 func (o *Orchestrator) handleDirectoryChange(changedDir string) {
+	// Keep the filename index fresh for directories the watcher sees
+	if o.indexer != nil {
+		o.indexer.Refresh(changedDir)
+	}
+	if o.contentIndexer != nil {
+		o.contentIndexer.Refresh(changedDir)
+	}
+
+	// The pane without focus in dual-pane mode reads its directory itself
+	o.refreshOtherPane(changedDir)
+
 	o.stateMu.RLock()
 	currentPath := o.state.CurrentPath
 	isSearchResult := o.state.IsSearchResult
@@ -816,8 +991,10 @@ func (o *Orchestrator) handleFSResponse(resp fs.Response) {
 	debug.Log(debug.APP, "FSResponse: op=%d path=%q entries=%d gen=%d cancelled=%v err=%v",
 		resp.Op, resp.Path, len(resp.Entries), resp.Gen, resp.Cancelled, resp.Err)
 
-	// Clear any progress indicator
-	o.setProgress(false, "", 0, 0)
+	// Clear any progress indicator (a search sending partial results is still running)
+	if !resp.Partial {
+		o.setProgress(false, "", 0, 0)
+	}
 
 	// If cancelled, just ignore the response
 	if resp.Cancelled {
@@ -836,14 +1013,26 @@ func (o *Orchestrator) handleFSResponse(resp fs.Response) {
 
 	if resp.Err != nil {
 		log.Printf("FS Error: %v", resp.Err)
+		switch resp.Op {
+		case fs.FindDuplicates:
+			o.ui.ShowError("Cannot find duplicates: " + resp.Err.Error())
+		case fs.CompareDirs:
+			o.ui.ShowError("Cannot compare folders: " + resp.Err.Error())
+		}
 		return
 	}
+	if resp.Op == fs.FindDuplicates && len(resp.Entries) == 0 {
+		o.ui.ShowSuccess("No duplicate files found")
+	}
+	if resp.Op == fs.CompareDirs {
+		o.setCompareResult(resp.Entries)
+	}
 
 	// Convert response entries to UI entries
 	entries := make([]ui.UIEntry, len(resp.Entries))
 	for i, e := range resp.Entries {
 		entries[i] = ui.UIEntry{
-			Name: e.Name, Path: e.Path, IsDir: e.IsDir, Size: e.Size, ModTime: e.ModTime,
+			Name: e.Name, Path: e.Path, IsDir: e.IsDir, Size: e.Size, ModTime: e.ModTime, Hits: e.Hits, DupGroup: e.Group, Compare: e.Compare,
 		}
 	}
 
@@ -883,16 +1072,25 @@ func (o *Orchestrator) handleFSResponse(resp fs.Response) {
 				debug.Log(debug.APP, "Successfully watching directory: %s", resp.Path)
 			}
 		}
-	} else {
-		// Search result: update entries but keep expanded state
+	} else if resp.Gen != o.streamGen {
+		// First results of a search: replace the list but keep expanded state
+		o.streamGen = resp.Gen
 		o.stateOwner.SetEntriesKeepExpanded(entries)
-		debug.Log(debug.APP, "FSResponse: SearchDir complete, %d results", len(entries))
+		debug.Log(debug.APP, "FSResponse: SearchDir first batch, %d results (partial=%v)", len(entries), resp.Partial)
 		// IsSearchResult and SearchQuery were already set in doSearch
+	} else {
+		// Later batches stream in, sorted into place
+		o.stateOwner.AppendEntries(entries)
+		debug.Log(debug.APP, "FSResponse: SearchDir batch, %d more results (partial=%v)", len(entries), resp.Partial)
 	}
 
 	// Sync StateOwner snapshot to o.state for UI rendering
 	snapshot := o.stateOwner.GetSnapshot()
 	o.stateMu.Lock()
+	if resp.Op == fs.SearchDir && resp.Gen == o.streamGen {
+		// Appended results re-sort the list; keep the selection on the same files
+		o.state.SelectedIndex, o.state.SelectedIndices = reselect(o.state.Entries, snapshot.Entries, o.state.SelectedIndex, o.state.SelectedIndices)
+	}
 	o.state.Entries = snapshot.Entries
 	o.state.CurrentPath = snapshot.CurrentPath
 	o.state.CanBack = snapshot.CanBack
@@ -904,11 +1102,80 @@ func (o *Orchestrator) handleFSResponse(resp fs.Response) {
 	if o.state.SelectedIndex >= len(o.state.Entries) {
 		o.state.SelectedIndex = -1
 	}
+	// A saved search waits for its directory before running
+	var pending *config.SavedSearch
+	if resp.Op == fs.FetchDir {
+		if o.pendingSearch != nil && o.pendingSearch.Path == resp.Path {
+			pending = o.pendingSearch
+		}
+		o.pendingSearch = nil
+	}
 	o.stateMu.Unlock()
 
+	if pending != nil {
+		o.searchCtrl.DoSavedSearch(*pending, o.setProgress)
+	}
 	o.window.Invalidate()
 }
 
+// runSavedSearch runs a saved search, first navigating to its directory if
+// it isn't the current one
+func (o *Orchestrator) runSavedSearch(name string) {
+	saved, ok := o.config.GetSavedSearch(name)
+	if !ok {
+		return
+	}
+	o.resetUIState()
+	saved.Path = o.expandHome(saved.Path)
+	if exists, isDir := o.navCtrl.ValidatePath(saved.Path); !exists || !isDir {
+		log.Printf("Saved search %q: directory %s not found", saved.Name, saved.Path)
+		return
+	}
+
+	o.stateMu.Lock()
+	here := o.state.CurrentPath == saved.Path
+	if !here {
+		o.pendingSearch = &saved
+	}
+	o.stateMu.Unlock()
+
+	if here {
+		o.searchCtrl.DoSavedSearch(saved, o.setProgress)
+	} else {
+		o.navCtrl.Navigate(saved.Path)
+	}
+}
+
+// reselect maps a selection on the old entries to the same paths in the new ones
+func reselect(old, entries []ui.UIEntry, selected int, indices map[int]bool) (int, map[int]bool) {
+	if selected < 0 && len(indices) == 0 {
+		return selected, indices
+	}
+	index := make(map[string]int, len(entries))
+	for i, e := range entries {
+		index[e.Path] = i
+	}
+	find := func(i int) (int, bool) {
+		if i < 0 || i >= len(old) {
+			return -1, false
+		}
+		j, ok := index[old[i].Path]
+		return j, ok
+	}
+
+	newSelected, _ := find(selected)
+	var newIndices map[int]bool
+	for i := range indices {
+		if j, ok := find(i); ok {
+			if newIndices == nil {
+				newIndices = make(map[int]bool, len(indices))
+			}
+			newIndices[j] = true
+		}
+	}
+	return newSelected, newIndices
+}
+
 func (o *Orchestrator) handleStoreResponse(resp store.Response) {
 	if resp.Err != nil {
 		log.Printf("Store Error: %v", resp.Err)
@@ -930,6 +1197,8 @@ func (o *Orchestrator) handleStoreResponse(resp store.Response) {
 	case store.FetchRecentFiles:
 		// Convert recent files to UI entries
 		o.handleRecentFilesResponse(resp.RecentFiles)
+	case store.FetchJournal:
+		o.journal.load(resp.Journal)
 	}
 }
 
@@ -940,6 +1209,15 @@ func (o *Orchestrator) applyFilterAndSort() {
 	o.state.Entries = snapshot.Entries
 }
 
+// syncJobs publishes the current background jobs to the UI
+func (o *Orchestrator) syncJobs() {
+	jobs := o.jobs.Snapshot()
+	o.stateMu.Lock()
+	o.state.Jobs = jobs
+	o.stateMu.Unlock()
+	o.window.Invalidate()
+}
+
 func (o *Orchestrator) setProgress(active bool, label string, current, total int64) {
 	o.progressMu.Lock()
 	o.state.Progress = ui.ProgressState{Active: active, Label: label, Current: current, Total: total}
@@ -1047,6 +1325,28 @@ func (o *Orchestrator) showTrash() {
 	o.window.Invalidate()
 }
 
+// findDuplicates scans dir for duplicate files on the fs goroutine; the
+// results replace the listing, grouped, in the duplicates view
+func (o *Orchestrator) findDuplicates(dir string) {
+	if fs.IsRemote(dir) {
+		o.ui.ShowError("Cannot find duplicates on remote storage")
+		return
+	}
+	debug.Log(debug.APP, "Finding duplicates in %s", dir)
+	o.resetUIState()
+	o.ui.SetDuplicatesView(true)
+
+	o.stateMu.Lock()
+	o.state.IsSearchResult = true
+	o.state.SearchQuery = ""
+	o.stateMu.Unlock()
+
+	o.setProgress(true, "Finding duplicates...", 0, 0)
+	gen := o.searchGen.Add(1)
+	o.fs.RequestChan <- fs.Request{Op: fs.FindDuplicates, Path: dir, Gen: gen}
+	o.window.Invalidate()
+}
+
 // emptyTrash permanently deletes all items in the trash
 func (o *Orchestrator) emptyTrash() {
 	o.setProgress(true, "Emptying "+trash.DisplayName()+"...", 0, 0)
@@ -1063,11 +1363,91 @@ func (o *Orchestrator) emptyTrash() {
 	}
 }
 
+// restoreFromTrash moves trashed items back to their original locations.
+// Occupied destinations go through the same conflict dialog as paste.
+func (o *Orchestrator) restoreFromTrash(trashPaths []string) {
+	items, err := trash.List()
+	if err != nil {
+		o.ui.ShowError("Error reading " + trash.DisplayName() + ": " + err.Error())
+		return
+	}
+	itemsByPath := make(map[string]trash.Item, len(items))
+	for _, item := range items {
+		itemsByPath[item.TrashPath] = item
+	}
+
+	session := &conflictSession{resolution: ui.ConflictAsk}
+
+	total := len(trashPaths)
+	var errorCount int
+	for i, trashPath := range trashPaths {
+		if session.abort {
+			break
+		}
+
+		item, ok := itemsByPath[trashPath]
+		if !ok || item.OriginalPath == "" {
+			log.Printf("Restore error for %s: original location unknown", trashPath)
+			errorCount++
+			continue
+		}
+		dst := item.OriginalPath
+
+		// Check for conflict at the original location
+		if dstInfo, err := os.Lstat(dst); err == nil {
+			srcInfo, err := os.Lstat(item.TrashPath)
+			if err != nil {
+				errorCount++
+				continue
+			}
+			resolution := o.resolveConflict(context.Background(), session, item.TrashPath, dst, srcInfo, dstInfo, total-i)
+
+			switch resolution {
+			case ui.ConflictReplaceAll:
+				// Replace - delete destination first
+				if err := deleteItem(dst); err != nil {
+					log.Printf("Restore error for %s: %v", dst, err)
+					errorCount++
+					continue
+				}
+			case ui.ConflictKeepBothAll:
+				// Keep both - restore under a new name
+				dst = keepBothPath(dst)
+			case ui.ConflictSkipAll:
+				continue
+			case ui.ConflictAsk:
+				// User clicked Stop or dialog was aborted
+				continue
+			}
+		}
+
+		label := fmt.Sprintf("Restoring (%d/%d) %s", i+1, total, filepath.Base(dst))
+		o.setProgress(true, label, 0, 0)
+		if err := trash.RestoreTo(item, dst); err != nil {
+			log.Printf("Restore error for %s: %v", item.TrashPath, err)
+			errorCount++
+		}
+	}
+	o.setProgress(false, "", 0, 0)
+
+	if errorCount > 0 {
+		o.ui.ShowError(fmt.Sprintf("Failed to restore %d of %d items", errorCount, total))
+	}
+
+	// Refresh trash view if currently showing
+	if o.ui.IsTrashView() {
+		o.showTrash()
+	}
+}
+
 // openFileLocation navigates to the directory containing the file
 func (o *Orchestrator) openFileLocation(path string) {
 	debug.Log(debug.APP, "Open file location: %s", path)
 	dir := filepath.Dir(path)
 	o.ui.SetRecentView(false)
+	o.ui.SetDuplicatesView(false)
+	o.ui.SetCompareView(false)
+	o.clearCompare()
 	o.navCtrl.Navigate(dir)
 
 	// Optionally select the file after navigation
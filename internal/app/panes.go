@@ -0,0 +1,158 @@
+package app
+
+import (
+	"path/filepath"
+
+	"github.com/justyntemme/razor/internal/debug"
+	"github.com/justyntemme/razor/internal/ui"
+)
+
+// paneState is the browser of the pane without focus in dual-pane mode.
+// The focused pane lives in the orchestrator's stateOwner and navCtrl;
+// switching focus swaps the two.
+type paneState struct {
+	owner *StateOwner // Entries, expansions and selection
+	tab   TabState    // Navigation history
+}
+
+// toggleDualPane shows or hides the second pane. A new second pane opens in
+// the focused pane's directory; hiding it keeps the focused pane.
+func (o *Orchestrator) toggleDualPane() {
+	o.stateMu.Lock()
+	show := !o.state.DualPane
+	o.state.DualPane = show
+	o.state.RightFocus = false
+	path := o.state.CurrentPath
+	o.stateMu.Unlock()
+
+	other := &o.otherPane
+	if show {
+		debug.Log(debug.APP, "Dual pane: opening second pane at %s", path)
+		other.tab = TabState{CurrentPath: path, History: []string{path}, SelectedIdx: -1}
+		other.owner.SetSort(o.sortColumn, o.sortAsc)
+		other.owner.ToggleDotfiles(o.showDotfiles)
+		other.owner.LoadDir(path)
+		o.syncOtherPane()
+	} else if otherPath := other.owner.GetCurrentPath(); o.watcher != nil && !o.isPathShown(otherPath) {
+		o.watcher.Unwatch(otherPath)
+	}
+	o.window.Invalidate()
+}
+
+// switchPane moves focus to the other pane, keeping each pane's primary
+// selection. The pane losing focus goes back to its directory if it was
+// showing search results or a special view.
+func (o *Orchestrator) switchPane() {
+	if !o.state.DualPane {
+		return
+	}
+	if o.state.IsSearchResult {
+		o.searchCtrl.CancelSearch(o.setProgress)
+	}
+	leavingView := o.state.IsSearchResult || o.ui.IsRecentView() || o.ui.IsTrashView() || o.ui.IsDuplicatesView() || o.ui.IsCompareView()
+
+	// Drop responses still on their way to the pane losing focus
+	o.searchGen.Add(1)
+
+	o.stateOwner.SetSelection(o.state.SelectedIndex)
+	o.stateOwner.SwapView(o.otherPane.owner)
+
+	other := &o.otherPane
+	o.navCtrl.History, other.tab.History = other.tab.History, o.navCtrl.History
+	o.navCtrl.HistoryIndex, other.tab.HistoryIndex = other.tab.HistoryIndex, o.navCtrl.HistoryIndex
+	if leavingView {
+		other.owner.RefreshCurrentDir()
+	}
+	other.tab.CurrentPath = other.owner.GetCurrentPath()
+
+	o.resetUIState()
+	snapshot := o.stateOwner.GetSnapshot()
+	o.ui.ClearExpanded()
+	for path := range snapshot.ExpandedDirs {
+		o.ui.SetExpanded(path, true)
+	}
+	o.ui.SwapPanes()
+
+	o.stateMu.Lock()
+	o.state.RightFocus = !o.state.RightFocus
+	o.state.Entries = snapshot.Entries
+	o.state.CurrentPath = snapshot.CurrentPath
+	o.state.SelectedIndex = snapshot.SelectedIndex
+	o.state.CanBack = o.navCtrl.HistoryIndex > 0
+	o.state.CanForward = o.navCtrl.HistoryIndex < len(o.navCtrl.History)-1
+	o.state.IsSearchResult = false
+	o.state.SearchQuery = ""
+	o.stateMu.Unlock()
+	o.syncOtherPane()
+
+	// A directory still loading when the pane lost focus is requested again
+	if i := o.navCtrl.HistoryIndex; i >= 0 && i < len(o.navCtrl.History) && o.navCtrl.History[i] != snapshot.CurrentPath {
+		o.navCtrl.RequestDir(o.navCtrl.History[i])
+	}
+
+	if o.activeTabIndex >= 0 && o.activeTabIndex < len(o.tabs) {
+		title := filepath.Base(snapshot.CurrentPath)
+		if title == "" || title == "/" || title == "." {
+			title = snapshot.CurrentPath
+		}
+		o.ui.UpdateTabTitle(o.activeTabIndex, title)
+		o.ui.UpdateTabPath(o.activeTabIndex, snapshot.CurrentPath)
+	}
+	debug.Log(debug.APP, "Dual pane: focus moved to %s", snapshot.CurrentPath)
+	o.window.Invalidate()
+}
+
+// transferToOtherPane queues a job copying (or moving) paths into the
+// directory of the pane without focus
+func (o *Orchestrator) transferToOtherPane(paths []string, move bool) {
+	if !o.state.DualPane || len(paths) == 0 {
+		return
+	}
+	dst := o.otherPane.owner.GetCurrentPath()
+	o.submitTransfer(paths, dst, move, o.verifyAlgorithm(false), func(error) {
+		// The watcher may not report the change (or not be running at all)
+		o.refreshOtherPane(dst)
+	})
+}
+
+// refreshOtherPane re-reads the directory of the pane without focus if it is dir
+func (o *Orchestrator) refreshOtherPane(dir string) {
+	o.stateMu.RLock()
+	dualPane := o.state.DualPane
+	o.stateMu.RUnlock()
+	if !dualPane || o.otherPane.owner.GetCurrentPath() != dir {
+		return
+	}
+	o.otherPane.owner.RefreshCurrentDir()
+	o.syncOtherPane()
+	o.window.Invalidate()
+}
+
+// syncOtherPane publishes the entries of the pane without focus to the UI
+func (o *Orchestrator) syncOtherPane() {
+	snapshot := o.otherPane.owner.GetSnapshot()
+	o.stateMu.Lock()
+	o.state.OtherPane = ui.PaneView{
+		Path:          snapshot.CurrentPath,
+		Entries:       snapshot.Entries,
+		SelectedIndex: snapshot.SelectedIndex,
+	}
+	o.stateMu.Unlock()
+
+	if o.watcher != nil && snapshot.CurrentPath != "" {
+		o.watcher.Watch(snapshot.CurrentPath)
+	}
+}
+
+// isPathShown reports whether the focused pane or any tab shows path
+func (o *Orchestrator) isPathShown(path string) bool {
+	if path == o.state.CurrentPath {
+		return true
+	}
+	for _, tab := range o.tabs {
+		if tab.CurrentPath == path {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/justyntemme/razor/internal/ui"
+)
+
+// batchReport collects the outcome of each item in a multi-file operation so failures
+// can be listed together once it finishes, instead of one toast per error
+type batchReport struct {
+	total     int
+	succeeded int
+	skipped   []ui.ReportItem
+	failed    []ui.ReportItem
+}
+
+func newBatchReport(total int) *batchReport {
+	return &batchReport{total: total}
+}
+
+func (b *batchReport) success() {
+	b.succeeded++
+}
+
+func (b *batchReport) skip(path, reason string) {
+	b.skipped = append(b.skipped, ui.ReportItem{Path: path, Reason: reason})
+}
+
+func (b *batchReport) fail(path string, err error) {
+	b.failed = append(b.failed, ui.ReportItem{Path: path, Reason: err.Error()})
+}
+
+// failedPaths returns the items to hand to a retry
+func (b *batchReport) failedPaths() []string {
+	paths := make([]string, len(b.failed))
+	for i, item := range b.failed {
+		paths[i] = item.Path
+	}
+	return paths
+}
+
+// err summarizes the failures for the job, or returns nil if there were none
+func (b *batchReport) err() error {
+	if len(b.failed) == 0 {
+		return nil
+	}
+	first := b.failed[0]
+	return fmt.Errorf("%d of %d items failed (%s: %s)", len(b.failed), b.total, first.Path, first.Reason)
+}
+
+// summary describes the counts, e.g. "12 succeeded, 1 skipped, 3 failed"
+func (b *batchReport) summary() string {
+	return fmt.Sprintf("%d succeeded, %d skipped, %d failed", b.succeeded, len(b.skipped), len(b.failed))
+}
+
+// showBatchReport opens the summary dialog if any item failed. retry, if not nil,
+// runs the operation again for just the failed paths.
+func (o *Orchestrator) showBatchReport(title, note string, b *batchReport, retry func(paths []string)) {
+	if len(b.failed) == 0 {
+		return
+	}
+
+	message := b.summary() + "."
+	if note != "" {
+		message += " " + note
+	}
+	report := ui.OperationReport{
+		Title:     title,
+		Message:   message,
+		Succeeded: b.succeeded,
+		Skipped:   b.skipped,
+		Failed:    b.failed,
+	}
+
+	var retryFn func()
+	if retry != nil {
+		failed := b.failedPaths()
+		retryFn = func() { retry(failed) }
+	}
+	o.showReport(report, retryFn)
+}
+
+// showReport opens the summary dialog for a finished operation, replacing any report
+// still open. retry backs the "Retry Failed" button (nil hides it).
+func (o *Orchestrator) showReport(report ui.OperationReport, retry func()) {
+	report.Active = true
+	report.CanRetry = retry != nil
+	o.stateMu.Lock()
+	o.state.Report = report
+	o.reportRetry = retry
+	o.stateMu.Unlock()
+	o.window.Invalidate()
+}
+
+// retryReport re-runs the failed items of the open report
+func (o *Orchestrator) retryReport() {
+	o.stateMu.Lock()
+	retry := o.reportRetry
+	o.reportRetry = nil
+	o.state.Report = ui.OperationReport{}
+	o.stateMu.Unlock()
+
+	if retry != nil {
+		retry()
+	}
+}
@@ -3,6 +3,7 @@ package app
 import (
 	"strings"
 
+	"github.com/justyntemme/razor/internal/config"
 	"github.com/justyntemme/razor/internal/debug"
 	"github.com/justyntemme/razor/internal/fs"
 	"github.com/justyntemme/razor/internal/search"
@@ -18,12 +19,27 @@ var searchDirectives = []string{
 	"filename:",
 	"recursive:",
 	"depth:",
+	"ignore:",
+	"index:",
+	"type:",
+	"perm:",
+	"owner:",
+	"group:",
+	"path:",
+	"created:",
+	"accessed:",
+	"empty:",
+	"case:",
+	"re:",
+	"regex:",
 }
 
 // Directives that don't require a value after the prefix
 var valueOptionalDirectives = map[string]bool{
 	"recursive:": true,
 	"depth:":     true,
+	"index:":     true,
+	"empty:":     true,
 }
 
 // DoSearch performs a search with the given query.
@@ -71,11 +87,39 @@ func (s *SearchController) DoSearch(query string, submitted bool, restoreDirecto
 		return
 	}
 
+	s.state.Mu.RLock()
+	currentPath := s.state.State.CurrentPath
+	s.state.Mu.RUnlock()
+
+	s.startSearch(currentPath, query, submitted, s.SelectedEngine, s.SelectedEngineCmd, setProgress)
+}
+
+// DoSavedSearch runs a saved search in its directory using its engine, or the
+// selected engine if that one isn't available. saved.Path should already be
+// the current directory so the results replace its listing.
+func (s *SearchController) DoSavedSearch(saved config.SavedSearch, setProgress func(bool, string, int64, int64)) {
+	debug.Log(debug.SEARCH, "DoSavedSearch: %q query=%q path=%q engine=%q", saved.Name, saved.Query, saved.Path, saved.Engine)
+
+	s.state.Mu.Lock()
+	s.state.State.SelectedIndex = -1
+	s.state.Mu.Unlock()
+
+	engine, engineCmd := s.SelectedEngine, s.SelectedEngineCmd
+	if saved.Engine != "" {
+		if e := search.GetEngineByName(saved.Engine); e == search.EngineBuiltin || search.IsEngineAvailable(e, s.Engines) {
+			engine, engineCmd = e, search.GetEngineCommand(e, s.Engines)
+		}
+	}
+	s.startSearch(saved.Path, saved.Query, false, engine, engineCmd, setProgress)
+}
+
+// startSearch sends a search of path to the fs goroutine.
+// submitted searches are saved to history.
+func (s *SearchController) startSearch(path, query string, submitted bool, engine search.SearchEngine, engineCmd string, setProgress func(bool, string, int64, int64)) {
 	// Track search state
 	s.state.Mu.Lock()
 	s.state.State.SearchQuery = query
 	s.state.State.IsSearchResult = true
-	currentPath := s.state.State.CurrentPath
 	s.state.Mu.Unlock()
 
 	// Check if this is a directive search (slow operation)
@@ -104,16 +148,18 @@ func (s *SearchController) DoSearch(query string, submitted bool, restoreDirecto
 	}
 
 	debug.Log(debug.SEARCH, "DoSearch: sending request path=%q gen=%d engine=%d depth=%d",
-		currentPath, gen, s.SelectedEngine, s.DefaultDepth)
+		path, gen, engine, s.DefaultDepth)
 
 	s.deps.FS.RequestChan <- fs.Request{
 		Op:           fs.SearchDir,
-		Path:         currentPath,
+		Path:         path,
 		Query:        query,
 		Gen:          gen,
-		SearchEngine: int(s.SelectedEngine),
-		EngineCmd:    s.SelectedEngineCmd,
+		SearchEngine: int(engine),
+		EngineCmd:    engineCmd,
 		DefaultDepth: s.DefaultDepth,
+
+		RespectIgnore: s.RespectIgnore,
 	}
 }
 
@@ -123,7 +169,7 @@ func (s *SearchController) ChangeEngine(engineID string) {
 	engineCmd := search.GetEngineCommand(engine, s.Engines)
 
 	// For non-builtin engines, verify it's actually available
-	if engine != search.EngineBuiltin && engineCmd == "" {
+	if engine != search.EngineBuiltin && !search.IsEngineAvailable(engine, s.Engines) {
 		debug.Log(debug.SEARCH, "Engine %s not available, staying with current", engineID)
 		return
 	}
@@ -9,6 +9,7 @@ import (
 
 	"gioui.org/app"
 	"github.com/justyntemme/razor/internal/debug"
+	"github.com/justyntemme/razor/internal/fs"
 	"github.com/justyntemme/razor/internal/ui"
 )
 
@@ -157,6 +158,16 @@ func (s *StateOwner) SetEntriesKeepExpanded(entries []ui.UIEntry) {
 	s.rebuildLocked()
 }
 
+// AppendEntries adds entries to the current ones (e.g. a batch of streamed
+// search results) and re-applies the filter and sort order
+func (s *StateOwner) AppendEntries(entries []ui.UIEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rawEntries = append(s.rawEntries, entries...)
+	s.rebuildLocked()
+}
+
 // ExpandDir expands a directory inline
 func (s *StateOwner) ExpandDir(path string) {
 	s.mu.Lock()
@@ -479,7 +490,7 @@ func (s *StateOwner) RefreshCurrentDir() {
 		// Also check if it's a child of an expanded dir by checking if the dir exists on disk
 		if !exists {
 			// Check if directory still exists on filesystem
-			if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			if info, err := fs.Stat(path); err != nil || !info.IsDir() {
 				delete(s.expandedDirs, path)
 				debug.Log(debug.APP, "RefreshCurrentDir: removed deleted expanded dir: %s", path)
 			}
@@ -495,6 +506,43 @@ func (s *StateOwner) RefreshCurrentDir() {
 	s.invalidate()
 }
 
+// LoadDir reads path from disk and shows it. Used by the pane without focus
+// in dual-pane mode, which doesn't receive filesystem responses.
+func (s *StateOwner) LoadDir(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.currentPath = path
+	s.rawEntries = s.readDirLocked(path)
+	s.expandedDirs = make(map[string]bool)
+	s.selectedIndex = -1
+	s.selectedIndices = make(map[int]bool)
+	s.rebuildLocked()
+	s.invalidate()
+}
+
+// SwapView exchanges the directory, entries, expansions and selection with
+// other when focus moves between the panes of dual-pane mode. Display settings
+// stay with each owner, so the incoming view is rebuilt with s's settings.
+func (s *StateOwner) SwapView(other *StateOwner) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	other.mu.Lock()
+	defer other.mu.Unlock()
+
+	s.entries, other.entries = other.entries, s.entries
+	s.rawEntries, other.rawEntries = other.rawEntries, s.rawEntries
+	s.currentPath, other.currentPath = other.currentPath, s.currentPath
+	s.expandedDirs, other.expandedDirs = other.expandedDirs, s.expandedDirs
+	s.selectedIndex, other.selectedIndex = other.selectedIndex, s.selectedIndex
+	s.selectedIndices, other.selectedIndices = other.selectedIndices, s.selectedIndices
+	s.canBack, other.canBack = other.canBack, s.canBack
+	s.canForward, other.canForward = other.canForward, s.canForward
+
+	s.rebuildLocked()
+	s.invalidate()
+}
+
 // RefreshExpandedDir refreshes children of an expanded directory
 func (s *StateOwner) RefreshExpandedDir(path string) {
 	s.mu.Lock()
@@ -599,6 +647,9 @@ func (s *StateOwner) applyExpansionsLocked() {
 }
 
 func (s *StateOwner) readDirLocked(path string) []ui.UIEntry {
+	if _, _, ok := fs.SplitArchivePath(path); ok || fs.IsRemote(path) {
+		return s.readVFSDirLocked(path)
+	}
 	dirEntries, err := os.ReadDir(path)
 	if err != nil {
 		debug.Log(debug.APP, "StateOwner.readDirLocked error: %v", err)
@@ -622,6 +673,20 @@ func (s *StateOwner) readDirLocked(path string) []ui.UIEntry {
 	return entries
 }
 
+// readVFSDirLocked lists a folder inside an archive or on remote storage
+func (s *StateOwner) readVFSDirLocked(path string) []ui.UIEntry {
+	vfsEntries, err := fs.ReadDir(path)
+	if err != nil {
+		debug.Log(debug.APP, "StateOwner.readVFSDirLocked error: %v", err)
+		return nil
+	}
+	entries := make([]ui.UIEntry, len(vfsEntries))
+	for i, e := range vfsEntries {
+		entries[i] = ui.UIEntry{Name: e.Name, Path: e.Path, IsDir: e.IsDir, Size: e.Size, ModTime: e.ModTime}
+	}
+	return entries
+}
+
 func (s *StateOwner) filterLocked(entries []ui.UIEntry) []ui.UIEntry {
 	if s.showDotfiles {
 		result := make([]ui.UIEntry, len(entries))
@@ -640,6 +705,11 @@ func (s *StateOwner) filterLocked(entries []ui.UIEntry) []ui.UIEntry {
 
 func (s *StateOwner) sortLocked(entries []ui.UIEntry) {
 	sort.Slice(entries, func(i, j int) bool {
+		// Duplicate groups stay together in order of wasted space
+		if entries[i].DupGroup != entries[j].DupGroup {
+			return entries[i].DupGroup < entries[j].DupGroup
+		}
+
 		// Directories first
 		if entries[i].IsDir != entries[j].IsDir {
 			return entries[i].IsDir
@@ -2,7 +2,6 @@ package app
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 
 	"github.com/justyntemme/razor/internal/debug"
@@ -70,9 +69,8 @@ func (o *Orchestrator) createTabAtPath(path string) {
 
 // openPathInNewTab creates a new tab and navigates to the specified path
 func (o *Orchestrator) openPathInNewTab(path string) {
-	// Validate path is a directory
-	info, err := os.Stat(path)
-	if err != nil || !info.IsDir() {
+	// Validate path is a directory (or an archive)
+	if exists, isDir := o.navCtrl.ValidatePath(path); !exists || !isDir {
 		debug.Log(debug.APP, "openPathInNewTab: invalid directory path %s", path)
 		return
 	}
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// Checksum algorithms for copy verification (config behavior.verifyAlgorithm)
+const (
+	ChecksumXXHash = "xxhash"
+	ChecksumSHA256 = "sha256"
+)
+
+// errChecksumMismatch marks a copy whose contents differ from its source
+var errChecksumMismatch = errors.New("checksum mismatch")
+
+// verifyAlgorithm returns the checksum algorithm for a copy, or "" when copies aren't
+// verified. force turns verification on for a single paste even if the config doesn't.
+func (o *Orchestrator) verifyAlgorithm(force bool) string {
+	behavior := o.config.GetBehaviorConfig()
+	if !behavior.VerifyCopies && !force {
+		return ""
+	}
+	if behavior.VerifyAlgorithm == ChecksumSHA256 {
+		return ChecksumSHA256
+	}
+	return ChecksumXXHash
+}
+
+func newChecksum(algorithm string) hash.Hash {
+	if algorithm == ChecksumSHA256 {
+		return sha256.New()
+	}
+	return xxhash.New()
+}
+
+// verifyChecksum hashes src and dst with the job's algorithm and records a mismatch on j
+func verifyChecksum(j *Job, src, dst string) error {
+	srcSum, err := checksumFile(j, src)
+	if err != nil {
+		return err
+	}
+	dstSum, err := checksumFile(j, dst)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(srcSum, dstSum) {
+		j.addMismatch(dst)
+		return fmt.Errorf("%s: %w", dst, errChecksumMismatch)
+	}
+	j.verified.Add(1)
+	return nil
+}
+
+// checksumFile hashes the contents of path, honouring pause/cancel of j
+func checksumFile(j *Job, path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := newChecksum(j.verify)
+	if _, err := io.Copy(h, &checkpointReader{r: f, job: j}); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// checkpointReader blocks while its job is paused and fails once it is cancelled
+type checkpointReader struct {
+	r   io.Reader
+	job *Job
+}
+
+func (cr *checkpointReader) Read(p []byte) (int, error) {
+	if err := cr.job.checkpoint(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/justyntemme/razor/internal/debug"
+	"github.com/justyntemme/razor/internal/fs"
 )
 
 // DirectoryWatcher watches directories for changes and notifies when refreshes are needed
@@ -113,8 +114,12 @@ func (dw *DirectoryWatcher) run() {
 	}
 }
 
-// Watch adds a directory to the watch list
+// Watch adds a directory to the watch list. Remote folders can't be
+// watched and are left out.
 func (dw *DirectoryWatcher) Watch(path string) error {
+	if fs.IsRemote(path) {
+		return nil
+	}
 	dw.mu.Lock()
 	defer dw.mu.Unlock()
 
@@ -27,6 +27,8 @@ type Config struct {
 	Terminal  TerminalConfig  `json:"terminal"`
 	Hotkeys   HotkeysConfig   `json:"hotkeys"`
 	Favorites []FavoriteEntry `json:"favorites"`
+
+	SavedSearches []SavedSearch `json:"savedSearches"`
 }
 
 // TerminalConfig holds terminal application settings
@@ -55,6 +57,8 @@ type HotkeysConfig struct {
 	NewFile         string `json:"newFile"`
 	NewFolder       string `json:"newFolder"`
 	SelectAll       string `json:"selectAll"`
+	Undo            string `json:"undo"`
+	Redo            string `json:"redo"`
 
 	// Navigation
 	Back          string `json:"back"`
@@ -84,6 +88,12 @@ type HotkeysConfig struct {
 	Tab4          string `json:"tab4"`
 	Tab5          string `json:"tab5"`
 	Tab6          string `json:"tab6"`
+
+	// Dual pane (the pane keys only apply while both panes are shown)
+	DualPane   string `json:"dualPane"`   // Toggle the two-pane layout
+	SwitchPane string `json:"switchPane"` // Move focus to the other pane
+	CopyToPane string `json:"copyToPane"` // Copy the selection to the other pane
+	MoveToPane string `json:"moveToPane"` // Move the selection to the other pane
 }
 
 // UIConfig holds UI-related settings
@@ -132,17 +142,26 @@ type StatusBarConfig struct {
 
 // SearchConfig holds search-related settings
 type SearchConfig struct {
-	Engine              string `json:"engine"`              // "builtin" | "ripgrep" | "ugrep"
+	Engine              string `json:"engine"`              // "builtin" | "ripgrep" | "ugrep" | "fts"
 	DefaultDepth        int    `json:"defaultDepth"`
 	RememberLastQuery   bool   `json:"rememberLastQuery"`
+	RespectIgnoreFiles  bool   `json:"respectIgnoreFiles"` // Skip paths excluded by .gitignore/.ignore
+
+	// Filename index for index: searches (off while IndexRoots is empty)
+	IndexRoots         []string `json:"indexRoots"`         // Directories to index, ~ for home
+	IndexRescanMinutes int      `json:"indexRescanMinutes"` // Full rescan interval, 0 for 60
+	ContentIndexRoots  []string `json:"contentIndexRoots"`  // Directories for the "fts" content engine
 }
 
 // BehaviorConfig holds behavior settings
 type BehaviorConfig struct {
-	ConfirmDelete      bool `json:"confirmDelete"`
-	DoubleClickToOpen  bool `json:"doubleClickToOpen"`
-	RestoreLastPath    bool `json:"restoreLastPath"`
-	SingleClickToSelect bool `json:"singleClickToSelect"`
+	ConfirmDelete       bool   `json:"confirmDelete"`
+	DoubleClickToOpen   bool   `json:"doubleClickToOpen"`
+	RestoreLastPath     bool   `json:"restoreLastPath"`
+	SingleClickToSelect bool   `json:"singleClickToSelect"`
+	FollowSymlinks      bool   `json:"followSymlinks"`  // Copy what symlinks point to instead of the links themselves
+	VerifyCopies        bool   `json:"verifyCopies"`    // Compare checksums of every copied file
+	VerifyAlgorithm     string `json:"verifyAlgorithm"` // "xxhash" | "sha256"
 }
 
 // TabsConfig holds tab-related settings
@@ -189,6 +208,14 @@ type FavoriteEntry struct {
 	Items    []FavoriteEntry `json:"items,omitempty"`    // For groups
 }
 
+// SavedSearch is a named query shown in the sidebar like a favorite
+type SavedSearch struct {
+	Name   string `json:"name"`
+	Query  string `json:"query"`
+	Path   string `json:"path"`             // Directory the query runs in, ~ for home
+	Engine string `json:"engine,omitempty"` // Engine name; empty for the configured engine
+}
+
 // Manager handles loading, saving, and accessing configuration
 type Manager struct {
 	mu       sync.RWMutex
@@ -239,15 +266,19 @@ func DefaultConfig() *Config {
 			},
 		},
 		Search: SearchConfig{
-			Engine:            "builtin",
-			DefaultDepth:      2,
-			RememberLastQuery: false,
+			Engine:             "builtin",
+			DefaultDepth:       2,
+			RememberLastQuery:  false,
+			RespectIgnoreFiles: true,
 		},
 		Behavior: BehaviorConfig{
 			ConfirmDelete:       true,
 			DoubleClickToOpen:   true,
 			RestoreLastPath:     true,
 			SingleClickToSelect: true,
+			FollowSymlinks:      false,
+			VerifyCopies:        false,
+			VerifyAlgorithm:     "xxhash",
 		},
 		Tabs: TabsConfig{
 			Enabled:            false,
@@ -286,6 +317,7 @@ func DefaultConfig() *Config {
 			{Name: "Documents", Path: filepath.Join(home, "Documents"), Icon: "folder"},
 			{Name: "Downloads", Path: filepath.Join(home, "Downloads"), Icon: "folder"},
 		},
+		SavedSearches: []SavedSearch{},
 	}
 }
 
@@ -465,6 +497,60 @@ func (m *Manager) GetFavorites() []FavoriteEntry {
 	return m.config.Favorites
 }
 
+// AddSavedSearch saves a search, replacing any saved search with the same name
+func (m *Manager) AddSavedSearch(saved SavedSearch) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	replaced := false
+	for i, s := range m.config.SavedSearches {
+		if s.Name == saved.Name {
+			m.config.SavedSearches[i] = saved
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		m.config.SavedSearches = append(m.config.SavedSearches, saved)
+	}
+	if err := m.saveUnlocked(); err != nil {
+		log.Printf("Error saving config after saving search: %v", err)
+	}
+}
+
+// RemoveSavedSearch removes a saved search by name
+func (m *Manager) RemoveSavedSearch(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, s := range m.config.SavedSearches {
+		if s.Name == name {
+			m.config.SavedSearches = append(m.config.SavedSearches[:i], m.config.SavedSearches[i+1:]...)
+			break
+		}
+	}
+	if err := m.saveUnlocked(); err != nil {
+		log.Printf("Error saving config after removing saved search: %v", err)
+	}
+}
+
+// GetSavedSearches returns the saved searches in sidebar order
+func (m *Manager) GetSavedSearches() []SavedSearch {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.config.SavedSearches
+}
+
+// GetSavedSearch returns the saved search called name
+func (m *Manager) GetSavedSearch(name string) (SavedSearch, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, s := range m.config.SavedSearches {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return SavedSearch{}, false
+}
+
 // IsDarkMode returns true if dark mode is enabled
 func (m *Manager) IsDarkMode() bool {
 	m.mu.RLock()
@@ -486,6 +572,13 @@ func (m *Manager) GetPreviewConfig() PreviewConfig {
 	return m.config.Preview
 }
 
+// GetBehaviorConfig returns the behavior configuration
+func (m *Manager) GetBehaviorConfig() BehaviorConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.config.Behavior
+}
+
 // GetTabsConfig returns the tabs configuration
 func (m *Manager) GetTabsConfig() TabsConfig {
 	m.mu.RLock()
@@ -494,10 +587,31 @@ func (m *Manager) GetTabsConfig() TabsConfig {
 }
 
 // GetHotkeys returns the hotkeys configuration
+// Bindings missing from older config files fall back to their defaults
 func (m *Manager) GetHotkeys() HotkeysConfig {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	return m.config.Hotkeys
+	hotkeys := m.config.Hotkeys
+	defaults := DefaultHotkeys()
+	if hotkeys.Undo == "" {
+		hotkeys.Undo = defaults.Undo
+	}
+	if hotkeys.Redo == "" {
+		hotkeys.Redo = defaults.Redo
+	}
+	if hotkeys.DualPane == "" {
+		hotkeys.DualPane = defaults.DualPane
+	}
+	if hotkeys.SwitchPane == "" {
+		hotkeys.SwitchPane = defaults.SwitchPane
+	}
+	if hotkeys.CopyToPane == "" {
+		hotkeys.CopyToPane = defaults.CopyToPane
+	}
+	if hotkeys.MoveToPane == "" {
+		hotkeys.MoveToPane = defaults.MoveToPane
+	}
+	return hotkeys
 }
 
 // GetTerminalApp returns the configured terminal application
@@ -226,6 +226,8 @@ type HotkeyMatcher struct {
 	NewFile         Hotkey
 	NewFolder       Hotkey
 	SelectAll       Hotkey
+	Undo            Hotkey
+	Redo            Hotkey
 
 	// Navigation
 	Back    Hotkey
@@ -255,6 +257,12 @@ type HotkeyMatcher struct {
 	Tab4 Hotkey
 	Tab5 Hotkey
 	Tab6 Hotkey
+
+	// Dual pane
+	DualPane   Hotkey
+	SwitchPane Hotkey
+	CopyToPane Hotkey
+	MoveToPane Hotkey
 }
 
 // NewHotkeyMatcher creates a matcher from config
@@ -270,6 +278,8 @@ func NewHotkeyMatcher(cfg HotkeysConfig) *HotkeyMatcher {
 		NewFile:         ParseHotkey(cfg.NewFile),
 		NewFolder:       ParseHotkey(cfg.NewFolder),
 		SelectAll:       ParseHotkey(cfg.SelectAll),
+		Undo:            ParseHotkey(cfg.Undo),
+		Redo:            ParseHotkey(cfg.Redo),
 
 		// Navigation
 		Back:    ParseHotkey(cfg.Back),
@@ -299,5 +309,11 @@ func NewHotkeyMatcher(cfg HotkeysConfig) *HotkeyMatcher {
 		Tab4: ParseHotkey(cfg.Tab4),
 		Tab5: ParseHotkey(cfg.Tab5),
 		Tab6: ParseHotkey(cfg.Tab6),
+
+		// Dual pane
+		DualPane:   ParseHotkey(cfg.DualPane),
+		SwitchPane: ParseHotkey(cfg.SwitchPane),
+		CopyToPane: ParseHotkey(cfg.CopyToPane),
+		MoveToPane: ParseHotkey(cfg.MoveToPane),
 	}
 }
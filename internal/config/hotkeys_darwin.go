@@ -17,6 +17,8 @@ func DefaultHotkeys() HotkeysConfig {
 		NewFile:         "Cmd+N",
 		NewFolder:       "Cmd+Shift+N",
 		SelectAll:       "Cmd+A",
+		Undo:            "Cmd+Z",
+		Redo:            "Cmd+Shift+Z",
 
 		// Navigation - uses Cmd on macOS
 		Back:    "Cmd+Left",
@@ -46,5 +48,11 @@ func DefaultHotkeys() HotkeysConfig {
 		Tab4: "Cmd+4",
 		Tab5: "Cmd+5",
 		Tab6: "Cmd+6",
+
+		// Dual pane - commander conventions; Tab only switches panes while both are shown
+		DualPane:   "Cmd+Shift+D",
+		SwitchPane: "Tab",
+		CopyToPane: "F5",
+		MoveToPane: "F6",
 	}
 }
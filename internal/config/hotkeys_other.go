@@ -16,6 +16,8 @@ func DefaultHotkeys() HotkeysConfig {
 		NewFile:         "Ctrl+N",
 		NewFolder:       "Ctrl+Shift+N",
 		SelectAll:       "Ctrl+A",
+		Undo:            "Ctrl+Z",
+		Redo:            "Ctrl+Shift+Z",
 
 		// Navigation - uses Alt on Windows/Linux
 		Back:    "Alt+Left",
@@ -45,5 +47,11 @@ func DefaultHotkeys() HotkeysConfig {
 		Tab4: "Ctrl+Shift+4",
 		Tab5: "Ctrl+Shift+5",
 		Tab6: "Ctrl+Shift+6",
+
+		// Dual pane - commander conventions; F5 and Tab only act as these while both panes are shown
+		DualPane:   "Ctrl+Shift+D",
+		SwitchPane: "Tab",
+		CopyToPane: "F5",
+		MoveToPane: "F6",
 	}
 }
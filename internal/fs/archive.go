@@ -0,0 +1,802 @@
+package fs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/justyntemme/razor/internal/debug"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Archives are browsed as read-only folders: a path such as
+// /home/me/src.tar.gz/src/main.go names the member src/main.go of the archive
+// /home/me/src.tar.gz. Listing a folder only needs the archive's index, which
+// is read once and cached; reading a member of a tar archive streams the
+// archive up to it.
+
+// archiveFormat is the kind of archive, known from the file name
+type archiveFormat int
+
+const (
+	formatNone archiveFormat = iota
+	formatZip
+	formatTar
+	formatTarGz
+	formatTarZst
+)
+
+// archiveExts maps the extensions of archives to their formats, longest first
+var archiveExts = []struct {
+	ext    string
+	format archiveFormat
+}{
+	{".tar.zst", formatTarZst},
+	{".tar.gz", formatTarGz},
+	{".tzst", formatTarZst},
+	{".tgz", formatTarGz},
+	{".zip", formatZip},
+	{".tar", formatTar},
+}
+
+func archiveFormatOf(name string) archiveFormat {
+	if i := archiveExtIndex(name); i >= 0 {
+		return archiveExts[i].format
+	}
+	return formatNone
+}
+
+func archiveExtIndex(name string) int {
+	lower := strings.ToLower(name)
+	for i, e := range archiveExts {
+		if strings.HasSuffix(lower, e.ext) {
+			return i
+		}
+	}
+	return -1
+}
+
+// TrimArchiveExt returns name without its archive extension, e.g. "site" for
+// "site.tar.gz"
+func TrimArchiveExt(name string) string {
+	if i := archiveExtIndex(name); i >= 0 {
+		return name[:len(name)-len(archiveExts[i].ext)]
+	}
+	return name
+}
+
+// IsArchive reports whether name is that of an archive that can be browsed
+// (zip, tar, tar.gz or tar.zst)
+func IsArchive(name string) bool {
+	return archiveFormatOf(name) != formatNone
+}
+
+// SplitArchivePath splits a path leading into an archive into the archive
+// file and the slash-separated path of a member ("." for the archive itself).
+// ok is false for paths outside archives. Archives inside archives are
+// members like any other; they can't be opened in turn.
+func SplitArchivePath(p string) (archive, member string, ok bool) {
+	if IsRemote(p) {
+		return "", "", false // Remote archives are plain files
+	}
+	p = filepath.Clean(p)
+	for dir := p; ; {
+		if IsArchive(filepath.Base(dir)) {
+			if info, err := os.Stat(dir); err == nil {
+				if !info.Mode().IsRegular() {
+					return "", "", false // A real folder, named like an archive
+				}
+				member = "."
+				if dir != p {
+					rel, err := filepath.Rel(dir, p)
+					if err != nil {
+						return "", "", false
+					}
+					member = filepath.ToSlash(rel)
+				}
+				return dir, member, true
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", false
+		}
+		dir = parent
+	}
+}
+
+// InArchive reports whether p is inside an archive rather than on disk
+func InArchive(p string) bool {
+	_, member, ok := SplitArchivePath(p)
+	return ok && member != "."
+}
+
+// ErrReadOnly is returned for attempts to change the contents of an archive
+var ErrReadOnly = errors.New("archives are read-only")
+
+// archiveMember is a file or folder of an archive. It serves as both the
+// fs.FileInfo and the fs.DirEntry of the member.
+type archiveMember struct {
+	name    string // Slash-separated path in the archive; "." for the root
+	size    int64
+	modTime time.Time
+	mode    fs.FileMode
+}
+
+func (m *archiveMember) Name() string               { return path.Base(m.name) }
+func (m *archiveMember) Size() int64                { return m.size }
+func (m *archiveMember) Mode() fs.FileMode          { return m.mode }
+func (m *archiveMember) ModTime() time.Time         { return m.modTime }
+func (m *archiveMember) IsDir() bool                { return m.mode.IsDir() }
+func (m *archiveMember) Sys() any                   { return nil }
+func (m *archiveMember) Type() fs.FileMode          { return m.mode.Type() }
+func (m *archiveMember) Info() (fs.FileInfo, error) { return m, nil }
+
+// archiveIndex lists the members of an archive as of its size and mtime
+type archiveIndex struct {
+	format   archiveFormat
+	size     int64
+	modTime  time.Time
+	members  map[string]*archiveMember   // By path, including "."
+	children map[string][]*archiveMember // Folder path to its members, sorted by name
+	unsafe   []string                    // Names left out for leading outside the archive
+}
+
+func newArchiveIndex(format archiveFormat, info os.FileInfo) *archiveIndex {
+	root := &archiveMember{name: ".", modTime: info.ModTime(), mode: fs.ModeDir | 0o755}
+	return &archiveIndex{
+		format:   format,
+		size:     info.Size(),
+		modTime:  info.ModTime(),
+		members:  map[string]*archiveMember{".": root},
+		children: make(map[string][]*archiveMember),
+	}
+}
+
+// add records a member, creating the folders above it that the archive
+// doesn't list itself. Names that would leave the archive are dropped.
+func (ix *archiveIndex) add(name string, size int64, modTime time.Time, mode fs.FileMode) {
+	raw := name
+	name, ok := memberName(name)
+	if !ok {
+		if name != "." {
+			ix.unsafe = append(ix.unsafe, raw)
+		}
+		return
+	}
+	if m, ok := ix.members[name]; ok {
+		// Listed twice (tar appends) or a folder created for a member below it
+		m.size, m.modTime, m.mode = size, modTime, mode
+		return
+	}
+	m := &archiveMember{name: name, size: size, modTime: modTime, mode: mode}
+	ix.members[name] = m
+	parent := path.Dir(name)
+	if _, ok := ix.members[parent]; !ok {
+		ix.add(parent, 0, modTime, fs.ModeDir|0o755)
+	}
+	ix.children[parent] = append(ix.children[parent], m)
+}
+
+// memberName cleans the name an archive records for a member. ok is false
+// for the root (".") and for names that would leave the archive.
+func memberName(raw string) (name string, ok bool) {
+	name = path.Clean(strings.TrimPrefix(filepath.ToSlash(raw), "/"))
+	return name, name != "." && name != ".." && !strings.HasPrefix(name, "../")
+}
+
+func (ix *archiveIndex) sortChildren() {
+	for _, list := range ix.children {
+		sort.Slice(list, func(i, j int) bool { return list[i].name < list[j].name })
+	}
+}
+
+// archiveCacheSize is how many archive indexes are kept
+const archiveCacheSize = 8
+
+var archiveCache = struct {
+	sync.Mutex
+	indexes map[string]*archiveIndex
+	order   []string // Least recently used first
+}{indexes: make(map[string]*archiveIndex)}
+
+// loadArchiveIndex returns the index of the archive at p, reading it again
+// if the file changed since it was cached
+func loadArchiveIndex(p string) (*archiveIndex, error) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, err
+	}
+	format := archiveFormatOf(p)
+	if format == formatNone || !info.Mode().IsRegular() {
+		return nil, &fs.PathError{Op: "open", Path: p, Err: errors.New("not an archive")}
+	}
+
+	archiveCache.Lock()
+	ix, ok := archiveCache.indexes[p]
+	if ok && ix.size == info.Size() && ix.modTime.Equal(info.ModTime()) {
+		archiveCache.order = append(removeString(archiveCache.order, p), p)
+		archiveCache.Unlock()
+		return ix, nil
+	}
+	archiveCache.Unlock()
+
+	start := time.Now()
+	if format == formatZip {
+		ix, err = readZipIndex(p, info)
+	} else {
+		ix, err = readTarIndex(p, format, info)
+	}
+	if err != nil {
+		return nil, err
+	}
+	debug.Log(debug.FS, "loadArchiveIndex: %s: %d members in %v", p, len(ix.members)-1, time.Since(start))
+
+	archiveCache.Lock()
+	defer archiveCache.Unlock()
+	archiveCache.indexes[p] = ix
+	archiveCache.order = append(removeString(archiveCache.order, p), p)
+	for len(archiveCache.order) > archiveCacheSize {
+		delete(archiveCache.indexes, archiveCache.order[0])
+		archiveCache.order = archiveCache.order[1:]
+	}
+	return ix, nil
+}
+
+func removeString(list []string, s string) []string {
+	for i, v := range list {
+		if v == s {
+			return append(list[:i:i], list[i+1:]...)
+		}
+	}
+	return list
+}
+
+func readZipIndex(p string, info os.FileInfo) (*archiveIndex, error) {
+	r, err := zip.OpenReader(p)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	ix := newArchiveIndex(formatZip, info)
+	for _, f := range r.File {
+		fi := f.FileInfo()
+		mode := fi.Mode()
+		if !mode.IsDir() && !mode.IsRegular() {
+			continue // Symlinks and the like
+		}
+		ix.add(f.Name, fi.Size(), f.Modified, mode)
+	}
+	ix.sortChildren()
+	return ix, nil
+}
+
+func readTarIndex(p string, format archiveFormat, info os.FileInfo) (*archiveIndex, error) {
+	tr, closeTar, err := openTar(p, format)
+	if err != nil {
+		return nil, err
+	}
+	defer closeTar()
+
+	ix := newArchiveIndex(format, info)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		mode := hdr.FileInfo().Mode()
+		if !mode.IsDir() && !mode.IsRegular() {
+			continue // Links and special files
+		}
+		ix.add(hdr.Name, hdr.Size, hdr.ModTime, mode)
+	}
+	ix.sortChildren()
+	return ix, nil
+}
+
+// openTar opens a tar archive, decompressing it as its format requires
+func openTar(p string, format archiveFormat) (*tar.Reader, func(), error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch format {
+	case formatTarGz:
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+		return tar.NewReader(gz), func() { gz.Close(); f.Close() }, nil
+	case formatTarZst:
+		zr, err := zstd.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+		return tar.NewReader(zr), func() { zr.Close(); f.Close() }, nil
+	}
+	return tar.NewReader(f), func() { f.Close() }, nil
+}
+
+// ArchiveFS is a read-only VFS of the members of an archive
+type ArchiveFS struct {
+	path string
+	ix   *archiveIndex
+}
+
+// OpenArchive reads the index of the archive at p
+func OpenArchive(p string) (*ArchiveFS, error) {
+	ix, err := loadArchiveIndex(p)
+	if err != nil {
+		return nil, err
+	}
+	return &ArchiveFS{path: p, ix: ix}, nil
+}
+
+func (a *ArchiveFS) member(op, name string) (*archiveMember, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	m, ok := a.ix.members[name]
+	if !ok {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return m, nil
+}
+
+// Stat describes a member
+func (a *ArchiveFS) Stat(name string) (fs.FileInfo, error) {
+	return a.member("stat", name)
+}
+
+// ReadDir lists the members of a folder of the archive, sorted by name
+func (a *ArchiveFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	m, err := a.member("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !m.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	children := a.ix.children[name]
+	entries := make([]fs.DirEntry, len(children))
+	for i, c := range children {
+		entries[i] = c
+	}
+	return entries, nil
+}
+
+// Open opens a member for reading
+func (a *ArchiveFS) Open(name string) (fs.File, error) {
+	m, err := a.member("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if m.IsDir() {
+		entries, _ := a.ReadDir(name)
+		return &archiveDir{member: m, entries: entries}, nil
+	}
+
+	var rc io.ReadCloser
+	if a.ix.format == formatZip {
+		rc, err = openZipMember(a.path, name)
+	} else {
+		rc, err = openTarMember(a.path, a.ix.format, name)
+	}
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return &archiveFile{member: m, ReadCloser: rc}, nil
+}
+
+// Create, Rename, Remove and MkdirAll fail with ErrReadOnly
+
+func (a *ArchiveFS) Create(name string) (io.WriteCloser, error) {
+	return nil, &fs.PathError{Op: "create", Path: name, Err: ErrReadOnly}
+}
+
+func (a *ArchiveFS) Rename(oldname, newname string) error {
+	return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: ErrReadOnly}
+}
+
+func (a *ArchiveFS) Remove(name string) error {
+	return &fs.PathError{Op: "remove", Path: name, Err: ErrReadOnly}
+}
+
+func (a *ArchiveFS) MkdirAll(name string) error {
+	return &fs.PathError{Op: "mkdir", Path: name, Err: ErrReadOnly}
+}
+
+// archiveFile is an open member; reads decompress it
+type archiveFile struct {
+	member *archiveMember
+	io.ReadCloser
+}
+
+func (f *archiveFile) Stat() (fs.FileInfo, error) { return f.member, nil }
+
+// archiveDir is an open folder of an archive
+type archiveDir struct {
+	member  *archiveMember
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *archiveDir) Stat() (fs.FileInfo, error) { return d.member, nil }
+func (d *archiveDir) Close() error               { return nil }
+
+func (d *archiveDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.member.name, Err: errors.New("is a directory")}
+}
+
+func (d *archiveDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(rest))
+	d.offset += n
+	return rest[:n], nil
+}
+
+// multiCloser closes a reader along with the readers it is stacked on
+type multiCloser struct {
+	io.Reader
+	close func() error
+}
+
+func (m *multiCloser) Close() error { return m.close() }
+
+func openZipMember(archive, name string) (io.ReadCloser, error) {
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range r.File {
+		if n, _ := memberName(f.Name); n != name || f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			r.Close()
+			return nil, err
+		}
+		return &multiCloser{Reader: rc, close: func() error {
+			rc.Close()
+			return r.Close()
+		}}, nil
+	}
+	r.Close()
+	return nil, fs.ErrNotExist
+}
+
+// openTarMember streams a tar archive up to the last copy of member name
+// (tar archives may be appended to) and returns a reader of its contents
+func openTarMember(archive string, format archiveFormat, name string) (io.ReadCloser, error) {
+	// Count the copies first, since the stream can't be rewound
+	tr, closeTar, err := openTar(archive, format)
+	if err != nil {
+		return nil, err
+	}
+	copies := 0
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			closeTar()
+			return nil, err
+		}
+		if n, _ := memberName(hdr.Name); hdr.Typeflag != tar.TypeDir && n == name {
+			copies++
+		}
+	}
+	closeTar()
+	if copies == 0 {
+		return nil, fs.ErrNotExist
+	}
+
+	if tr, closeTar, err = openTar(archive, format); err != nil {
+		return nil, err
+	}
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			closeTar()
+			if err == io.EOF {
+				err = fs.ErrNotExist
+			}
+			return nil, err
+		}
+		if n, _ := memberName(hdr.Name); hdr.Typeflag != tar.TypeDir && n == name {
+			if copies--; copies == 0 {
+				return &multiCloser{Reader: tr, close: func() error { closeTar(); return nil }}, nil
+			}
+		}
+	}
+}
+
+// ErrUnsafePath is returned for archive members whose path would leave the
+// folder they are extracted to
+var ErrUnsafePath = errors.New("member path leaves the destination folder")
+
+// Extract writes the member name (a file, or a folder and everything below
+// it) to dst on disk in a single pass over the archive, keeping permission
+// bits and modification times. progress is called with the bytes written, as
+// in CopyContents; returning an error stops the extraction. fileDone is
+// called after each file.
+func (a *ArchiveFS) Extract(name, dst string, progress func(n int64) error, fileDone func()) error {
+	return a.ExtractMembers(map[string]string{name: dst}, progress, fileDone)
+}
+
+// ExtractMembers is Extract for several members at once, still in a single
+// pass; targets maps each member to its destination. Nothing is written
+// outside the destinations: members named to leave the archive are never
+// listed (see Unsafe), and a file is refused if symlinks already on disk
+// would redirect it.
+func (a *ArchiveFS) ExtractMembers(targets map[string]string, progress func(n int64) error, fileDone func()) error {
+	type root struct {
+		m        *archiveMember
+		dst      string
+		resolved string // Folder the extracted files must resolve into
+	}
+	roots := make(map[string]*root, len(targets))
+	for name, dst := range targets {
+		m, err := a.member("extract", name)
+		if err != nil {
+			return err
+		}
+		r := &root{m: m, dst: filepath.Clean(dst)}
+		base := r.dst
+		if !m.IsDir() {
+			base = filepath.Dir(base)
+		} else if err := os.MkdirAll(base, 0o755); err != nil {
+			return err
+		}
+		if r.resolved, err = filepath.EvalSymlinks(base); err != nil {
+			return err
+		}
+		roots[name] = r
+	}
+
+	// target maps a member to its path on disk; r is nil for members outside the targets
+	target := func(member string) (p string, r *root, err error) {
+		for dir := member; ; dir = path.Dir(dir) {
+			if r = roots[dir]; r != nil {
+				break
+			}
+			if dir == "." {
+				return "", nil, nil
+			}
+		}
+		if member == r.m.name {
+			return r.dst, r, nil
+		}
+		rel := member
+		if r.m.name != "." {
+			rel = member[len(r.m.name)+1:]
+		}
+		p = filepath.Join(r.dst, filepath.FromSlash(rel))
+		if !withinDir(r.dst, p) {
+			return "", nil, &fs.PathError{Op: "extract", Path: member, Err: ErrUnsafePath}
+		}
+		return p, r, nil
+	}
+
+	// Folders first, writable until their contents are in place
+	var dirs []*archiveMember
+	for _, m := range a.ix.members {
+		if !m.IsDir() {
+			continue
+		}
+		p, r, err := target(m.name)
+		if err != nil {
+			return err
+		}
+		if r != nil {
+			if err := os.MkdirAll(p, 0o755); err != nil {
+				return err
+			}
+			dirs = append(dirs, m)
+		}
+	}
+
+	write := func(member string, rd io.Reader) error {
+		m := a.ix.members[member]
+		if m == nil || m.IsDir() {
+			return nil
+		}
+		p, r, err := target(member)
+		if err != nil || r == nil {
+			return err
+		}
+		// A symlink on disk (in the way, or in a folder above) could point anywhere
+		parent, err := filepath.EvalSymlinks(filepath.Dir(p))
+		if err != nil {
+			return err
+		}
+		if info, err := os.Lstat(p); !withinDir(r.resolved, parent) || err == nil && info.Mode()&os.ModeSymlink != 0 {
+			return &fs.PathError{Op: "extract", Path: member, Err: ErrUnsafePath}
+		}
+
+		perm := m.mode.Perm()
+		if perm == 0 {
+			perm = 0o644
+		}
+		f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm|0o200)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(f, &progressReader{r: rd, progress: progress}); err != nil {
+			f.Close()
+			os.Remove(p)
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		os.Chmod(p, perm)
+		os.Chtimes(p, m.modTime, m.modTime)
+		fileDone()
+		return nil
+	}
+
+	var err error
+	if a.ix.format == formatZip {
+		err = a.extractZip(write)
+	} else {
+		err = a.extractTar(write)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Folder times last, deepest first, since writing their contents bumps them
+	sort.Slice(dirs, func(i, j int) bool { return len(dirs[i].name) > len(dirs[j].name) })
+	for _, m := range dirs {
+		p, _, _ := target(m.name)
+		os.Chmod(p, m.mode.Perm()|0o700)
+		os.Chtimes(p, m.modTime, m.modTime)
+	}
+	return nil
+}
+
+// Unsafe returns the names of the archive's members that were left out
+// because they would land outside the folder the archive is extracted to
+// (zip-slip), such as "../../.bashrc"
+func (a *ArchiveFS) Unsafe() []string {
+	return a.ix.unsafe
+}
+
+func (a *ArchiveFS) extractZip(write func(member string, r io.Reader) error) error {
+	r, err := zip.OpenReader(a.path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	for _, f := range r.File {
+		name, ok := memberName(f.Name)
+		if !ok || !f.FileInfo().Mode().IsRegular() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		err = write(name, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractTar writes every copy of a member in turn, so the last one appended wins
+func (a *ArchiveFS) extractTar(write func(member string, r io.Reader) error) error {
+	tr, closeTar, err := openTar(a.path, a.ix.format)
+	if err != nil {
+		return err
+	}
+	defer closeTar()
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name, ok := memberName(hdr.Name)
+		if !ok || !hdr.FileInfo().Mode().IsRegular() {
+			continue
+		}
+		if err := write(name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// withinDir reports whether p is dir or lies below it
+func withinDir(dir, p string) bool {
+	rel, err := filepath.Rel(dir, p)
+	return err == nil && !filepath.IsAbs(rel) && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// Stat is os.Stat for paths that may lead into an archive or be remote
+func Stat(p string) (os.FileInfo, error) {
+	if IsRemote(p) {
+		fsys, name, err := Lookup(p)
+		if err != nil {
+			return nil, err
+		}
+		return fsys.Stat(name)
+	}
+	info, err := os.Stat(p)
+	if err == nil {
+		return info, nil
+	}
+	archive, member, ok := SplitArchivePath(p)
+	if !ok {
+		return nil, err
+	}
+	a, err := OpenArchive(archive)
+	if err != nil {
+		return nil, err
+	}
+	return a.Stat(member)
+}
+
+// Open is os.Open for paths that may lead into an archive or be remote
+func Open(p string) (fs.File, error) {
+	if IsRemote(p) {
+		fsys, name, err := Lookup(p)
+		if err != nil {
+			return nil, err
+		}
+		return fsys.Open(name)
+	}
+	f, err := os.Open(p)
+	if err == nil {
+		return f, nil
+	}
+	archive, member, ok := SplitArchivePath(p)
+	if !ok || member == "." {
+		return nil, err
+	}
+	a, err := OpenArchive(archive)
+	if err != nil {
+		return nil, err
+	}
+	return a.Open(member)
+}
+
+// ReadFile is os.ReadFile for paths that may lead into an archive or be remote
+func ReadFile(p string) ([]byte, error) {
+	f, err := Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
@@ -0,0 +1,292 @@
+package fs
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/justyntemme/razor/internal/debug"
+)
+
+// CompareStatus classifies one relative path of a directory comparison
+type CompareStatus int
+
+const (
+	CompareNone       CompareStatus = iota // Not a comparison result
+	CompareIdentical                       // Same on both sides
+	CompareLeftNewer                       // Differs; the left copy was modified later
+	CompareRightNewer                      // Differs; the right copy was modified later
+	CompareLeftOnly                        // Only in the left directory
+	CompareRightOnly                       // Only in the right directory
+	CompareDiffers                         // Differs with neither side newer, or a file faces a folder
+)
+
+func (s CompareStatus) String() string {
+	switch s {
+	case CompareIdentical:
+		return "Identical"
+	case CompareLeftNewer:
+		return "Newer on left"
+	case CompareRightNewer:
+		return "Newer on right"
+	case CompareLeftOnly:
+		return "Only on left"
+	case CompareRightOnly:
+		return "Only on right"
+	case CompareDiffers:
+		return "Different"
+	}
+	return ""
+}
+
+// mtimeTolerance is how far apart two modification times may be and still
+// count as the same. FAT volumes store them in 2 second steps.
+const mtimeTolerance = 2 * time.Second
+
+// compareDirs walks left and right side by side and classifies every relative
+// path below them, by size and modification time or, if byContent, by a hash
+// of the contents of files with the same size. A folder on one side only is a
+// single entry; its contents are not listed. Folders on both sides are not
+// listed themselves, only their contents. Symlinks are never followed: two
+// are the same if they point to the same target. Files and subfolders that
+// can't be read count as CompareDiffers; only an unreadable left or right
+// directory fails the comparison.
+//
+// Each Entry's Name is its path relative to the compared directories, and its
+// Path, Size and ModTime are those of the left copy if there is one.
+func (s *System) compareDirs(ctx context.Context, left, right string, byContent bool, gen int64) Response {
+	debug.Log(debug.FS, "compareDirs: left=%q right=%q byContent=%v", left, right, byContent)
+	c := &comparer{
+		ctx:       ctx,
+		left:      left,
+		right:     right,
+		byContent: byContent,
+		progress:  &searchProgress{gen: gen, useFileCount: true, progressCh: s.ProgressChan},
+	}
+	if err := c.compare(""); err != nil {
+		return Response{Op: CompareDirs, Path: left, Err: err}
+	}
+	debug.Log(debug.FS, "compareDirs: %d entries", len(c.entries))
+	return Response{Op: CompareDirs, Path: left, Entries: c.entries}
+}
+
+type comparer struct {
+	ctx         context.Context
+	left, right string
+	byContent   bool
+	progress    *searchProgress
+	entries     []Entry
+}
+
+// compare classifies the entries of the directory rel on both sides, then
+// descends into the folders found on both
+func (c *comparer) compare(rel string) error {
+	if c.ctx.Err() != nil {
+		return c.ctx.Err()
+	}
+	leftInfos, err := readDirInfos(filepath.Join(c.left, rel))
+	if err != nil {
+		return err
+	}
+	rightInfos, err := readDirInfos(filepath.Join(c.right, rel))
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(leftInfos)+len(rightInfos))
+	for name := range leftInfos {
+		names = append(names, name)
+	}
+	for name := range rightInfos {
+		if _, ok := leftInfos[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var subdirs []string
+	subdirInfos := make(map[string]os.FileInfo)
+	for _, name := range names {
+		path := filepath.Join(rel, name)
+		l, r := leftInfos[name], rightInfos[name]
+		switch {
+		case r == nil:
+			c.add(path, filepath.Join(c.left, path), l, CompareLeftOnly)
+		case l == nil:
+			c.add(path, filepath.Join(c.right, path), r, CompareRightOnly)
+		case l.IsDir() && r.IsDir():
+			subdirs = append(subdirs, path)
+			subdirInfos[path] = l
+		case l.IsDir() || r.IsDir():
+			c.add(path, filepath.Join(c.left, path), l, CompareDiffers)
+		default:
+			status, err := c.compareFiles(path, l, r)
+			if err != nil {
+				return err
+			}
+			c.add(path, filepath.Join(c.left, path), l, status)
+		}
+	}
+
+	for _, sub := range subdirs {
+		if err := c.compare(sub); err != nil {
+			if c.ctx.Err() != nil {
+				return err
+			}
+			debug.Log(debug.FS, "compareDirs: can't compare %s: %v", sub, err)
+			c.add(sub, filepath.Join(c.left, sub), subdirInfos[sub], CompareDiffers)
+		}
+	}
+	return nil
+}
+
+// compareFiles classifies a file or symlink present on both sides. Only a
+// cancelled context is an error; files that can't be read differ.
+func (c *comparer) compareFiles(rel string, l, r os.FileInfo) (CompareStatus, error) {
+	leftLink, rightLink := l.Mode()&os.ModeSymlink != 0, r.Mode()&os.ModeSymlink != 0
+	same := l.Size() == r.Size()
+	switch {
+	case leftLink != rightLink:
+		return CompareDiffers, nil
+	case leftLink:
+		leftTarget, err := os.Readlink(filepath.Join(c.left, rel))
+		if err != nil {
+			debug.Log(debug.FS, "compareDirs: can't read link %s: %v", rel, err)
+			return CompareDiffers, nil
+		}
+		rightTarget, err := os.Readlink(filepath.Join(c.right, rel))
+		if err != nil {
+			debug.Log(debug.FS, "compareDirs: can't read link %s: %v", rel, err)
+			return CompareDiffers, nil
+		}
+		same = leftTarget == rightTarget
+	case same && c.byContent:
+		leftSum, err := hashFile(c.ctx, filepath.Join(c.left, rel), -1)
+		var rightSum [sha256.Size]byte
+		if err == nil {
+			rightSum, err = hashFile(c.ctx, filepath.Join(c.right, rel), -1)
+		}
+		same = leftSum == rightSum
+		if c.ctx.Err() != nil {
+			return CompareNone, c.ctx.Err()
+		}
+		if err != nil {
+			debug.Log(debug.FS, "compareDirs: can't read %s: %v", rel, err)
+			return CompareDiffers, nil
+		}
+	case same:
+		same = sameModTime(l.ModTime(), r.ModTime())
+	}
+
+	switch {
+	case same:
+		return CompareIdentical, nil
+	case sameModTime(l.ModTime(), r.ModTime()):
+		return CompareDiffers, nil
+	case l.ModTime().After(r.ModTime()):
+		return CompareLeftNewer, nil
+	default:
+		return CompareRightNewer, nil
+	}
+}
+
+func (c *comparer) add(rel, path string, info os.FileInfo, status CompareStatus) {
+	c.entries = append(c.entries, Entry{
+		Name:    rel,
+		Path:    path,
+		IsDir:   info.IsDir(),
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		Compare: status,
+	})
+	c.progress.currentFiles++
+	if c.progress.currentFiles%1000 == 0 {
+		c.progress.report(fmt.Sprintf("Comparing folders... %d files", c.progress.currentFiles))
+	}
+}
+
+// readDirInfos describes the entries of dir without following symlinks,
+// leaving out devices, sockets and the like
+func readDirInfos(dir string) (map[string]os.FileInfo, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	infos := make(map[string]os.FileInfo, len(dirEntries))
+	for _, d := range dirEntries {
+		info, err := d.Info()
+		if err != nil {
+			continue // Deleted while reading
+		}
+		if info.IsDir() || info.Mode().IsRegular() || info.Mode()&os.ModeSymlink != 0 {
+			infos[d.Name()] = info
+		}
+	}
+	return infos, nil
+}
+
+// sameModTime reports whether two modification times are within mtimeTolerance
+func sameModTime(a, b time.Time) bool {
+	d := a.Sub(b)
+	return d < mtimeTolerance && d > -mtimeTolerance
+}
+
+// SyncMode is the direction of a directory sync
+type SyncMode int
+
+const (
+	SyncLeftToRight SyncMode = iota // Make right match left
+	SyncRightToLeft                 // Make left match right
+	SyncBothWays                    // Copy the newer version of each path to the other side
+)
+
+// SyncStep copies one file or folder from one side of a comparison to the
+// same relative path on the other
+type SyncStep struct {
+	Name     string        // Path relative to the compared directories
+	Status   CompareStatus // Why it is copied
+	Src, Dst string
+	IsDir    bool
+	ToRight  bool // Copied from left to right
+}
+
+// PlanSync lists the copies that sync the directories left and right, given
+// the entries of their comparison. One-way syncs copy every path that differs
+// over to the other side, replacing what is there, and delete nothing. Two-way
+// syncs copy paths found on one side only and the newer version of the others.
+//
+// skipped returns the differing entries the sync leaves alone: those only on
+// the target side of a one-way sync, and those with neither side newer in a
+// two-way sync.
+func PlanSync(left, right string, entries []Entry, mode SyncMode) (steps []SyncStep, skipped []Entry) {
+	toRight := func(e Entry) SyncStep {
+		return SyncStep{Name: e.Name, Status: e.Compare, Src: filepath.Join(left, e.Name), Dst: filepath.Join(right, e.Name), IsDir: e.IsDir, ToRight: true}
+	}
+	toLeft := func(e Entry) SyncStep {
+		return SyncStep{Name: e.Name, Status: e.Compare, Src: filepath.Join(right, e.Name), Dst: filepath.Join(left, e.Name), IsDir: e.IsDir}
+	}
+
+	for _, e := range entries {
+		switch {
+		case e.Compare == CompareNone || e.Compare == CompareIdentical:
+			continue
+		case mode == SyncLeftToRight && e.Compare == CompareRightOnly,
+			mode == SyncRightToLeft && e.Compare == CompareLeftOnly,
+			mode == SyncBothWays && e.Compare == CompareDiffers:
+			skipped = append(skipped, e)
+		case mode == SyncLeftToRight:
+			steps = append(steps, toRight(e))
+		case mode == SyncRightToLeft:
+			steps = append(steps, toLeft(e))
+		case e.Compare == CompareLeftOnly || e.Compare == CompareLeftNewer:
+			steps = append(steps, toRight(e))
+		default:
+			steps = append(steps, toLeft(e))
+		}
+	}
+	return steps, skipped
+}
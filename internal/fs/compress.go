@@ -0,0 +1,186 @@
+package fs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/justyntemme/razor/internal/debug"
+)
+
+// CreateArchive writes sources, each under its own name and with everything
+// below it, to an archive at dst in the format of its extension (zip, tar,
+// tar.gz or tar.zst), replacing any file there. Symlinks are stored as links
+// and never followed; sockets, devices and the like are left out. progress is
+// called with the bytes read, as in CopyContents; returning an error stops the
+// archive. fileDone is called after each file. A failed archive is removed.
+func CreateArchive(dst string, sources []string, progress func(n int64) error, fileDone func()) (err error) {
+	format := archiveFormatOf(dst)
+	if format == formatNone {
+		return &os.PathError{Op: "compress", Path: dst, Err: errors.New("unknown archive format")}
+	}
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(dst)
+		}
+	}()
+
+	buf := bufio.NewWriterSize(f, 1<<20)
+	w, finish, err := newArchiveWriter(buf, format)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	absDst, _ := filepath.Abs(dst)
+	for _, src := range sources {
+		if err = addToArchive(w, src, absDst, progress, fileDone); err != nil {
+			finish()
+			f.Close()
+			return err
+		}
+	}
+	if err = finish(); err == nil {
+		err = buf.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	debug.Log(debug.FS, "CreateArchive: %s from %d sources, err=%v", dst, len(sources), err)
+	return err
+}
+
+// archiveWriter adds one file, folder or symlink to an archive being written
+type archiveWriter func(name string, info os.FileInfo, link string, r io.Reader) error
+
+// newArchiveWriter starts an archive of format on w. finish completes the
+// archive and any compression, but doesn't close w.
+func newArchiveWriter(w io.Writer, format archiveFormat) (add archiveWriter, finish func() error, err error) {
+	if format == formatZip {
+		zw := zip.NewWriter(w)
+		return func(name string, info os.FileInfo, link string, r io.Reader) error {
+			hdr, err := zip.FileInfoHeader(info)
+			if err != nil {
+				return err
+			}
+			hdr.Name = name
+			if info.IsDir() {
+				hdr.Name += "/"
+			} else if info.Mode().IsRegular() {
+				hdr.Method = zip.Deflate
+			}
+			fw, err := zw.CreateHeader(hdr)
+			if err != nil {
+				return err
+			}
+			if link != "" {
+				_, err = io.WriteString(fw, link)
+			} else if r != nil {
+				_, err = io.Copy(fw, r)
+			}
+			return err
+		}, zw.Close, nil
+	}
+
+	var compressor io.WriteCloser
+	switch format {
+	case formatTarGz:
+		compressor = gzip.NewWriter(w)
+	case formatTarZst:
+		if compressor, err = zstd.NewWriter(w); err != nil {
+			return nil, nil, err
+		}
+	}
+	tarOut := w
+	if compressor != nil {
+		tarOut = compressor
+	}
+	tw := tar.NewWriter(tarOut)
+	add = func(name string, info os.FileInfo, link string, r io.Reader) error {
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		hdr.Name = name
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if r != nil {
+			_, err = io.Copy(tw, r)
+		}
+		return err
+	}
+	finish = func() error {
+		err := tw.Close()
+		if compressor != nil {
+			err = errors.Join(err, compressor.Close())
+		}
+		return err
+	}
+	return add, finish, nil
+}
+
+// addToArchive adds src and everything below it under the name of src,
+// skipping the archive being written (absDst) if it lies inside
+func addToArchive(add archiveWriter, src, absDst string, progress func(n int64) error, fileDone func()) error {
+	src = filepath.Clean(src)
+	base := filepath.Base(src)
+	return filepath.WalkDir(src, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if abs, _ := filepath.Abs(p); abs == absDst {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		name := path.Join(base, filepath.ToSlash(rel))
+
+		switch mode := info.Mode(); {
+		case mode.IsDir():
+			return add(name, info, "", nil)
+		case mode&os.ModeSymlink != 0:
+			link, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			if err := add(name, info, link, nil); err != nil {
+				return err
+			}
+		case mode.IsRegular():
+			f, err := os.Open(p)
+			if err != nil {
+				return err
+			}
+			err = add(name, info, "", &progressReader{r: f, progress: progress})
+			f.Close()
+			if err != nil {
+				return err
+			}
+		default:
+			return nil // Sockets, devices, pipes
+		}
+		fileDone()
+		return nil
+	})
+}
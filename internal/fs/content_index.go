@@ -0,0 +1,192 @@
+package fs
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charlievieth/fastwalk"
+	"github.com/justyntemme/razor/internal/debug"
+	"github.com/justyntemme/razor/internal/search"
+	"github.com/justyntemme/razor/internal/store"
+)
+
+// contentReadBatch is the number of changed files read and written to the
+// content index at once
+const contentReadBatch = 100
+
+// ContentIndexer keeps the FTS5 content index in razor.db up to date for a set
+// of root directories. Rescans only read files whose size or mtime changed.
+type ContentIndexer struct {
+	indexRunner
+	index *store.ContentIndex
+}
+
+// NewContentIndexer creates a content indexer for roots. Call Start to begin indexing.
+func NewContentIndexer(index *store.ContentIndex, roots []string, interval time.Duration) *ContentIndexer {
+	ix := &ContentIndexer{indexRunner: newIndexRunner("ContentIndexer", roots, interval), index: index}
+	ix.indexRunner.scan = ix.scan
+	ix.indexRunner.refreshDir = ix.refreshDir
+	ix.scanned = index.Scanned
+	ix.setScanned = index.SetScanned
+	return ix
+}
+
+// refreshDir rescans the files directly inside dir and fully scans the
+// subdirectories with nothing indexed yet
+func (ix *ContentIndexer) refreshDir(dir string) {
+	subdirs, err := ix.scanDir(dir, 1)
+	if err != nil {
+		debug.Log(debug.FS, "ContentIndexer: refresh of %s failed: %v", dir, err)
+		return
+	}
+	for _, sub := range subdirs {
+		if known, err := ix.index.Known(sub, 0); err == nil && len(known) == 0 {
+			if err := ix.scan(sub, 0); err != nil {
+				debug.Log(debug.FS, "ContentIndexer: scan of %s failed: %v", sub, err)
+			}
+		}
+	}
+}
+
+func (ix *ContentIndexer) scan(dir string, maxDepth int) error {
+	_, err := ix.scanDir(dir, maxDepth)
+	return err
+}
+
+// scanDir indexes the changed text files below dir, down to maxDepth levels
+// (0 for no limit), drops the files that are gone and returns the directories
+// at maxDepth, whose contents were not scanned
+func (ix *ContentIndexer) scanDir(dir string, maxDepth int) ([]string, error) {
+	gen := ix.nextGen()
+	known, err := ix.index.Known(dir, maxDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	var unchanged []string
+	var changed []store.ContentDoc
+	var subdirs []string
+
+	// Don't follow symlinks, as in recursive searches
+	conf := &fastwalk.Config{Follow: false}
+	err = fastwalk.Walk(conf, dir, func(path string, d fs.DirEntry, err error) error {
+		if ix.ctx.Err() != nil {
+			return ix.ctx.Err()
+		}
+		if err != nil || path == dir {
+			return nil
+		}
+		if shouldSkipPath(path) {
+			if d.IsDir() {
+				return fastwalk.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if maxDepth > 0 && fastwalk.DirEntryDepth(d) >= maxDepth {
+				mu.Lock()
+				subdirs = append(subdirs, path)
+				mu.Unlock()
+				return fastwalk.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || info.Size() > search.MaxContentSize {
+			return nil
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if f, ok := known[path]; ok && f.Size == info.Size() && f.ModTime.Equal(info.ModTime()) {
+			unchanged = append(unchanged, path)
+			return nil
+		}
+		changed = append(changed, store.ContentDoc{Path: path, Size: info.Size(), ModTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ix.index.Touch(unchanged, gen); err != nil {
+		return nil, err
+	}
+	debug.Log(debug.FS, "ContentIndexer: %s: %d unchanged, %d to read", dir, len(unchanged), len(changed))
+	for len(changed) > 0 {
+		if ix.ctx.Err() != nil {
+			return nil, ix.ctx.Err()
+		}
+		n := min(len(changed), contentReadBatch)
+		batch := changed[:n]
+		for i := range batch {
+			data, err := os.ReadFile(batch[i].Path)
+			// Unreadable files are recorded as binary so they're retried once they change
+			batch[i].Binary = err != nil || search.IsBinary(data)
+			if !batch[i].Binary {
+				batch[i].Body = string(data)
+			}
+		}
+		if err := ix.index.Put(batch, gen); err != nil {
+			return nil, err
+		}
+		changed = changed[n:]
+	}
+	return subdirs, ix.index.Sweep(dir, maxDepth, gen)
+}
+
+// canSearchContentIndex reports whether the content index can answer a
+// contents: directive: plain text of at least three characters (one trigram)
+func canSearchContentIndex(d search.Directive) bool {
+	return !d.Regex && utf8.RuneCountInString(d.Value) >= 3
+}
+
+// searchContentIndex answers a content search from the FTS5 index. The index
+// finds the candidate files; those the ignore rules (nil when off) leave are
+// then read and checked against the whole query, which also yields their
+// matching lines and drops stale candidates.
+func (s *System) searchContentIndex(ctx context.Context, gen int64, basePath string, query *search.Query, pattern search.Directive, maxDepth int, ignore *ignoreMatcher, stream *resultStream) error {
+	paths, err := s.ContentIndexer.index.Query(basePath, maxDepth, pattern.Value)
+	if err != nil {
+		return err
+	}
+	debug.Log(debug.SEARCH, "searchContentIndex: %d candidates for %q", len(paths), pattern.Value)
+
+	matcher := search.NewMatcherWithContext(ctx, query)
+	matcher.SetRoot(basePath)
+	progress := &searchProgress{gen: gen, totalFiles: len(paths), useFileCount: true, progressCh: s.ProgressChan}
+	for _, path := range paths {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if ignore != nil && ignore.IgnoredBelow(basePath, path, false) {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue // Deleted since it was indexed
+		}
+		progress.currentFiles++
+		progress.report("Checking indexed matches...")
+		if matched, hits := matcher.MatchHits(path, info); matched {
+			stream.add(Entry{
+				Name:    filepath.Base(path),
+				Path:    path,
+				IsDir:   false,
+				Size:    info.Size(),
+				ModTime: info.ModTime(),
+				Hits:    hits,
+			})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package fs
+
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+// CopyStrategy selects how CopyContents moves file data
+type CopyStrategy int
+
+const (
+	CopyAuto      CopyStrategy = iota // Fastest available: reflink, then copy_file_range, then buffered
+	CopyReflink                       // Copy-on-write clone (FICLONE on btrfs, XFS, ...)
+	CopyFileRange                     // In-kernel copy with copy_file_range
+	CopyBuffered                      // Plain read/write through userspace
+)
+
+func (s CopyStrategy) String() string {
+	switch s {
+	case CopyReflink:
+		return "reflink"
+	case CopyFileRange:
+		return "copy_file_range"
+	case CopyBuffered:
+		return "buffered"
+	}
+	return "auto"
+}
+
+// ErrCopyUnsupported is returned when a requested copy strategy is not available
+// for the given files (platform, filesystem or kernel)
+var ErrCopyUnsupported = errors.New("copy strategy not supported")
+
+// copyChunkSize bounds each in-kernel copy so progress and cancellation stay responsive
+const copyChunkSize = 8 << 20
+
+// CopyContents copies the remaining contents of src into dst, both open at their current
+// offsets. With CopyAuto each fast path falls back to the next when the filesystem refuses it.
+// progress is called with the number of bytes written by each step; returning an error
+// (e.g. because the copy was cancelled) stops the copy with that error.
+// It returns the strategy that finished the copy.
+func CopyContents(dst, src *os.File, strategy CopyStrategy, progress func(n int64) error) (CopyStrategy, error) {
+	if progress == nil {
+		progress = func(int64) error { return nil }
+	}
+
+	switch strategy {
+	case CopyReflink:
+		return CopyReflink, reflink(dst, src, progress)
+	case CopyFileRange:
+		return CopyFileRange, copyFileRange(dst, src, progress)
+	case CopyBuffered:
+		return CopyBuffered, copyBuffered(dst, src, progress)
+	}
+
+	if err := reflink(dst, src, progress); !errors.Is(err, ErrCopyUnsupported) {
+		return CopyReflink, err
+	}
+	// copy_file_range continues from wherever the file offsets are, so a partial
+	// in-kernel copy can be finished by the buffered path
+	if err := copyFileRange(dst, src, progress); !errors.Is(err, ErrCopyUnsupported) {
+		return CopyFileRange, err
+	}
+	return CopyBuffered, copyBuffered(dst, src, progress)
+}
+
+// copyBuffered copies through a userspace buffer. The reader is wrapped so io.Copy
+// cannot hand the work to the kernel behind our back.
+func copyBuffered(dst, src *os.File, progress func(n int64) error) error {
+	_, err := io.Copy(dst, &progressReader{r: src, progress: progress})
+	return err
+}
+
+// progressReader reports every read to progress
+type progressReader struct {
+	r        io.Reader
+	progress func(n int64) error
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+	n, err := pr.r.Read(p)
+	if n > 0 {
+		if perr := pr.progress(int64(n)); perr != nil {
+			return n, perr
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,82 @@
+//go:build linux
+
+package fs
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// reflink clones src into dst with FICLONE, sharing extents until either file is modified.
+// The clone is all or nothing, so progress is reported once for the whole file.
+func reflink(dst, src *os.File, progress func(n int64) error) error {
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	// FICLONE always clones the whole file, so it only applies to a copy from the start
+	if offset, err := src.Seek(0, io.SeekCurrent); err != nil || offset != 0 {
+		return ErrCopyUnsupported
+	}
+
+	if err := unix.IoctlFileClone(int(dst.Fd()), int(src.Fd())); err != nil {
+		if fastPathRefused(err) {
+			return ErrCopyUnsupported
+		}
+		return err
+	}
+
+	// Leave both offsets at the end, like a regular copy would
+	if _, err := src.Seek(0, io.SeekEnd); err != nil {
+		return err
+	}
+	if _, err := dst.Seek(0, io.SeekEnd); err != nil {
+		return err
+	}
+	return progress(info.Size())
+}
+
+// copyFileRangeCall is unix.CopyFileRange, replaced by tests to fake refusals
+var copyFileRangeCall = unix.CopyFileRange
+
+// copyFileRange copies in-kernel with copy_file_range in bounded chunks, advancing both
+// file offsets. It reports ErrCopyUnsupported only if nothing was copied yet.
+func copyFileRange(dst, src *os.File, progress func(n int64) error) error {
+	var copied int64
+	for {
+		n, err := copyFileRangeCall(int(src.Fd()), nil, int(dst.Fd()), nil, copyChunkSize, 0)
+		if errors.Is(err, unix.EINTR) {
+			continue
+		}
+		if err != nil {
+			if copied == 0 && fastPathRefused(err) {
+				return ErrCopyUnsupported
+			}
+			return err
+		}
+		if n == 0 {
+			if copied == 0 {
+				// Some filesystems (procfs, sysfs) report no data at all; let read() decide
+				return ErrCopyUnsupported
+			}
+			return nil
+		}
+		copied += int64(n)
+		if err := progress(int64(n)); err != nil {
+			return err
+		}
+	}
+}
+
+// fastPathRefused reports whether err means the kernel or filesystem can't take the fast path.
+// Like Go's internal/poll, EPERM and EBADF count too: seccomp filters (Docker, Flatpak) refuse
+// copy_file_range with EPERM, and an O_APPEND destination gives EBADF, yet read/write works.
+func fastPathRefused(err error) bool {
+	return errors.Is(err, unix.EOPNOTSUPP) || errors.Is(err, unix.ENOTSUP) ||
+		errors.Is(err, unix.EXDEV) || errors.Is(err, unix.EINVAL) ||
+		errors.Is(err, unix.ENOSYS) || errors.Is(err, unix.ENOTTY) ||
+		errors.Is(err, unix.EBADF) || errors.Is(err, unix.EPERM)
+}
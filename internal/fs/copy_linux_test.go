@@ -0,0 +1,55 @@
+//go:build linux
+
+package fs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func openCopyPair(t *testing.T, dstFlag int) (dst, src *os.File) {
+	t.Helper()
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "src"), []byte("contents"), 0o644)
+	src, err := os.Open(filepath.Join(dir, "src"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { src.Close() })
+	dst, err = os.OpenFile(filepath.Join(dir, "dst"), os.O_WRONLY|os.O_CREATE|dstFlag, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { dst.Close() })
+	return dst, src
+}
+
+func TestCopyFileRange_Refused(t *testing.T) {
+	// A seccomp filter refusing the system call, as in Docker or Flatpak
+	t.Cleanup(func() { copyFileRangeCall = unix.CopyFileRange })
+	for _, errno := range []error{unix.EPERM, unix.ENOSYS, unix.EXDEV} {
+		copyFileRangeCall = func(int, *int64, int, *int64, int, int) (int, error) { return 0, errno }
+		dst, src := openCopyPair(t, 0)
+		if err := copyFileRange(dst, src, func(int64) error { return nil }); !errors.Is(err, ErrCopyUnsupported) {
+			t.Errorf("copyFileRange with %v = %v, want ErrCopyUnsupported", errno, err)
+		}
+	}
+	copyFileRangeCall = unix.CopyFileRange
+
+	// The kernel refuses O_APPEND destinations with EBADF; the buffered copy takes them
+	dst, src := openCopyPair(t, os.O_APPEND)
+	if err := copyFileRange(dst, src, func(int64) error { return nil }); !errors.Is(err, ErrCopyUnsupported) {
+		t.Errorf("copyFileRange to an O_APPEND file = %v, want ErrCopyUnsupported", err)
+	}
+	dst, src = openCopyPair(t, os.O_APPEND)
+	if _, err := CopyContents(dst, src, CopyAuto, func(int64) error { return nil }); err != nil {
+		t.Fatalf("CopyContents to an O_APPEND file: %v", err)
+	}
+	if data, _ := os.ReadFile(dst.Name()); string(data) != "contents" {
+		t.Errorf("copied %q", data)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package fs
+
+import "os"
+
+// reflink is only implemented on Linux (FICLONE)
+func reflink(dst, src *os.File, progress func(n int64) error) error {
+	return ErrCopyUnsupported
+}
+
+// copyFileRange is only implemented on Linux
+func copyFileRange(dst, src *os.File, progress func(n int64) error) error {
+	return ErrCopyUnsupported
+}
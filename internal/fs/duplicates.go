@@ -0,0 +1,305 @@
+package fs
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/charlievieth/fastwalk"
+	"github.com/justyntemme/razor/internal/debug"
+)
+
+// partialHashSize is how much of the start of each file the first hash pass reads
+const partialHashSize = 16 * 1024
+
+// dupFile is a candidate file of a duplicate search
+type dupFile struct {
+	path string
+	info os.FileInfo
+}
+
+// findDuplicates walks root and returns the files whose contents are identical
+// to another file's. Candidates are grouped by size, then by a hash of their
+// first partialHashSize bytes and finally by a hash of the whole file, so most
+// files are never read in full. Hardlinks to the same file count once, and
+// empty files are left out.
+//
+// Each Entry's Group numbers its set of identical files, starting with the
+// set that wastes the most space.
+func (s *System) findDuplicates(ctx context.Context, root string, gen int64) Response {
+	debug.Log(debug.FS, "findDuplicates: root=%q", root)
+	progress := &searchProgress{gen: gen, useFileCount: true, progressCh: s.ProgressChan}
+
+	var mu sync.Mutex
+	bySize := make(map[int64][]dupFile)
+	scanned := 0
+
+	// Don't follow symlinks, as in recursive searches
+	conf := &fastwalk.Config{Follow: false}
+	err := fastwalk.Walk(conf, root, func(path string, d fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil || path == root {
+			return nil
+		}
+		if shouldSkipPath(path) {
+			if d.IsDir() {
+				return fastwalk.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || info.Size() == 0 {
+			return nil
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		bySize[info.Size()] = append(bySize[info.Size()], dupFile{path: path, info: info})
+		scanned++
+		if scanned%1000 == 0 {
+			progress.currentFiles = scanned
+			progress.report(fmt.Sprintf("Scanning for duplicates... %d files", scanned))
+		}
+		return nil
+	})
+	if err != nil {
+		return Response{Op: FindDuplicates, Path: root, Err: err}
+	}
+
+	// Only files sharing their size with another file can be duplicates
+	var candidates [][]dupFile
+	total := 0
+	for _, files := range bySize {
+		if files = distinctFiles(files); len(files) > 1 {
+			candidates = append(candidates, files)
+			total += len(files)
+		}
+	}
+	debug.Log(debug.FS, "findDuplicates: %d files scanned, %d share a size", scanned, total)
+
+	// Files no larger than the partial hash are fully compared by it
+	progress.totalFiles, progress.currentFiles = total, 0
+	var groups, partial [][]dupFile
+	for _, files := range candidates {
+		same, err := groupByHash(ctx, files, partialHashSize, progress, "Comparing file starts...")
+		if err != nil {
+			return Response{Op: FindDuplicates, Path: root, Err: err}
+		}
+		if files[0].info.Size() <= partialHashSize {
+			groups = append(groups, same...)
+		} else {
+			partial = append(partial, same...)
+		}
+	}
+
+	total = 0
+	for _, files := range partial {
+		total += len(files)
+	}
+	progress.totalFiles, progress.currentFiles = total, 0
+	for _, files := range partial {
+		same, err := groupByHash(ctx, files, -1, progress, "Hashing files...")
+		if err != nil {
+			return Response{Op: FindDuplicates, Path: root, Err: err}
+		}
+		groups = append(groups, same...)
+	}
+
+	// Most wasted space first, then by path so results are stable
+	for _, g := range groups {
+		sort.Slice(g, func(i, j int) bool { return g[i].path < g[j].path })
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		wi := groups[i][0].info.Size() * int64(len(groups[i])-1)
+		wj := groups[j][0].info.Size() * int64(len(groups[j])-1)
+		if wi != wj {
+			return wi > wj
+		}
+		return groups[i][0].path < groups[j][0].path
+	})
+
+	var entries []Entry
+	for i, g := range groups {
+		for _, f := range g {
+			entries = append(entries, Entry{
+				Name:    f.info.Name(),
+				Path:    f.path,
+				Size:    f.info.Size(),
+				ModTime: f.info.ModTime(),
+				Group:   i + 1,
+			})
+		}
+	}
+	debug.Log(debug.FS, "findDuplicates: %d groups, %d files", len(groups), len(entries))
+	return Response{Op: FindDuplicates, Path: root, Entries: entries}
+}
+
+// distinctFiles drops the hardlinks to files already in the list
+func distinctFiles(files []dupFile) []dupFile {
+	var distinct []dupFile
+outer:
+	for _, f := range files {
+		for _, seen := range distinct {
+			if os.SameFile(f.info, seen.info) {
+				continue outer
+			}
+		}
+		distinct = append(distinct, f)
+	}
+	return distinct
+}
+
+// groupByHash splits files into sets with the same hash of their first limit
+// bytes (the whole file if limit is negative), dropping the files that match
+// no other. Unreadable files are skipped. progress counts each file.
+func groupByHash(ctx context.Context, files []dupFile, limit int64, progress *searchProgress, label string) ([][]dupFile, error) {
+	byHash := make(map[[sha256.Size]byte][]dupFile)
+	var order [][sha256.Size]byte
+	for _, f := range files {
+		sum, err := hashFile(ctx, f.path, limit)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		progress.currentFiles++
+		progress.report(label)
+		if err != nil {
+			debug.Log(debug.FS, "findDuplicates: skipping %s: %v", f.path, err)
+			continue
+		}
+		if _, ok := byHash[sum]; !ok {
+			order = append(order, sum)
+		}
+		byHash[sum] = append(byHash[sum], f)
+	}
+
+	var groups [][]dupFile
+	for _, sum := range order {
+		if same := byHash[sum]; len(same) > 1 {
+			groups = append(groups, same)
+		}
+	}
+	return groups, nil
+}
+
+// hashFile returns the SHA-256 of the first limit bytes of a file, or of all
+// of it if limit is negative, checking ctx between chunks
+func hashFile(ctx context.Context, path string, limit int64) ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+	f, err := os.Open(path)
+	if err != nil {
+		return sum, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if limit >= 0 {
+		r = io.LimitReader(f, limit)
+	}
+	h := sha256.New()
+	buf := make([]byte, 1024*1024)
+	for {
+		if ctx.Err() != nil {
+			return sum, ctx.Err()
+		}
+		n, err := r.Read(buf)
+		h.Write(buf[:n])
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return sum, err
+		}
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
+
+// ReplaceWithHardlink replaces dup with a hardlink to keep after checking that
+// both still have the same contents. The link is created next to dup, under
+// a name nothing else has, and renamed over it, so dup is never missing. Both
+// must be on the same volume.
+func ReplaceWithHardlink(keep, dup string) error {
+	keepInfo, err := os.Stat(keep)
+	if err != nil {
+		return err
+	}
+	dupInfo, err := os.Lstat(dup)
+	if err != nil {
+		return err
+	}
+	if os.SameFile(keepInfo, dupInfo) {
+		return nil // Already linked
+	}
+	if !dupInfo.Mode().IsRegular() || keepInfo.Size() != dupInfo.Size() {
+		return fmt.Errorf("%s changed since it was compared", filepath.Base(dup))
+	}
+	if same, err := sameContents(keep, dup); err != nil {
+		return err
+	} else if !same {
+		return fmt.Errorf("%s changed since it was compared", filepath.Base(dup))
+	}
+
+	var tmp string
+	for n := 1; ; n++ {
+		tmp = filepath.Join(filepath.Dir(dup), fmt.Sprintf(".%s.razor-link-%d", filepath.Base(dup), n))
+		err := os.Link(keep, tmp)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return err
+		}
+		// Taken, perhaps by an interrupted attempt: leave it and try the next name
+	}
+	if err := os.Rename(tmp, dup); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// sameContents reports whether two files hold the same bytes
+func sameContents(a, b string) (bool, error) {
+	fa, err := os.Open(a)
+	if err != nil {
+		return false, err
+	}
+	defer fa.Close()
+	fb, err := os.Open(b)
+	if err != nil {
+		return false, err
+	}
+	defer fb.Close()
+
+	bufA := make([]byte, 256*1024)
+	bufB := make([]byte, 256*1024)
+	for {
+		na, errA := io.ReadFull(fa, bufA)
+		nb, errB := io.ReadFull(fb, bufB)
+		if na != nb || !bytes.Equal(bufA[:na], bufB[:nb]) {
+			return false, nil
+		}
+		if errA == io.EOF || errA == io.ErrUnexpectedEOF {
+			return errB == io.EOF || errB == io.ErrUnexpectedEOF, nil
+		}
+		if errA != nil {
+			return false, errA
+		}
+		if errB != nil {
+			return false, errB
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ignoreFileNames are read in every directory of a search, in increasing
+// precedence. .gitignore only applies inside a git repository, like ripgrep.
+var ignoreFileNames = []string{".gitignore", ".ignore"}
+
+// ignoreRule is one pattern line of an ignore file
+type ignoreRule struct {
+	re      *regexp.Regexp // Matches slash-separated paths relative to the file's directory
+	negate  bool           // "!pattern" re-includes what earlier rules excluded
+	dirOnly bool           // "pattern/" only matches directories
+}
+
+// ignoreDir holds the rules of one directory's ignore files
+type ignoreDir struct {
+	parent *ignoreDir
+	path   string
+	inRepo bool // The directory or one of its parents contains .git
+	rules  []ignoreRule
+}
+
+// ignoreMatcher decides which paths a search skips because of .gitignore and
+// .ignore files, with gitignore semantics: rules in deeper directories win,
+// and within a directory the last matching rule wins. Ignore files above the
+// search root apply too. It is safe for concurrent use by the walker.
+type ignoreMatcher struct {
+	mu   sync.Mutex
+	dirs map[string]*ignoreDir
+}
+
+func newIgnoreMatcher() *ignoreMatcher {
+	return &ignoreMatcher{dirs: make(map[string]*ignoreDir)}
+}
+
+// Ignored reports whether the ignore files exclude path
+func (m *ignoreMatcher) Ignored(path string, isDir bool) bool {
+	for d := m.dir(filepath.Dir(path)); d != nil; d = d.parent {
+		if len(d.rules) == 0 {
+			continue
+		}
+		rel := filepath.ToSlash(strings.TrimPrefix(path[len(d.path):], string(filepath.Separator)))
+		for i := len(d.rules) - 1; i >= 0; i-- {
+			r := d.rules[i]
+			if r.dirOnly && !isDir {
+				continue
+			}
+			if r.re.MatchString(rel) {
+				return !r.negate
+			}
+		}
+	}
+	return false
+}
+
+// IgnoredBelow reports whether a walk from root would skip path: path itself
+// or one of the directories between root and it is ignored
+func (m *ignoreMatcher) IgnoredBelow(root, path string, isDir bool) bool {
+	for p := path; len(p) > len(root); p, isDir = filepath.Dir(p), true {
+		if m.Ignored(p, isDir) {
+			return true
+		}
+	}
+	return false
+}
+
+// dir returns the rules of a directory, reading its ignore files (and those
+// of its parents) the first time
+func (m *ignoreMatcher) dir(path string) *ignoreDir {
+	m.mu.Lock()
+	d, ok := m.dirs[path]
+	m.mu.Unlock()
+	if ok {
+		return d
+	}
+
+	d = &ignoreDir{path: path}
+	if parent := filepath.Dir(path); parent != path {
+		d.parent = m.dir(parent)
+	}
+	_, err := os.Lstat(filepath.Join(path, ".git"))
+	d.inRepo = err == nil || (d.parent != nil && d.parent.inRepo)
+
+	for _, name := range ignoreFileNames {
+		if name == ".gitignore" && !d.inRepo {
+			continue
+		}
+		if data, err := os.ReadFile(filepath.Join(path, name)); err == nil {
+			d.rules = append(d.rules, parseIgnoreFile(string(data))...)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if existing, ok := m.dirs[path]; ok {
+		return existing // Another walker goroutine got here first
+	}
+	m.dirs[path] = d
+	return d
+}
+
+// parseIgnoreFile parses the lines of a .gitignore-style file
+func parseIgnoreFile(data string) []ignoreRule {
+	var rules []ignoreRule
+	for _, line := range strings.Split(data, "\n") {
+		if r, ok := parseIgnoreLine(line); ok {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// parseIgnoreLine parses one pattern. Blank lines and comments give no rule.
+func parseIgnoreLine(line string) (ignoreRule, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	// Trailing spaces don't count unless escaped with a backslash
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	if line == "" || line[0] == '#' {
+		return ignoreRule{}, false
+	}
+
+	var r ignoreRule
+	switch {
+	case line[0] == '!':
+		r.negate, line = true, line[1:]
+	case strings.HasPrefix(line, `\!`), strings.HasPrefix(line, `\#`):
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly, line = true, strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+
+	// A slash anywhere but the end anchors the pattern to the file's directory;
+	// otherwise it matches at any depth
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		atSegmentStart := i == 0 || line[i-1] == '/'
+		switch {
+		case atSegmentStart && strings.HasPrefix(line[i:], "**/"):
+			b.WriteString("(?:.*/)?") // Zero or more directories
+			i += 2
+		case atSegmentStart && line[i:] == "**":
+			b.WriteString(".*") // Everything inside
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(line[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := line[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(line):
+			i++
+			b.WriteString(regexp.QuoteMeta(line[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(line[i : i+1]))
+		}
+	}
+	b.WriteString("$")
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return ignoreRule{}, false
+	}
+	r.re = re
+	return r, true
+}
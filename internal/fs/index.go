@@ -0,0 +1,358 @@
+package fs
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charlievieth/fastwalk"
+	"github.com/justyntemme/razor/internal/debug"
+	"github.com/justyntemme/razor/internal/search"
+	"github.com/justyntemme/razor/internal/store"
+)
+
+// indexWriteBatch is the number of scanned entries written to the index at once
+const indexWriteBatch = 1000
+
+// indexRunner schedules the scans of a background index: a full scan of each
+// root whenever its last one is older than the rescan interval, and a rescan of
+// single directories the DirectoryWatcher reports as changed in between
+type indexRunner struct {
+	name     string // For logs
+	roots    []string
+	interval time.Duration
+	refresh  chan string
+	ctx      context.Context
+	cancel   context.CancelFunc
+	lastGen  int64 // Scan generation of the last scan (indexer goroutine only)
+
+	scan       func(dir string, maxDepth int) error
+	refreshDir func(dir string)
+	scanned    func(root string) time.Time
+	setScanned func(root string, t time.Time) error
+}
+
+func newIndexRunner(name string, roots []string, interval time.Duration) indexRunner {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := indexRunner{
+		name:     name,
+		interval: interval,
+		refresh:  make(chan string, 64),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+	for _, root := range roots {
+		if root = filepath.Clean(root); filepath.IsAbs(root) {
+			r.roots = append(r.roots, root)
+		}
+	}
+	return r
+}
+
+// Start indexes until Close is called
+func (r *indexRunner) Start() {
+	debug.Log(debug.FS, "%s started: roots=%v interval=%v", r.name, r.roots, r.interval)
+	r.scanDue()
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case dir := <-r.refresh:
+			r.refreshDir(dir)
+		case <-ticker.C:
+			r.scanDue()
+		}
+	}
+}
+
+// Close stops the indexer, abandoning any scan in progress
+func (r *indexRunner) Close() {
+	r.cancel()
+}
+
+// Refresh queues a rescan of one directory, e.g. after the watcher saw it change
+func (r *indexRunner) Refresh(dir string) {
+	if !r.Covers(dir) {
+		return
+	}
+	select {
+	case r.refresh <- dir:
+	default:
+		// Queue full; the periodic rescan catches up
+	}
+}
+
+// Covers reports whether path is inside one of the indexed roots
+func (r *indexRunner) Covers(path string) bool {
+	for _, root := range r.roots {
+		if path == root || strings.HasPrefix(path, root) && (strings.HasSuffix(root, string(filepath.Separator)) || path[len(root)] == filepath.Separator) {
+			return true
+		}
+	}
+	return false
+}
+
+// scanDue fully rescans the roots whose last scan is older than the interval
+func (r *indexRunner) scanDue() {
+	for _, root := range r.roots {
+		if time.Since(r.scanned(root)) < r.interval {
+			continue
+		}
+		start := time.Now()
+		if err := r.scan(root, 0); err != nil {
+			debug.Log(debug.FS, "%s: scan of %s failed: %v", r.name, root, err)
+			continue
+		}
+		if err := r.setScanned(root, start); err != nil {
+			debug.Log(debug.FS, "%s: %v", r.name, err)
+		}
+		debug.Log(debug.FS, "%s: scanned %s in %v", r.name, root, time.Since(start))
+	}
+}
+
+// nextGen returns a scan generation newer than every earlier one
+func (r *indexRunner) nextGen() int64 {
+	r.lastGen = max(r.lastGen+1, time.Now().UnixNano())
+	return r.lastGen
+}
+
+// Indexer keeps the filename index in razor.db up to date for a set of root
+// directories, like locate
+type Indexer struct {
+	indexRunner
+	index *store.FileIndex
+}
+
+// NewIndexer creates an indexer for roots. Call Start to begin indexing.
+func NewIndexer(index *store.FileIndex, roots []string, interval time.Duration) *Indexer {
+	ix := &Indexer{indexRunner: newIndexRunner("Indexer", roots, interval), index: index}
+	ix.indexRunner.scan = ix.scan
+	ix.indexRunner.refreshDir = ix.refreshDir
+	ix.scanned = index.Scanned
+	ix.setScanned = index.SetScanned
+	return ix
+}
+
+// refreshDir rescans the entries directly inside dir and fully scans the
+// subdirectories that are new to the index
+func (ix *Indexer) refreshDir(dir string) {
+	known, err := ix.index.ChildDirs(dir)
+	if err != nil {
+		debug.Log(debug.FS, "Indexer: refresh of %s failed: %v", dir, err)
+		return
+	}
+	if err := ix.scan(dir, 1); err != nil {
+		debug.Log(debug.FS, "Indexer: refresh of %s failed: %v", dir, err)
+		return
+	}
+
+	children, err := ix.index.ChildDirs(dir)
+	if err != nil {
+		debug.Log(debug.FS, "Indexer: refresh of %s failed: %v", dir, err)
+		return
+	}
+	for path := range children {
+		if !known[path] {
+			if err := ix.scan(path, 0); err != nil {
+				debug.Log(debug.FS, "Indexer: scan of %s failed: %v", path, err)
+			}
+		}
+	}
+}
+
+// scan records everything below dir, down to maxDepth levels (0 for no limit),
+// then drops the entries that are gone
+func (ix *Indexer) scan(dir string, maxDepth int) error {
+	gen := ix.nextGen()
+
+	var mu sync.Mutex
+	var batch []store.IndexEntry
+	var writeErr error
+	flush := func() {
+		if writeErr == nil {
+			writeErr = ix.index.Upsert(batch, gen)
+		}
+		batch = batch[:0]
+	}
+
+	// Don't follow symlinks, as in recursive searches
+	conf := &fastwalk.Config{Follow: false}
+	err := fastwalk.Walk(conf, dir, func(path string, d fs.DirEntry, err error) error {
+		if ix.ctx.Err() != nil {
+			return ix.ctx.Err()
+		}
+		if err != nil || path == dir {
+			return nil
+		}
+		if shouldSkipPath(path) || maxDepth > 0 && fastwalk.DirEntryDepth(d) > maxDepth {
+			if d.IsDir() {
+				return fastwalk.SkipDir
+			}
+			return nil
+		}
+		info, err := fastwalk.StatDirEntry(path, d)
+		if err != nil {
+			if info, err = os.Lstat(path); err != nil {
+				return nil
+			}
+		}
+		// Like the search walker, skip devices, sockets and the like
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			return nil
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		batch = append(batch, store.IndexEntry{
+			Path:    path,
+			Name:    d.Name(),
+			IsDir:   info.IsDir(),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+		if len(batch) >= indexWriteBatch {
+			flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if flush(); writeErr != nil {
+		return writeErr
+	}
+	return ix.index.Sweep(dir, maxDepth, gen)
+}
+
+// searchIndex answers a query from the filename index. The index narrows the
+// candidates in SQL, and the matcher and ignore rules (nil when off) check
+// each one, so results are the same as a walk's as of the last time the index
+// saw them. System directories a walk skips are never indexed.
+func (s *System) searchIndex(ctx context.Context, basePath string, query *search.Query, maxDepth int, ignore *ignoreMatcher, stream *resultStream) error {
+	matcher := search.NewMatcherWithContext(ctx, query)
+	matcher.SetRoot(basePath)
+	return s.Indexer.index.Query(indexQueryFor(query, basePath, maxDepth), func(e store.IndexEntry) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if ignore != nil && ignore.IgnoredBelow(basePath, e.Path, e.IsDir) {
+			return nil
+		}
+		if matched, hits := matcher.MatchHits(e.Path, indexedInfo{e}); matched {
+			stream.add(Entry{
+				Name:    e.Name,
+				Path:    e.Path,
+				IsDir:   e.IsDir,
+				Size:    e.Size,
+				ModTime: e.ModTime,
+				Hits:    hits,
+			})
+		}
+		return nil
+	})
+}
+
+// indexQueryFor translates the required filename, ext, size and modified
+// directives of a query into an index lookup under root
+func indexQueryFor(query *search.Query, root string, maxDepth int) store.IndexQuery {
+	q := store.IndexQuery{Root: root, MaxDepth: maxDepth, MaxSize: -1}
+	for _, d := range query.RequiredDirectives() {
+		switch d.Type {
+		case search.DirFilename:
+			// SQL LIKE only ignores ASCII case and knows no regexes or globs
+			if !d.Regex && isASCII(d.Value) && !strings.Contains(d.Value, "*") {
+				q.Names = append(q.Names, d.Value)
+			}
+		case search.DirExt:
+			if isASCII(d.Value) {
+				q.Suffixes = append(q.Suffixes, d.Value)
+			}
+		case search.DirSize:
+			lo, hi := int64(-1), int64(-1)
+			switch d.Operator {
+			case search.OpGreater:
+				lo = d.NumValue + 1
+			case search.OpGreaterEq:
+				lo = d.NumValue
+			case search.OpLess:
+				hi = d.NumValue - 1
+			case search.OpLessEq:
+				hi = d.NumValue
+			default:
+				lo, hi = d.NumValue, d.NumValue
+			}
+			if lo > q.MinSize {
+				q.MinSize = lo
+			}
+			if hi >= 0 && (q.MaxSize < 0 || hi < q.MaxSize) {
+				q.MaxSize = hi
+			}
+		case search.DirModified:
+			if d.TimeVal.IsZero() {
+				continue
+			}
+			// Equality compares calendar days, which the matcher handles
+			switch d.Operator {
+			case search.OpGreater:
+				q.ModifiedAfter = laterTime(q.ModifiedAfter, d.TimeVal.Add(time.Nanosecond))
+			case search.OpGreaterEq:
+				q.ModifiedAfter = laterTime(q.ModifiedAfter, d.TimeVal)
+			case search.OpLess:
+				q.ModifiedBefore = earlierTime(q.ModifiedBefore, d.TimeVal.Add(-time.Nanosecond))
+			case search.OpLessEq:
+				q.ModifiedBefore = earlierTime(q.ModifiedBefore, d.TimeVal)
+			}
+		}
+	}
+	return q
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// laterTime returns the later of two lower bounds (zero means none)
+func laterTime(a, b time.Time) time.Time {
+	if a.IsZero() || b.After(a) {
+		return b
+	}
+	return a
+}
+
+// earlierTime returns the earlier of two upper bounds (zero means none)
+func earlierTime(a, b time.Time) time.Time {
+	if a.IsZero() || b.Before(a) {
+		return b
+	}
+	return a
+}
+
+// indexedInfo presents an index entry to the matcher as file info
+type indexedInfo struct {
+	e store.IndexEntry
+}
+
+func (i indexedInfo) Name() string       { return i.e.Name }
+func (i indexedInfo) Size() int64        { return i.e.Size }
+func (i indexedInfo) ModTime() time.Time { return i.e.ModTime }
+func (i indexedInfo) IsDir() bool        { return i.e.IsDir }
+func (i indexedInfo) Sys() any           { return nil }
+
+func (i indexedInfo) Mode() fs.FileMode {
+	if i.e.IsDir {
+		return fs.ModeDir | 0o755
+	}
+	return 0o644
+}
@@ -0,0 +1,359 @@
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// RenameCase selects the case transform applied by a batch rename
+type RenameCase int
+
+const (
+	CaseKeep  RenameCase = iota // Leave the case alone
+	CaseLower                   // lower case, including the extension
+	CaseUpper                   // UPPER CASE, including the extension
+	CaseTitle                   // Title Case for each word of the name
+)
+
+// RenameRules describes how a batch rename rewrites each name. The rules are
+// applied in order: find/replace on the name without its extension, the
+// template, the case transform, and finally the extension.
+type RenameRules struct {
+	Find      string
+	Replace   string // In regex mode $1, ${name} etc. refer to groups
+	Regex     bool
+	Template  string // "{name}" when empty; see renameTokens
+	Start     int    // First value of {n}
+	Padding   int    // Zero-pad {n} to this many digits
+	Case      RenameCase
+	Extension string // New extension for files ("" keeps it, "." removes it)
+}
+
+// renameTokens lists the template tokens and what they expand to
+var renameTokens = map[string]func(name string, n int, padding int, mod time.Time) string{
+	"name":  func(name string, _, _ int, _ time.Time) string { return name },
+	"n":     func(_ string, n, padding int, _ time.Time) string { return fmt.Sprintf("%0*d", padding, n) },
+	"date":  func(_ string, _, _ int, mod time.Time) string { return mod.Format("2006-01-02") },
+	"time":  func(_ string, _, _ int, mod time.Time) string { return mod.Format("150405") },
+	"year":  func(_ string, _, _ int, mod time.Time) string { return mod.Format("2006") },
+	"month": func(_ string, _, _ int, mod time.Time) string { return mod.Format("01") },
+	"day":   func(_ string, _, _ int, mod time.Time) string { return mod.Format("02") },
+}
+
+// RenameItem is one file or folder in a batch rename
+type RenameItem struct {
+	Path    string
+	IsDir   bool
+	ModTime time.Time // Source of the date tokens
+}
+
+// RenameEntry is the planned outcome for one item
+type RenameEntry struct {
+	OldPath string
+	NewPath string
+	IsDir   bool
+	Problem string // Why this item cannot be renamed (blocks the whole batch)
+	InCycle bool   // Part of a swap or rotation, applied through a temporary name
+}
+
+// Changed reports whether the entry renames anything
+func (e RenameEntry) Changed() bool {
+	return e.OldPath != e.NewPath
+}
+
+// RenamePlan is the preview of a batch rename
+type RenamePlan struct {
+	Entries  []RenameEntry
+	Changes  int // Entries whose name changes
+	Problems int // Entries with a Problem
+	Cycles   int // Entries that need a temporary name
+}
+
+// RenameStep is a single rename of a plan, in the order it must be applied
+type RenameStep struct {
+	From, To string
+	IsDir    bool
+}
+
+// PlanRename works out the new name of every item and checks the batch for
+// collisions. exists reports whether a path is taken on disk (nil uses Lstat);
+// paths vacated by the batch itself never count as taken. Invalid rules (a bad
+// regex or an unknown template token) return an error.
+func PlanRename(items []RenameItem, rules RenameRules, exists func(path string) bool) (*RenamePlan, error) {
+	if exists == nil {
+		exists = func(path string) bool {
+			_, err := os.Lstat(path)
+			return err == nil
+		}
+	}
+
+	var re *regexp.Regexp
+	if rules.Regex && rules.Find != "" {
+		var err error
+		if re, err = regexp.Compile(rules.Find); err != nil {
+			return nil, fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+	template := rules.Template
+	if template == "" {
+		template = "{name}"
+	}
+	if err := checkTemplate(template); err != nil {
+		return nil, err
+	}
+
+	plan := &RenamePlan{Entries: make([]RenameEntry, len(items))}
+	sources := make(map[string]int, len(items)) // pathKey(OldPath) -> entry
+	for i, item := range items {
+		sources[pathKey(item.Path)] = i
+	}
+
+	targets := make(map[string]int, len(items)) // pathKey(NewPath) -> first entry claiming it
+	for i, item := range items {
+		name := renameOne(filepath.Base(item.Path), item.IsDir, i, item.ModTime, rules, re, template)
+		e := RenameEntry{OldPath: item.Path, NewPath: filepath.Join(filepath.Dir(item.Path), name), IsDir: item.IsDir}
+
+		switch {
+		case name == "" || name == "." || name == "..":
+			e.Problem = "name is empty"
+		case strings.ContainsRune(name, '/') || strings.ContainsRune(name, filepath.Separator):
+			e.Problem = "name contains a path separator"
+		}
+		key := pathKey(e.NewPath)
+		if e.Problem == "" {
+			if other, ok := targets[key]; ok {
+				e.Problem = "same new name as " + filepath.Base(items[other].Path)
+				if plan.Entries[other].Problem == "" {
+					plan.Entries[other].Problem = "same new name as " + filepath.Base(item.Path)
+					plan.Problems++
+				}
+			} else if _, vacated := sources[key]; !vacated && e.Changed() && exists(e.NewPath) {
+				e.Problem = filepath.Base(e.NewPath) + " already exists"
+			}
+		}
+		if _, ok := targets[key]; !ok {
+			targets[key] = i
+		}
+
+		if e.Problem != "" {
+			plan.Problems++
+		}
+		if e.Changed() {
+			plan.Changes++
+		}
+		plan.Entries[i] = e
+	}
+
+	plan.markCycles(sources)
+	return plan, nil
+}
+
+// renameOne applies the rules to a single name
+func renameOne(name string, isDir bool, index int, mod time.Time, rules RenameRules, re *regexp.Regexp, template string) string {
+	stem, ext := name, ""
+	if !isDir {
+		// Dotfiles like .bashrc have no extension
+		if e := filepath.Ext(name); e != name {
+			stem, ext = strings.TrimSuffix(name, e), e
+		}
+	}
+
+	if re != nil {
+		stem = re.ReplaceAllString(stem, rules.Replace)
+	} else if rules.Find != "" {
+		stem = strings.ReplaceAll(stem, rules.Find, rules.Replace)
+	}
+
+	stem = expandTemplate(template, stem, rules.Start+index, rules.Padding, mod)
+
+	if !isDir && rules.Extension != "" {
+		ext = ""
+		if rules.Extension != "." {
+			ext = "." + strings.TrimPrefix(rules.Extension, ".")
+		}
+	}
+
+	switch rules.Case {
+	case CaseLower:
+		stem, ext = strings.ToLower(stem), strings.ToLower(ext)
+	case CaseUpper:
+		stem, ext = strings.ToUpper(stem), strings.ToUpper(ext)
+	case CaseTitle:
+		stem = titleCase(stem)
+	}
+	return strings.TrimSpace(stem + ext)
+}
+
+// checkTemplate rejects unknown or unterminated tokens
+func checkTemplate(template string) error {
+	for rest := template; ; {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			return nil
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return errors.New("unterminated { in template")
+		}
+		token := rest[start+1 : start+end]
+		if _, ok := renameTokens[token]; !ok {
+			return fmt.Errorf("unknown template token {%s}", token)
+		}
+		rest = rest[start+end+1:]
+	}
+}
+
+// expandTemplate replaces the tokens of a template checked by checkTemplate
+func expandTemplate(template, name string, n, padding int, mod time.Time) string {
+	var b strings.Builder
+	for rest := template; ; {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			b.WriteString(rest)
+			return b.String()
+		}
+		end := start + strings.IndexByte(rest[start:], '}')
+		b.WriteString(rest[:start])
+		b.WriteString(renameTokens[rest[start+1:end]](name, n, padding, mod))
+		rest = rest[end+1:]
+	}
+}
+
+// titleCase upper-cases the first letter of each word and lower-cases the rest
+func titleCase(s string) string {
+	runes := []rune(s)
+	wordStart := true
+	for i, r := range runes {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if wordStart {
+				runes[i] = unicode.ToUpper(r)
+			} else {
+				runes[i] = unicode.ToLower(r)
+			}
+			wordStart = false
+		} else {
+			wordStart = r != '\''
+		}
+	}
+	return string(runes)
+}
+
+// pathKey is the identity of a path on the platform's usual filesystem,
+// which ignores case on macOS and Windows
+func pathKey(path string) string {
+	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+		return strings.ToLower(path)
+	}
+	return path
+}
+
+// markCycles flags entries whose new name is the old name of another entry
+// in a loop (a swap or rotation), which cannot be applied in any order
+// without a temporary name
+func (p *RenamePlan) markCycles(sources map[string]int) {
+	next := func(i int) int {
+		e := p.Entries[i]
+		if !e.Changed() || pathKey(e.OldPath) == pathKey(e.NewPath) {
+			return -1
+		}
+		if j, ok := sources[pathKey(e.NewPath)]; ok && p.Entries[j].Changed() {
+			return j
+		}
+		return -1
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make([]int, len(p.Entries))
+	for i := range p.Entries {
+		var walk []int
+		j := i
+		for j >= 0 && state[j] == unvisited {
+			state[j] = visiting
+			walk = append(walk, j)
+			j = next(j)
+		}
+		if j >= 0 && state[j] == visiting {
+			// Everything from j onwards in this walk is on the loop
+			for k := len(walk) - 1; k >= 0; k-- {
+				p.Entries[walk[k]].InCycle = true
+				p.Cycles++
+				if walk[k] == j {
+					break
+				}
+			}
+		}
+		for _, k := range walk {
+			state[k] = done
+		}
+	}
+}
+
+// pathTaken reports whether something, even a dangling symlink, may be at p
+func pathTaken(p string) bool {
+	var err error
+	if IsRemote(p) {
+		_, err = Stat(p)
+	} else {
+		_, err = os.Lstat(p)
+	}
+	return !errors.Is(err, os.ErrNotExist)
+}
+
+// Steps orders the renames of a plan so no rename lands on a name that is
+// still in use by another item of the batch. Each loop is broken by first
+// moving one of its items to a temporary name in the same folder.
+func (p *RenamePlan) Steps() []RenameStep {
+	var pending []RenameEntry
+	sources := make(map[string]bool)
+	for _, e := range p.Entries {
+		if e.Changed() {
+			pending = append(pending, e)
+			sources[pathKey(e.OldPath)] = true
+		}
+	}
+
+	var steps []RenameStep
+	for temp := 0; len(pending) > 0; {
+		remaining := pending[:0]
+		for _, e := range pending {
+			// A case-only rename targets its own key and can go at any time
+			if sources[pathKey(e.NewPath)] && pathKey(e.NewPath) != pathKey(e.OldPath) {
+				remaining = append(remaining, e)
+				continue
+			}
+			steps = append(steps, RenameStep{From: e.OldPath, To: e.NewPath, IsDir: e.IsDir})
+			delete(sources, pathKey(e.OldPath))
+		}
+
+		if len(remaining) == len(pending) {
+			// Only loops are left: park one item to free its name
+			e := &remaining[0]
+			var tmp string
+			for {
+				temp++
+				tmp = filepath.Join(filepath.Dir(e.OldPath), ".razor-rename-"+strconv.Itoa(temp)+"-"+filepath.Base(e.OldPath))
+				// Leftovers of an interrupted batch keep their names
+				if !pathTaken(tmp) {
+					break
+				}
+			}
+			steps = append(steps, RenameStep{From: e.OldPath, To: tmp, IsDir: e.IsDir})
+			delete(sources, pathKey(e.OldPath))
+			e.OldPath = tmp
+		}
+		pending = remaining
+	}
+	return steps
+}
@@ -0,0 +1,462 @@
+package fs
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// s3://bucket/key browses a bucket of Amazon S3 or a compatible store. The
+// credentials, region and, for stores other than AWS, the endpoint come from
+// the usual variables: AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY,
+// AWS_SESSION_TOKEN, AWS_REGION (or AWS_DEFAULT_REGION) and AWS_ENDPOINT_URL.
+// Without keys, requests are sent unsigned, which public buckets allow.
+//
+// Folders are key prefixes up to a "/". New folders are stored as empty
+// "name/" objects, so they show up before anything is put in them.
+
+// emptySHA256 is the hash of a request without a body
+const emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+type s3FS struct {
+	client    *http.Client
+	endpoint  url.URL // Scheme and host of the store
+	pathStyle bool    // The bucket is the first path segment rather than part of the host
+	bucket    string
+	region    string
+	keyID     string
+	secret    string
+	token     string
+}
+
+func dialS3(u *url.URL) (VFS, error) {
+	s := &s3FS{
+		client: &http.Client{
+			Transport: &http.Transport{
+				Proxy:                 http.ProxyFromEnvironment,
+				ResponseHeaderTimeout: davTimeout,
+			},
+		},
+		bucket: u.Host,
+		region: firstEnv("AWS_REGION", "AWS_DEFAULT_REGION"),
+		keyID:  os.Getenv("AWS_ACCESS_KEY_ID"),
+		secret: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		token:  os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if s.region == "" {
+		s.region = "us-east-1"
+	}
+	if endpoint := firstEnv("AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL"); endpoint != "" {
+		e, err := url.Parse(endpoint)
+		if err != nil || e.Host == "" {
+			return nil, fmt.Errorf("invalid AWS_ENDPOINT_URL %q", endpoint)
+		}
+		s.endpoint = url.URL{Scheme: e.Scheme, Host: e.Host}
+		s.pathStyle = true
+	} else {
+		s.endpoint = url.URL{Scheme: "https", Host: "s3." + s.region + ".amazonaws.com"}
+		// Bucket names with dots don't match the certificate as a subdomain
+		s.pathStyle = strings.Contains(s.bucket, ".")
+	}
+	// Fail at once on a missing bucket or wrong credentials
+	if _, _, err := s.list("", "/", 1); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// s3Error is the error document of a failed request
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// do sends a signed request for key. body, if set, is sent with its size and
+// SHA-256 hash. Any status but ok is returned as an error.
+func (s *s3FS) do(method, key string, query url.Values, header http.Header, body io.Reader, size int64, sum string, ok ...int) (*http.Response, error) {
+	u := s.endpoint
+	host := u.Host
+	p := "/" + key
+	if s.pathStyle {
+		p = "/" + s.bucket + p
+	} else {
+		host = s.bucket + "." + host
+		u.Host = host
+	}
+	u.Path = p
+	u.RawPath = uriEncode(p, false)
+	u.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	if sum == "" {
+		sum = emptySHA256
+	}
+	if s.keyID != "" {
+		s.sign(req, host, sum, time.Now())
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	for _, code := range ok {
+		if resp.StatusCode == code {
+			return resp, nil
+		}
+	}
+	defer resp.Body.Close()
+	err = statusError(resp)
+	var doc s3Error
+	if xml.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&doc) == nil && doc.Code != "" {
+		if doc.Code == "NoSuchBucket" {
+			err = fmt.Errorf("%w: no bucket %s", fs.ErrNotExist, s.bucket)
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			err = fmt.Errorf("%s: %s", doc.Code, doc.Message)
+		}
+	}
+	return nil, nameError(strings.ToLower(method), key, err)
+}
+
+// sign adds an AWS Signature Version 4 to req, covering its headers and the
+// body with the SHA-256 hash sum
+func (s *s3FS) sign(req *http.Request, host, sum string, now time.Time) {
+	stamp := now.UTC().Format("20060102T150405Z")
+	req.Header.Set("X-Amz-Date", stamp)
+	req.Header.Set("X-Amz-Content-Sha256", sum)
+	if s.token != "" {
+		req.Header.Set("X-Amz-Security-Token", s.token)
+	}
+
+	headers := map[string]string{"host": host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonical strings.Builder
+	for _, k := range names {
+		canonical.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signed := strings.Join(names, ";")
+
+	request := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonical.String(),
+		signed,
+		sum,
+	}, "\n")
+	scope := stamp[:8] + "/" + s.region + "/s3/aws4_request"
+	toSign := "AWS4-HMAC-SHA256\n" + stamp + "\n" + scope + "\n" + hexSHA256(request)
+
+	key := hmacSHA256([]byte("AWS4"+s.secret), stamp[:8])
+	for _, part := range []string{s.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+s.keyID+"/"+scope+
+		", SignedHeaders="+signed+", Signature="+signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func hexSHA256(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// uriEncode escapes everything but unreserved characters, as signatures
+// expect, keeping slashes unless encodeSlash
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// canonicalQuery encodes query sorted by key, as signatures expect
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// s3List is a page of ListObjectsV2
+type s3List struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	CommonPrefixes []struct {
+		Prefix string `xml:"Prefix"`
+	} `xml:"CommonPrefixes"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// list returns the objects below prefix, and the folders when delimiter is
+// "/", up to max of them (all if max is 0). found reports whether anything,
+// even just a folder's own "prefix/" object, has prefix.
+func (s *s3FS) list(prefix, delimiter string, max int) (infos []fs.FileInfo, found bool, err error) {
+	var token string
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if delimiter != "" {
+			query.Set("delimiter", delimiter)
+		}
+		if max > 0 {
+			query.Set("max-keys", strconv.Itoa(max))
+		}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		resp, err := s.do("GET", "", query, nil, nil, 0, "", http.StatusOK)
+		if err != nil {
+			return nil, false, err
+		}
+		var page s3List
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, false, err
+		}
+		for _, c := range page.Contents {
+			found = true
+			if c.Key == prefix {
+				continue // The folder itself
+			}
+			infos = append(infos, &fileInfo{name: path.Base(c.Key), size: c.Size, mode: 0o644, modTime: c.LastModified})
+		}
+		for _, p := range page.CommonPrefixes {
+			found = true
+			infos = append(infos, &fileInfo{name: path.Base(p.Prefix), mode: fs.ModeDir | 0o755})
+		}
+		if !page.IsTruncated || max > 0 {
+			return infos, found, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// key returns the object key of name
+func (s *s3FS) key(name string) string {
+	if name == "." {
+		return ""
+	}
+	return name
+}
+
+// prefix returns the prefix of the objects in the folder name
+func (s *s3FS) prefix(name string) string {
+	if name == "." {
+		return ""
+	}
+	return name + "/"
+}
+
+func (s *s3FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	infos, found, err := s.list(s.prefix(name), "/", 0)
+	if err != nil {
+		return nil, err
+	}
+	if !found && name != "." {
+		return nil, nameError("readdir", name, fs.ErrNotExist)
+	}
+	return dirEntries(infos), nil
+}
+
+// Stat describes an object, or a folder if objects start with name + "/"
+func (s *s3FS) Stat(name string) (fs.FileInfo, error) {
+	if name == "." {
+		return &fileInfo{name: ".", mode: fs.ModeDir | 0o755}, nil
+	}
+	resp, err := s.do("HEAD", s.key(name), nil, nil, nil, 0, "", http.StatusOK)
+	if err == nil {
+		resp.Body.Close()
+		return s.objectInfo(name, resp), nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if _, found, err := s.list(s.prefix(name), "", 1); err != nil || !found {
+		return nil, nameError("stat", name, fs.ErrNotExist)
+	}
+	return &fileInfo{name: path.Base(name), mode: fs.ModeDir | 0o755}, nil
+}
+
+func (s *s3FS) objectInfo(name string, resp *http.Response) *fileInfo {
+	info := &fileInfo{name: path.Base(name), size: resp.ContentLength, mode: 0o644}
+	info.modTime, _ = http.ParseTime(resp.Header.Get("Last-Modified"))
+	return info
+}
+
+func (s *s3FS) Open(name string) (fs.File, error) {
+	resp, err := s.do("GET", s.key(name), nil, nil, nil, 0, "", http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	return &remoteFile{ReadCloser: resp.Body, info: s.objectInfo(name, resp)}, nil
+}
+
+// Create collects what is written in a temporary file, since an object is
+// stored with a single PUT of known size, and uploads it on Close
+func (s *s3FS) Create(name string) (io.WriteCloser, error) {
+	f, err := os.CreateTemp("", "razor-s3-*")
+	if err != nil {
+		return nil, err
+	}
+	return &s3Upload{fsys: s, key: s.key(name), f: f, h: sha256.New()}, nil
+}
+
+type s3Upload struct {
+	fsys *s3FS
+	key  string
+	f    *os.File
+	h    hash.Hash
+	size int64
+}
+
+func (u *s3Upload) Write(p []byte) (int, error) {
+	n, err := u.f.Write(p)
+	u.h.Write(p[:n])
+	u.size += int64(n)
+	return n, err
+}
+
+func (u *s3Upload) Close() error {
+	defer os.Remove(u.f.Name())
+	defer u.f.Close()
+	if _, err := u.f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	var body io.Reader = u.f
+	if u.size == 0 {
+		body = strings.NewReader("") // Sent as no body rather than one of unknown size
+	}
+	sum := hex.EncodeToString(u.h.Sum(nil))
+	resp, err := u.fsys.do("PUT", u.key, nil, nil, body, u.size, sum, http.StatusOK)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// Rename copies a file to its new key and deletes the old one. Folders,
+// made of many objects, can't be renamed.
+func (s *s3FS) Rename(oldname, newname string) error {
+	info, err := s.Stat(oldname)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: errors.ErrUnsupported}
+	}
+	source := "/" + s.bucket + "/" + s.key(oldname)
+	header := http.Header{"X-Amz-Copy-Source": {uriEncode(source, false)}}
+	resp, err := s.do("PUT", s.key(newname), nil, header, nil, 0, "", http.StatusOK)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return s.Remove(oldname)
+}
+
+// Remove deletes an object, or the "name/" object of an empty folder
+func (s *s3FS) Remove(name string) error {
+	info, err := s.Stat(name)
+	if err != nil {
+		return err
+	}
+	key := s.key(name)
+	if info.IsDir() {
+		infos, _, err := s.list(s.prefix(name), "", 2)
+		if err != nil {
+			return err
+		}
+		if len(infos) > 0 {
+			return nameError("remove", name, errors.New("directory not empty"))
+		}
+		key = s.prefix(name)
+	}
+	resp, err := s.do("DELETE", key, nil, nil, nil, 0, "", http.StatusNoContent, http.StatusOK)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// MkdirAll stores the "name/" object of a folder. Parents need none: a
+// folder exists as long as something is below it.
+func (s *s3FS) MkdirAll(name string) error {
+	if name == "." {
+		return nil
+	}
+	if info, err := s.Stat(name); err == nil {
+		if !info.IsDir() {
+			return nameError("mkdir", name, errors.New("not a directory"))
+		}
+		return nil
+	}
+	resp, err := s.do("PUT", s.prefix(name), nil, nil, strings.NewReader(""), 0, emptySHA256, http.StatusOK)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
@@ -0,0 +1,170 @@
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"os/user"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// sftp://[user[:password]@]host[:port]/path browses a host over SFTP. Keys
+// come from the SSH agent and the unencrypted default keys in ~/.ssh; the
+// host key must be in ~/.ssh/known_hosts. Paths are absolute on the host.
+
+// sftpDialTimeout bounds connecting and the SSH handshake
+const sftpDialTimeout = 15 * time.Second
+
+// sftpKeyFiles are the private keys tried, in ~/.ssh
+var sftpKeyFiles = []string{"id_ed25519", "id_ecdsa", "id_rsa"}
+
+type sftpFS struct {
+	conn   *ssh.Client
+	client *sftp.Client
+}
+
+func dialSFTP(u *url.URL) (VFS, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	hostKeys, err := knownhosts.New(filepath.Join(home, ".ssh", "known_hosts"))
+	if err != nil {
+		return nil, fmt.Errorf("cannot check the host key: %w", err)
+	}
+
+	name := u.User.Username()
+	if name == "" {
+		if current, err := user.Current(); err == nil {
+			name = current.Username
+		}
+	}
+	port := u.Port()
+	if port == "" {
+		port = "22"
+	}
+	auth, closeAgent := sftpAuth(u, home)
+	config := &ssh.ClientConfig{
+		User:            name,
+		Auth:            auth,
+		HostKeyCallback: hostKeys,
+		Timeout:         sftpDialTimeout,
+	}
+	conn, err := ssh.Dial("tcp", net.JoinHostPort(u.Hostname(), port), config)
+	closeAgent()
+	if err != nil {
+		return nil, err
+	}
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &sftpFS{conn: conn, client: client}, nil
+}
+
+// sftpAuth returns the ways to log in: the password of the URL, then the
+// keys of the agent and those in ~/.ssh. closeAgent ends the connection to
+// the agent once logged in.
+func sftpAuth(u *url.URL, home string) (methods []ssh.AuthMethod, closeAgent func()) {
+	closeAgent = func() {}
+	if password, ok := u.User.Password(); ok {
+		methods = append(methods, ssh.Password(password))
+	}
+	var signers []ssh.Signer
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		if c, err := net.Dial("unix", sock); err == nil {
+			closeAgent = func() { c.Close() }
+			if agentSigners, err := agent.NewClient(c).Signers(); err == nil {
+				signers = append(signers, agentSigners...)
+			}
+		}
+	}
+	for _, file := range sftpKeyFiles {
+		data, err := os.ReadFile(filepath.Join(home, ".ssh", file))
+		if err != nil {
+			continue
+		}
+		// Keys with a passphrase need the agent
+		if signer, err := ssh.ParsePrivateKey(data); err == nil {
+			signers = append(signers, signer)
+		}
+	}
+	if len(signers) > 0 {
+		methods = append(methods, ssh.PublicKeys(signers...))
+	}
+	return methods, closeAgent
+}
+
+// path returns the absolute path of name on the host
+func (s *sftpFS) path(name string) string {
+	return path.Join("/", name)
+}
+
+func (s *sftpFS) Open(name string) (fs.File, error) {
+	return s.client.Open(s.path(name))
+}
+
+// ReadDir lists a folder, describing symlinks by their targets
+func (s *sftpFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	dir := s.path(name)
+	infos, err := s.client.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for i, info := range infos {
+		if info.Mode()&fs.ModeSymlink == 0 {
+			continue
+		}
+		if target, err := s.client.Stat(s.client.Join(dir, info.Name())); err == nil {
+			infos[i] = &fileInfo{name: info.Name(), size: target.Size(), mode: target.Mode(), modTime: target.ModTime()}
+		}
+	}
+	return dirEntries(infos), nil
+}
+
+func (s *sftpFS) Stat(name string) (fs.FileInfo, error) {
+	return s.client.Stat(s.path(name))
+}
+
+func (s *sftpFS) Create(name string) (io.WriteCloser, error) {
+	return s.client.Create(s.path(name))
+}
+
+// Rename replaces newname like os.Rename where the server supports it
+func (s *sftpFS) Rename(oldname, newname string) error {
+	err := s.client.PosixRename(s.path(oldname), s.path(newname))
+	var status *sftp.StatusError
+	if errors.As(err, &status) && status.FxCode() == sftp.ErrSSHFxOpUnsupported {
+		err = s.client.Rename(s.path(oldname), s.path(newname))
+	}
+	return err
+}
+
+func (s *sftpFS) Remove(name string) error {
+	return s.client.Remove(s.path(name))
+}
+
+func (s *sftpFS) MkdirAll(name string) error {
+	return s.client.MkdirAll(s.path(name))
+}
+
+func (s *sftpFS) Chtimes(name string, atime, mtime time.Time) error {
+	return s.client.Chtimes(s.path(name), atime, mtime)
+}
+
+// Wait blocks until the connection is closed or lost
+func (s *sftpFS) Wait() error {
+	return s.conn.Wait()
+}
@@ -3,6 +3,7 @@ package fs
 import (
 	"context"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -21,6 +22,8 @@ const (
 	FetchDir OpType = iota
 	SearchDir
 	CancelSearch
+	FindDuplicates // Find files with identical contents below Path; cancelled like a search
+	CompareDirs    // Compare Path (left) with Other (right); cancelled like a search
 )
 
 type Request struct {
@@ -31,6 +34,15 @@ type Request struct {
 	SearchEngine int    // Search engine type (0=builtin, 1=ripgrep, 2=ugrep)
 	EngineCmd    string // Command for external engine
 	DefaultDepth int    // Default recursive depth when not specified
+
+	// RespectIgnore skips paths excluded by .gitignore and .ignore files
+	// unless the query turns it off with ignore:off
+	RespectIgnore bool
+
+	// Other is the right-hand directory of CompareDirs, compared by content
+	// hashes instead of size and modification time if ByContent
+	Other     string
+	ByContent bool
 }
 
 type Entry struct {
@@ -39,8 +51,14 @@ type Entry struct {
 	IsDir   bool
 	Size    int64
 	ModTime time.Time
+	Hits    []search.ExternalSearchResult // Lines matching a contents: search
+	Group   int                           // Set of identical files of a FindDuplicates result (from 1)
+	Compare CompareStatus                 // Classification of a CompareDirs result
 }
 
+// Response answers a Request. A search first sends its matches in Partial
+// batches as it finds them; the final Response holds the matches not sent yet,
+// so the batches and the final Entries together are the full result.
 type Response struct {
 	Op        OpType
 	Path      string
@@ -48,6 +66,7 @@ type Response struct {
 	Err       error
 	Gen       int64 // Generation counter from request
 	Cancelled bool  // True if search was cancelled
+	Partial   bool  // A batch of search matches; more follow
 }
 
 // Progress represents a progress update during long operations
@@ -68,6 +87,11 @@ type System struct {
 	cancelFunc   context.CancelFunc
 	currentGen   int64
 	searchActive bool
+
+	// Indexer answers index: searches; nil when indexing is off
+	Indexer *Indexer
+	// ContentIndexer answers content searches with EngineFTS; nil when off
+	ContentIndexer *ContentIndexer
 }
 
 func NewSystem() *System {
@@ -109,7 +133,7 @@ func (s *System) Start() {
 				resp.Path, len(resp.Entries), resp.Gen, resp.Err)
 			s.ResponseChan <- resp
 
-		case SearchDir:
+		case SearchDir, FindDuplicates, CompareDirs:
 			// Cancel any existing search
 			s.cancelMu.Lock()
 			if s.cancelFunc != nil {
@@ -124,11 +148,19 @@ func (s *System) Start() {
 
 			// Run search in goroutine so we can process cancel requests
 			go func(ctx context.Context, req Request) {
-				defaultDepth := req.DefaultDepth
-				if defaultDepth <= 0 {
-					defaultDepth = 2 // Fallback default
+				var resp Response
+				switch req.Op {
+				case FindDuplicates:
+					resp = s.findDuplicates(ctx, req.Path, req.Gen)
+				case CompareDirs:
+					resp = s.compareDirs(ctx, req.Path, req.Other, req.ByContent, req.Gen)
+				default:
+					defaultDepth := req.DefaultDepth
+					if defaultDepth <= 0 {
+						defaultDepth = 2 // Fallback default
+					}
+					resp = s.searchDir(ctx, req.Path, req.Query, req.Gen, search.SearchEngine(req.SearchEngine), req.EngineCmd, defaultDepth, req.RespectIgnore, s.ResponseChan)
 				}
-				resp := s.searchDir(ctx, req.Path, req.Query, req.Gen, search.SearchEngine(req.SearchEngine), req.EngineCmd, defaultDepth)
 				resp.Gen = req.Gen
 
 				// Check if cancelled
@@ -141,8 +173,8 @@ func (s *System) Start() {
 				s.searchActive = false
 				s.cancelMu.Unlock()
 
-				debug.Log(debug.FS, "SearchDir response: path=%q entries=%d gen=%d cancelled=%v",
-					resp.Path, len(resp.Entries), resp.Gen, resp.Cancelled)
+				debug.Log(debug.FS, "Search response: op=%d path=%q entries=%d gen=%d cancelled=%v",
+					resp.Op, resp.Path, len(resp.Entries), resp.Gen, resp.Cancelled)
 				s.ResponseChan <- resp
 			}(ctx, req)
 		}
@@ -183,6 +215,13 @@ func shouldSkipPath(path string) bool {
 func (s *System) fetchDir(path string) Response {
 	debug.Log(debug.FS, "fetchDir: reading %q", path)
 
+	// Archives and remote folders are listed through their VFS
+	if _, _, ok := SplitArchivePath(path); ok || IsRemote(path) {
+		entries, err := ReadDir(path)
+		debug.Log(debug.FS, "fetchDir: %d entries from VFS, err=%v", len(entries), err)
+		return Response{Op: FetchDir, Path: path, Entries: entries, Err: err}
+	}
+
 	var result []Entry
 	var mu sync.Mutex
 
@@ -263,11 +302,17 @@ func (s *System) fetchDir(path string) Response {
 	return Response{Op: FetchDir, Path: path, Entries: result}
 }
 
-func (s *System) searchDir(ctx context.Context, basePath, queryStr string, gen int64, engine search.SearchEngine, engineCmd string, defaultDepth int) Response {
+// searchDir runs a search. When out is set, matches are sent on it in Partial
+// batches while the search runs and the returned Response only holds the rest.
+// respectIgnore is the configured default for skipping ignored paths.
+func (s *System) searchDir(ctx context.Context, basePath, queryStr string, gen int64, engine search.SearchEngine, engineCmd string, defaultDepth int, respectIgnore bool, out chan<- Response) Response {
 	debug.Log(debug.SEARCH, "searchDir: basePath=%q query=%q engine=%d defaultDepth=%d",
 		basePath, queryStr, engine, defaultDepth)
 
 	query := search.Parse(queryStr)
+	if query.Err != nil {
+		return Response{Op: SearchDir, Path: basePath, Err: query.Err}
+	}
 	if query.IsEmpty() {
 		debug.Log(debug.SEARCH, "searchDir: empty query, falling back to fetchDir")
 		return s.fetchDir(basePath)
@@ -276,7 +321,7 @@ func (s *System) searchDir(ctx context.Context, basePath, queryStr string, gen i
 	debug.Log(debug.SEARCH, "searchDir: parsed query: contentSearch=%v recursive=%v directives=%d",
 		query.HasContentSearch(), query.HasRecursive(), len(query.Directives))
 
-	var results []Entry
+	stream := &resultStream{out: out, path: basePath, gen: gen}
 
 	// Default depth is 1 (current directory only)
 	// Use recursive: directive to enable deeper search
@@ -288,14 +333,70 @@ func (s *System) searchDir(ctx context.Context, basePath, queryStr string, gen i
 		}
 	}
 
-	debug.Log(debug.SEARCH, "searchDir: maxDepth=%d", maxDepth)
+	// Remote folders are walked through their VFS; the indexes, external
+	// engines and ignore files only know the local disk
+	if IsRemote(basePath) {
+		debug.Log(debug.SEARCH, "searchDir: walking remote folder, maxDepth=%d", maxDepth)
+		matcher := search.NewMatcherWithContext(ctx, query)
+		matcher.SetRoot(basePath)
+		progress := &searchProgress{gen: gen, progressCh: s.ProgressChan}
+		if err := s.walkRemote(ctx, basePath, maxDepth, matcher, progress, stream); err != nil {
+			debug.Log(debug.SEARCH, "searchDir: remote walk error: %v", err)
+			return Response{Op: SearchDir, Path: basePath, Err: err}
+		}
+		debug.Log(debug.SEARCH, "searchDir: remote search complete, %d results", stream.count)
+		return Response{Op: SearchDir, Path: basePath, Entries: stream.rest()}
+	}
+
+	respectIgnore = query.RespectIgnore(respectIgnore)
+	var ignore *ignoreMatcher
+	if respectIgnore {
+		ignore = newIgnoreMatcher()
+	}
+
+	// index: answers from the filename index when it covers basePath.
+	// Contents are still read from disk; the walk is the fallback.
+	if query.UseIndex() && s.Indexer != nil && s.Indexer.Covers(basePath) {
+		if !query.HasRecursive() {
+			maxDepth = 0 // The whole indexed tree
+		}
+		debug.Log(debug.SEARCH, "searchDir: using filename index, maxDepth=%d", maxDepth)
+		if err := s.searchIndex(ctx, basePath, query, maxDepth, ignore, stream); err != nil {
+			if ctx.Err() != nil {
+				return Response{Op: SearchDir, Path: basePath, Cancelled: true}
+			}
+			debug.Log(debug.SEARCH, "searchDir: index error: %v", err)
+			return Response{Op: SearchDir, Path: basePath, Err: err}
+		}
+		debug.Log(debug.SEARCH, "searchDir: index search complete, %d results", stream.count)
+		return Response{Op: SearchDir, Path: basePath, Entries: stream.rest()}
+	}
+
+	debug.Log(debug.SEARCH, "searchDir: maxDepth=%d respectIgnore=%v", maxDepth, respectIgnore)
+
+	// The FTS5 content index answers plain contents: searches below its roots;
+	// regexes, short values and other paths use the builtin search
+	if query.HasContentSearch() && engine == search.EngineFTS && s.ContentIndexer != nil && s.ContentIndexer.Covers(basePath) {
+		if contentPattern, ok := query.RequiredContent(); ok && canSearchContentIndex(contentPattern) {
+			debug.Log(debug.SEARCH, "searchDir: using content index for pattern=%q", contentPattern.String())
+			if err := s.searchContentIndex(ctx, gen, basePath, query, contentPattern, maxDepth, ignore, stream); err != nil {
+				if ctx.Err() != nil {
+					return Response{Op: SearchDir, Path: basePath, Cancelled: true}
+				}
+				debug.Log(debug.SEARCH, "searchDir: content index error: %v", err)
+				return Response{Op: SearchDir, Path: basePath, Err: err}
+			}
+			debug.Log(debug.SEARCH, "searchDir: content index search complete, %d results", stream.count)
+			return Response{Op: SearchDir, Path: basePath, Entries: stream.rest()}
+		}
+	}
 
 	// Check if we should use an external search engine for content searches
 	if query.HasContentSearch() && engine != search.EngineBuiltin && engineCmd != "" {
-		// Use external search engine (ripgrep or ugrep)
-		contentPattern := query.GetContentPattern()
-		if contentPattern != "" {
-			debug.Log(debug.SEARCH, "searchDir: using external engine %s for pattern=%q", engine.String(), contentPattern)
+		// Use external search engine (ripgrep or ugrep). Its file list can only stand in
+		// for a contents: directive every result must satisfy; OR/NOT queries walk instead.
+		if contentPattern, ok := query.RequiredContent(); ok {
+			debug.Log(debug.SEARCH, "searchDir: using external engine %s for pattern=%q", engine.String(), contentPattern.String())
 			
 			// Show initial indeterminate progress
 			s.ProgressChan <- Progress{
@@ -318,7 +419,7 @@ func (s *System) searchDir(ctx context.Context, basePath, queryStr string, gen i
 			}
 			
 			// Run external search with progress callback
-			matchingPaths, err := search.SearchWithEngine(ctx, engine, engineCmd, contentPattern, basePath, maxDepth, progressFn)
+			hits, err := search.SearchWithEngine(ctx, engine, engineCmd, contentPattern, basePath, maxDepth, respectIgnore, progressFn)
 			if err != nil {
 				if ctx.Err() != nil {
 					debug.Log(debug.SEARCH, "searchDir: external search cancelled")
@@ -327,10 +428,19 @@ func (s *System) searchDir(ctx context.Context, basePath, queryStr string, gen i
 				debug.Log(debug.SEARCH, "searchDir: external search error: %v, falling back to builtin", err)
 				// Fall back to builtin on error
 			} else {
+				// Group the matching lines by file, keeping the engine's order
+				var matchingPaths []string
+				hitsByPath := make(map[string][]search.ExternalSearchResult)
+				for _, hit := range hits {
+					if _, ok := hitsByPath[hit.Path]; !ok {
+						matchingPaths = append(matchingPaths, hit.Path)
+					}
+					hitsByPath[hit.Path] = append(hitsByPath[hit.Path], hit)
+				}
 				debug.Log(debug.SEARCH, "searchDir: external search found %d paths", len(matchingPaths))
 
 				// Process results directly instead of walking entire directory
-				results, err := s.processExternalResults(ctx, gen, matchingPaths, query)
+				err := s.processExternalResults(ctx, gen, basePath, matchingPaths, hitsByPath, query, stream)
 				if err != nil {
 					if ctx.Err() != nil {
 						debug.Log(debug.SEARCH, "searchDir: result processing cancelled")
@@ -340,8 +450,8 @@ func (s *System) searchDir(ctx context.Context, basePath, queryStr string, gen i
 					return Response{Op: SearchDir, Path: basePath, Err: err}
 				}
 
-				debug.Log(debug.SEARCH, "searchDir: external search complete, %d results", len(results))
-				return Response{Op: SearchDir, Path: basePath, Entries: results}
+				debug.Log(debug.SEARCH, "searchDir: external search complete, %d results", stream.count)
+				return Response{Op: SearchDir, Path: basePath, Entries: stream.rest()}
 			}
 		}
 	}
@@ -349,6 +459,7 @@ func (s *System) searchDir(ctx context.Context, basePath, queryStr string, gen i
 	// Use builtin search (single-pass with streaming progress)
 	debug.Log(debug.SEARCH, "searchDir: using builtin search")
 	matcher := search.NewMatcherWithContext(ctx, query)
+	matcher.SetRoot(basePath)
 
 	// Create progress tracker with streaming/indeterminate progress
 	// (no longer doing a separate count pass - use single walk)
@@ -359,31 +470,73 @@ func (s *System) searchDir(ctx context.Context, basePath, queryStr string, gen i
 		progressCh: s.ProgressChan,
 	}
 
-	results, err := s.walkDirWithProgress(ctx, basePath, maxDepth, matcher, progress)
-	if err != nil {
+	if err := s.walkDirWithProgress(ctx, basePath, maxDepth, matcher, ignore, progress, stream); err != nil {
 		debug.Log(debug.SEARCH, "searchDir: walkDir error: %v", err)
 		return Response{Op: SearchDir, Path: basePath, Err: err}
 	}
 
-	debug.Log(debug.SEARCH, "searchDir: complete, %d results", len(results))
-	return Response{Op: SearchDir, Path: basePath, Entries: results}
+	debug.Log(debug.SEARCH, "searchDir: complete, %d results", stream.count)
+	return Response{Op: SearchDir, Path: basePath, Entries: stream.rest()}
+}
+
+const (
+	streamBatchSize = 200                    // Most matches in one Partial response
+	streamInterval  = 150 * time.Millisecond // Longest a match waits to be sent
+)
+
+// resultStream collects the matches of a running search and sends them as
+// Partial responses, so results show up while the walk continues. Without an
+// out channel it only collects them for the final Response.
+type resultStream struct {
+	out  chan<- Response
+	path string
+	gen  int64
+
+	mu       sync.Mutex
+	pending  []Entry
+	lastSend time.Time
+	count    int // Matches found so far
+}
+
+// add records a match, sending the pending batch once it is big or old enough.
+// The first match goes out at once.
+func (rs *resultStream) add(e Entry) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	rs.pending = append(rs.pending, e)
+	rs.count++
+	if rs.out == nil || (len(rs.pending) < streamBatchSize && time.Since(rs.lastSend) < streamInterval) {
+		return
+	}
+	rs.out <- Response{Op: SearchDir, Path: rs.path, Gen: rs.gen, Entries: rs.pending, Partial: true}
+	rs.pending = nil
+	rs.lastSend = time.Now()
+}
+
+// rest returns the matches that have not been sent
+func (rs *resultStream) rest() []Entry {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	pending := rs.pending
+	rs.pending = nil
+	return pending
 }
 
 // processExternalResults directly processes paths from external search engines
 // This is more efficient than walking the entire directory tree
-func (s *System) processExternalResults(ctx context.Context, gen int64, paths []string, query *search.Query) ([]Entry, error) {
+func (s *System) processExternalResults(ctx context.Context, gen int64, basePath string, paths []string, hits map[string][]search.ExternalSearchResult, query *search.Query, stream *resultStream) error {
 	total := len(paths)
 	if total == 0 {
-		return nil, nil
+		return nil
 	}
 	
-	var results []Entry
 	lastPct := 0
 	
 	for i, path := range paths {
 		// Check for cancellation
 		if ctx.Err() != nil {
-			return results, ctx.Err()
+			return ctx.Err()
 		}
 		
 		// Report progress every 5%
@@ -410,50 +563,47 @@ func (s *System) processExternalResults(ctx context.Context, gen int64, paths []
 		
 		// Apply additional filters (ext:, size:, modified:, filename:)
 		// Skip content matching since external tool already did that
-		if matchesNonContentDirectives(query, path, info) {
-			results = append(results, Entry{
+		if matchesNonContentDirectives(query, basePath, path, info) {
+			stream.add(Entry{
 				Name:    filepath.Base(path),
 				Path:    path,
 				IsDir:   info.IsDir(),
 				Size:    info.Size(),
 				ModTime: info.ModTime(),
+				Hits:    hits[path],
 			})
 		}
 	}
 	
-	return results, nil
+	return nil
 }
 
-// matchesNonContentDirectives checks if a file matches all non-content directives
-func matchesNonContentDirectives(query *search.Query, path string, info os.FileInfo) bool {
-	for _, d := range query.Directives {
+// matchesNonContentDirectives evaluates the query for a file the external engine
+// already matched by contents, so the contents: directive counts as satisfied.
+// path: directives match relative to basePath.
+func matchesNonContentDirectives(query *search.Query, basePath, path string, info os.FileInfo) bool {
+	if query.Root == nil {
+		return true
+	}
+	return query.Root.Eval(func(d search.Directive) bool {
 		switch d.Type {
-		case search.DirContents:
-			// Skip - external tool already matched this
-			continue
-		case search.DirRecursive:
-			// Skip - this is a control directive
-			continue
 		case search.DirFilename:
-			if !search.MatchGlob(strings.ToLower(info.Name()), strings.ToLower(d.Value)) {
-				return false
-			}
+			return d.MatchName(info.Name())
 		case search.DirExt:
-			ext := strings.ToLower(filepath.Ext(info.Name()))
-			if ext != d.Value {
-				return false
-			}
+			return strings.ToLower(filepath.Ext(info.Name())) == d.Value
 		case search.DirSize:
-			if !search.CompareInt(info.Size(), d.NumValue, d.Operator) {
-				return false
-			}
+			return search.CompareInt(info.Size(), d.NumValue, d.Operator)
 		case search.DirModified:
-			if !d.TimeVal.IsZero() && !search.CompareTime(info.ModTime(), d.TimeVal, d.Operator) {
-				return false
-			}
+			return d.TimeVal.IsZero() || search.CompareTime(info.ModTime(), d.TimeVal, d.Operator)
+		case search.DirPath:
+			return d.MatchPath(search.RelPath(basePath, path))
+		case search.DirType, search.DirPerm, search.DirOwner, search.DirGroup,
+			search.DirCreated, search.DirAccessed, search.DirEmpty:
+			return d.MatchInfo(path, info)
 		}
-	}
-	return true
+		// Contents: the external tool already matched this
+		return true
+	})
 }
 
 type searchProgress struct {
@@ -465,6 +615,18 @@ type searchProgress struct {
 	progressCh    chan Progress
 	lastReportPct int  // Last reported percentage to avoid spamming
 	useFileCount  bool // Use file count instead of bytes for progress
+
+	mu sync.Mutex // Guards the counters in fileSearched, called from parallel walks
+}
+
+// fileSearched counts a file of the given size and reports progress. Safe
+// for concurrent use by the goroutines of a walk.
+func (p *searchProgress) fileSearched(size int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.currentFiles++
+	p.currentBytes += size
+	p.report(fmt.Sprintf("Searched %d files...", p.currentFiles))
 }
 
 func (p *searchProgress) report(label string) {
@@ -517,12 +679,11 @@ func (p *searchProgress) report(label string) {
 	}
 }
 
-func (s *System) walkDirWithProgress(ctx context.Context, basePath string, maxDepth int, matcher *search.Matcher, progress *searchProgress) ([]Entry, error) {
+// walkDirWithProgress walks basePath down to maxDepth, streaming matches.
+// Paths excluded by ignore are skipped; a nil ignore walks everything.
+func (s *System) walkDirWithProgress(ctx context.Context, basePath string, maxDepth int, matcher *search.Matcher, ignore *ignoreMatcher, progress *searchProgress, stream *resultStream) error {
 	debug.Log(debug.FS_WALK, "walkDir: starting path=%q maxDepth=%d", basePath, maxDepth)
 
-	var results []Entry
-	var mu sync.Mutex
-
 	// Don't follow symlinks in recursive searches to avoid infinite loops
 	// (e.g., symlinks pointing to parent directories)
 	conf := &fastwalk.Config{
@@ -563,6 +724,15 @@ func (s *System) walkDirWithProgress(ctx context.Context, basePath string, maxDe
 			return nil
 		}
 
+		// Skip paths excluded by .gitignore and .ignore files
+		if ignore != nil && ignore.Ignored(fullPath, d.IsDir()) {
+			debug.Log(debug.FS_WALK, "walkDir: ignored: %s", fullPath)
+			if d.IsDir() {
+				return fastwalk.SkipDir
+			}
+			return nil
+		}
+
 		// Get full file info (follows symlinks)
 		info, err := fastwalk.StatDirEntry(fullPath, d)
 		if err != nil {
@@ -583,23 +753,20 @@ func (s *System) walkDirWithProgress(ctx context.Context, basePath string, maxDe
 
 		// Update progress for files (streaming progress - indeterminate with count)
 		if !isDir && info.Mode().IsRegular() {
-			progress.currentFiles++
-			progress.currentBytes += info.Size()
-			progress.report(fmt.Sprintf("Searched %d files...", progress.currentFiles))
+			progress.fileSearched(info.Size())
 		}
 
 		// Check if this entry matches
-		if matcher.Match(fullPath, info) {
+		if matched, hits := matcher.MatchHits(fullPath, info); matched {
 			debug.Log(debug.FS_WALK, "walkDir: MATCH %s", fullPath)
-			mu.Lock()
-			results = append(results, Entry{
+			stream.add(Entry{
 				Name:    d.Name(),
 				Path:    fullPath,
 				IsDir:   isDir,
 				Size:    info.Size(),
 				ModTime: info.ModTime(),
+				Hits:    hits,
 			})
-			mu.Unlock()
 		}
 
 		return nil
@@ -607,9 +774,76 @@ func (s *System) walkDirWithProgress(ctx context.Context, basePath string, maxDe
 
 	if err != nil && ctx.Err() == nil {
 		debug.Log(debug.FS_WALK, "walkDir: walk error: %v", err)
-		return results, err
+		return err
+	}
+
+	debug.Log(debug.FS_WALK, "walkDir: complete, %d results", stream.count)
+	return nil
+}
+
+// walkRemote is walkDirWithProgress for a folder on remote storage. Contents
+// are read through the folder's VFS.
+func (s *System) walkRemote(ctx context.Context, basePath string, maxDepth int, matcher *search.Matcher, progress *searchProgress, stream *resultStream) error {
+	fsys, root, err := Lookup(basePath)
+	if err != nil {
+		return err
 	}
+	matcher.SetContentFunc(func(path string) (string, error) {
+		_, name, _ := SplitRemotePath(path)
+		f, err := fsys.Open(name)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+		data, err := io.ReadAll(io.LimitReader(f, search.MaxContentSize))
+		if err != nil || search.IsBinary(data) {
+			return "", err
+		}
+		return string(data), nil
+	})
+
+	err = fs.WalkDir(fsys, root, func(name string, d fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			if name == root {
+				return err
+			}
+			debug.Log(debug.FS_WALK, "walkRemote: error at %q: %v", name, err)
+			return nil
+		}
+		rel := relName(root, name)
+		if rel == "." {
+			return nil
+		}
+		depth := strings.Count(rel, "/") + 1
 
-	debug.Log(debug.FS_WALK, "walkDir: complete, %d results", len(results))
-	return results, nil
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		fullPath := filepath.Join(basePath, filepath.FromSlash(rel))
+		if !info.IsDir() {
+			progress.fileSearched(info.Size())
+		}
+		if matched, hits := matcher.MatchHits(fullPath, info); matched {
+			stream.add(Entry{
+				Name:    d.Name(),
+				Path:    fullPath,
+				IsDir:   info.IsDir(),
+				Size:    info.Size(),
+				ModTime: info.ModTime(),
+				Hits:    hits,
+			})
+		}
+		if info.IsDir() && maxDepth > 0 && depth >= maxDepth {
+			return fs.SkipDir // Deep enough
+		}
+		return nil
+	})
+	if err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
 }
@@ -0,0 +1,267 @@
+package fs
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dav://[user[:password]@]host[:port]/path browses a WebDAV server over HTTP,
+// davs:// over HTTPS. The user and password are sent with basic auth.
+
+// davTimeout bounds each request short of reading or writing file contents
+const davTimeout = 30 * time.Second
+
+type davFS struct {
+	base       url.URL // Scheme and host of the server
+	user, pass string
+	client     *http.Client
+}
+
+func dialWebDAV(u *url.URL) (VFS, error) {
+	d := &davFS{
+		base: url.URL{Scheme: "http", Host: u.Host},
+		client: &http.Client{
+			Transport: &http.Transport{
+				Proxy:                 http.ProxyFromEnvironment,
+				ResponseHeaderTimeout: davTimeout,
+				IdleConnTimeout:       90 * time.Second,
+			},
+		},
+	}
+	if u.Scheme == "davs" {
+		d.base.Scheme = "https"
+	}
+	if u.User != nil {
+		d.user = u.User.Username()
+		d.pass, _ = u.User.Password()
+	}
+	// Fail at once on a wrong address or login
+	if _, err := d.Stat("."); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// url returns the URL of name on the server
+func (d *davFS) url(name string) string {
+	u := d.base
+	u.Path = path.Join("/", name)
+	if strings.HasSuffix(name, "/") {
+		u.Path += "/" // Folders for MKCOL
+	}
+	return u.String()
+}
+
+// do sends a request for name, returning an error for any status but ok
+func (d *davFS) do(method, name string, body io.Reader, header http.Header, ok ...int) (*http.Response, error) {
+	req, err := http.NewRequest(method, d.url(name), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if d.user != "" {
+		req.SetBasicAuth(d.user, d.pass)
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	for _, code := range ok {
+		if resp.StatusCode == code {
+			return resp, nil
+		}
+	}
+	resp.Body.Close()
+	return nil, nameError(strings.ToLower(method), name, statusError(resp))
+}
+
+// statusError turns an HTTP status into an error that errors.Is can match
+// against fs.ErrNotExist and the like
+func statusError(resp *http.Response) error {
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return fs.ErrNotExist
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("%w (%s)", fs.ErrPermission, resp.Status)
+	case http.StatusPreconditionFailed:
+		return fs.ErrExist
+	}
+	return errors.New(resp.Status)
+}
+
+const davPropfind = `<?xml version="1.0" encoding="utf-8"?>
+<D:propfind xmlns:D="DAV:"><D:prop><D:resourcetype/><D:getcontentlength/><D:getlastmodified/></D:prop></D:propfind>`
+
+// davMultistatus is the answer to a PROPFIND
+type davMultistatus struct {
+	Responses []struct {
+		Href     string `xml:"href"`
+		Propstat []struct {
+			Status string `xml:"status"`
+			Prop   struct {
+				ResourceType struct {
+					Collection *struct{} `xml:"collection"`
+				} `xml:"resourcetype"`
+				ContentLength string `xml:"getcontentlength"`
+				LastModified  string `xml:"getlastmodified"`
+			} `xml:"prop"`
+		} `xml:"propstat"`
+	} `xml:"response"`
+}
+
+// propfind describes name and, with depth 1, its children
+func (d *davFS) propfind(name, depth string) (self fs.FileInfo, children []fs.FileInfo, err error) {
+	header := http.Header{"Depth": {depth}, "Content-Type": {"application/xml; charset=utf-8"}}
+	resp, err := d.do("PROPFIND", name, strings.NewReader(davPropfind), header, http.StatusMultiStatus)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	var ms davMultistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		return nil, nil, nameError("propfind", name, err)
+	}
+
+	want := strings.TrimSuffix(path.Join("/", name), "/")
+	for _, r := range ms.Responses {
+		href, err := url.Parse(r.Href)
+		if err != nil {
+			continue
+		}
+		p := strings.TrimSuffix(href.Path, "/")
+		info := &fileInfo{name: path.Base(p), mode: 0o644}
+		for _, ps := range r.Propstat {
+			if !strings.Contains(ps.Status, " 200") {
+				continue
+			}
+			if ps.Prop.ResourceType.Collection != nil {
+				info.mode = fs.ModeDir | 0o755
+			}
+			info.size, _ = strconv.ParseInt(ps.Prop.ContentLength, 10, 64)
+			info.modTime, _ = http.ParseTime(ps.Prop.LastModified)
+		}
+		if p == want {
+			if name == "." {
+				info.name = "."
+			}
+			self = info
+		} else {
+			children = append(children, info)
+		}
+	}
+	if self == nil {
+		return nil, nil, nameError("propfind", name, fs.ErrNotExist)
+	}
+	return self, children, nil
+}
+
+func (d *davFS) Stat(name string) (fs.FileInfo, error) {
+	info, _, err := d.propfind(name, "0")
+	return info, err
+}
+
+func (d *davFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	self, children, err := d.propfind(name, "1")
+	if err != nil {
+		return nil, err
+	}
+	if !self.IsDir() {
+		return nil, nameError("readdir", name, errors.New("not a directory"))
+	}
+	return dirEntries(children), nil
+}
+
+func (d *davFS) Open(name string) (fs.File, error) {
+	resp, err := d.do("GET", name, nil, nil, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	info := &fileInfo{name: path.Base(name), size: resp.ContentLength, mode: 0o644}
+	info.modTime, _ = http.ParseTime(resp.Header.Get("Last-Modified"))
+	return &remoteFile{ReadCloser: resp.Body, info: info}, nil
+}
+
+// Create uploads what is written with a single PUT, finished by Close
+func (d *davFS) Create(name string) (io.WriteCloser, error) {
+	pr, pw := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		resp, err := d.do("PUT", name, pr, nil, http.StatusOK, http.StatusCreated, http.StatusNoContent)
+		if err == nil {
+			resp.Body.Close()
+		}
+		pr.CloseWithError(err) // Fail writes still waiting
+		done <- err
+	}()
+	return &upload{pw: pw, done: done}, nil
+}
+
+// upload is a file being written by a request in flight
+type upload struct {
+	pw   *io.PipeWriter
+	done chan error
+}
+
+func (u *upload) Write(p []byte) (int, error) { return u.pw.Write(p) }
+
+func (u *upload) Close() error {
+	u.pw.Close()
+	return <-u.done
+}
+
+func (d *davFS) Rename(oldname, newname string) error {
+	header := http.Header{"Destination": {d.url(newname)}, "Overwrite": {"T"}}
+	resp, err := d.do("MOVE", oldname, nil, header, http.StatusCreated, http.StatusNoContent)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// Remove deletes a file or an empty folder; DELETE alone would take a
+// folder's contents with it
+func (d *davFS) Remove(name string) error {
+	self, children, err := d.propfind(name, "1")
+	if err != nil {
+		return err
+	}
+	if self.IsDir() && len(children) > 0 {
+		return nameError("remove", name, errors.New("directory not empty"))
+	}
+	resp, err := d.do("DELETE", name, nil, nil, http.StatusOK, http.StatusNoContent)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// MkdirAll creates name and its missing parents with MKCOL
+func (d *davFS) MkdirAll(name string) error {
+	if info, err := d.Stat(name); err == nil {
+		if !info.IsDir() {
+			return nameError("mkdir", name, errors.New("not a directory"))
+		}
+		return nil
+	}
+	if parent := path.Dir(name); parent != name && parent != "." {
+		if err := d.MkdirAll(parent); err != nil {
+			return err
+		}
+	}
+	resp, err := d.do("MKCOL", name+"/", nil, nil, http.StatusCreated)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
@@ -3,12 +3,15 @@ package search
 import (
 	"bufio"
 	"context"
+	"io"
 	"log"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"syscall"
+	"unicode/utf8"
 )
 
 // SearchEngine represents a content search engine
@@ -18,6 +21,7 @@ const (
 	EngineBuiltin SearchEngine = iota // Go's built-in file reading
 	EngineRipgrep                     // ripgrep (rg)
 	EngineUgrep                       // ugrep (ug)
+	EngineFTS                         // SQLite FTS5 content index (razor.db)
 )
 
 func (e SearchEngine) String() string {
@@ -26,6 +30,8 @@ func (e SearchEngine) String() string {
 		return "ripgrep"
 	case EngineUgrep:
 		return "ugrep"
+	case EngineFTS:
+		return "fts"
 	default:
 		return "builtin"
 	}
@@ -101,6 +107,15 @@ func DetectEngines() []EngineInfo {
 		})
 	}
 
+	// The content index is built into razor.db; it is usable once roots are configured
+	engines = append(engines, EngineInfo{
+		Engine:    EngineFTS,
+		Name:      "Content index (not configured)",
+		Command:   "",
+		Available: false,
+		Version:   "SQLite FTS5",
+	})
+
 	return engines
 }
 
@@ -117,35 +132,141 @@ func getCommandVersion(cmd string, versionFlag string) string {
 	return ""
 }
 
-// ExternalSearchResult represents a match from an external search engine
+// MaxHitsPerFile is how many matching lines a content search keeps per file
+const MaxHitsPerFile = 3
+
+// maxSnippetLen caps the length of a hit's Content on long lines
+const maxSnippetLen = 120
+
+// ExternalSearchResult is a line matching a contents: directive, from an
+// external search engine or the builtin matcher
 type ExternalSearchResult struct {
-	Path    string
-	Line    int
-	Content string
+	Path       string
+	Line       int    // 1-based line number
+	Column     int    // Byte offset of the match in the full line
+	Content    string // Snippet of the line around the match
+	Start, End int    // Byte range of the match within Content (equal when unknown)
+}
+
+// newHit builds the result for one matching line. loc is the byte range of the
+// match in line, or nil when the engine's match can't be located in Go.
+func newHit(path string, lineNo int, line string, loc []int) ExternalSearchResult {
+	line = strings.TrimRight(line, "\r")
+	start, end := 0, 0
+	if loc != nil && loc[1] <= len(line) {
+		start, end = loc[0], loc[1]
+	}
+
+	// Drop the indentation, and on long lines keep a window around the match
+	indent := len(line) - len(strings.TrimLeft(line, " \t"))
+	from := min(indent, start)
+	if start-from > maxSnippetLen/3 {
+		from = start - maxSnippetLen/3
+		for from < start && !utf8.RuneStart(line[from]) {
+			from++
+		}
+	}
+	to := len(line)
+	if to-from > maxSnippetLen {
+		to = max(from+maxSnippetLen, end)
+		for to > end && to < len(line) && !utf8.RuneStart(line[to]) {
+			to--
+		}
+	}
+
+	prefix, suffix := "", ""
+	if from > indent {
+		prefix = "…"
+	}
+	if to < len(line) {
+		suffix = "…"
+	}
+	return ExternalSearchResult{
+		Path:    path,
+		Line:    lineNo,
+		Column:  start,
+		Content: prefix + line[from:to] + suffix,
+		Start:   len(prefix) + start - from,
+		End:     len(prefix) + end - from,
+	}
+}
+
+// FindHits returns up to limit lines of text matching a contents: directive
+func FindHits(d Directive, path, text string, limit int) []ExternalSearchResult {
+	locate := d.textLocator()
+	var hits []ExternalSearchResult
+	for lineNo := 1; text != "" && len(hits) < limit; lineNo++ {
+		line := text
+		if i := strings.IndexByte(text, '\n'); i >= 0 {
+			line, text = text[:i], text[i+1:]
+		} else {
+			text = ""
+		}
+		if loc := locate(line); loc != nil {
+			hits = append(hits, newHit(path, lineNo, line, loc))
+		}
+	}
+	return hits
+}
+
+// mergeHits orders the hits of several directives by line, dropping
+// duplicate lines and keeping at most MaxHitsPerFile
+func mergeHits(hits []ExternalSearchResult) []ExternalSearchResult {
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Line < hits[j].Line })
+	merged := hits[:0]
+	for _, h := range hits {
+		if len(merged) > 0 && merged[len(merged)-1].Line == h.Line {
+			continue
+		}
+		if merged = append(merged, h); len(merged) == MaxHitsPerFile {
+			break
+		}
+	}
+	return merged
 }
 
 // SearchWithEngine performs a content search using the specified engine
-// Returns a list of file paths that match the pattern
-// progressFn is called periodically with the number of results found so far
-func SearchWithEngine(ctx context.Context, engine SearchEngine, engineCmd, pattern, basePath string, maxDepth int, progressFn func(found int)) ([]string, error) {
+// Returns the first MaxHitsPerFile lines of every file whose contents match the
+// contents: directive (honouring its regex and case-sensitivity modes), with
+// the lines of each file together
+// respectIgnore makes the engine skip paths excluded by .gitignore and .ignore files
+// progressFn is called periodically with the number of files found so far
+func SearchWithEngine(ctx context.Context, engine SearchEngine, engineCmd string, pattern Directive, basePath string, maxDepth int, respectIgnore bool, progressFn func(found int)) ([]ExternalSearchResult, error) {
 	switch engine {
 	case EngineRipgrep:
-		return searchWithRipgrep(ctx, engineCmd, pattern, basePath, maxDepth, progressFn)
+		args := ripgrepArgs(pattern, basePath, maxDepth, respectIgnore)
+		log.Printf("[RIPGREP] Running: %s %v", engineCmd, args)
+		return runSearchCommand(ctx, engineCmd, args, pattern, progressFn)
 	case EngineUgrep:
-		return searchWithUgrep(ctx, engineCmd, pattern, basePath, maxDepth, progressFn)
+		args := ugrepArgs(pattern, basePath, maxDepth, respectIgnore)
+		log.Printf("[UGREP] Running: %s %v", engineCmd, args)
+		return runSearchCommand(ctx, engineCmd, args, pattern, progressFn)
 	default:
 		return nil, nil // Builtin doesn't use this function
 	}
 }
 
-func searchWithRipgrep(ctx context.Context, cmd, pattern, basePath string, maxDepth int, progressFn func(found int)) ([]string, error) {
+func ripgrepArgs(pattern Directive, basePath string, maxDepth int, respectIgnore bool) []string {
 	args := []string{
-		"--files-with-matches", // Only output file names
+		"--null", "--line-number", "--with-filename", // path NUL line:text
 		"--no-heading",
-		"--ignore-case",
+		"--color", "never",
+		"--max-count", strconv.Itoa(MaxHitsPerFile),
+		"--max-columns", "1000", // Elide minified lines
 		"--max-filesize", "10M", // Skip files larger than 10MB
 	}
 
+	// Plain values are literal text like the builtin matcher; regexes use
+	// ripgrep's syntax, which agrees with Go's RE2 for everything RE2 supports
+	if !pattern.Regex {
+		args = append(args, "--fixed-strings")
+	}
+	if pattern.CaseSensitive {
+		args = append(args, "--case-sensitive")
+	} else {
+		args = append(args, "--ignore-case")
+	}
+
 	// ripgrep depth semantics:
 	// --max-depth 1 = search only in the specified directory (no subdirs)
 	// --max-depth 2 = specified dir + 1 level of subdirs
@@ -154,20 +275,33 @@ func searchWithRipgrep(ctx context.Context, cmd, pattern, basePath string, maxDe
 		args = append(args, "--max-depth", strconv.Itoa(maxDepth))
 	}
 
-	args = append(args, "--", pattern, basePath)
+	// ripgrep reads .gitignore and .ignore files by default
+	if !respectIgnore {
+		args = append(args, "--no-ignore")
+	}
 
-	log.Printf("[RIPGREP] Running: %s %v", cmd, args)
-	return runSearchCommand(ctx, cmd, args, progressFn)
+	return append(args, "--", pattern.Value, basePath)
 }
 
-func searchWithUgrep(ctx context.Context, cmd, pattern, basePath string, maxDepth int, progressFn func(found int)) ([]string, error) {
+func ugrepArgs(pattern Directive, basePath string, maxDepth int, respectIgnore bool) []string {
 	args := []string{
-		"-l",              // Only output file names
-		"-i",              // Ignore case
+		"--null", "-n", "-H", // path NUL line:text
+		"--color=never",
+		"--max-count=" + strconv.Itoa(MaxHitsPerFile),
 		"--ignore-binary", // Skip binary files
 		"-r",              // Recursive (required for directory searching)
 	}
 
+	// Plain values are literal text; regexes use Perl syntax, the closest to Go's
+	if pattern.Regex {
+		args = append(args, "-P")
+	} else {
+		args = append(args, "-F")
+	}
+	if !pattern.CaseSensitive {
+		args = append(args, "-i") // Ignore case
+	}
+
 	// ugrep depth semantics:
 	// --max-depth=1 = search only in the specified directory (no subdirs)
 	// --max-depth=2 = specified dir + 1 level of subdirs
@@ -176,13 +310,15 @@ func searchWithUgrep(ctx context.Context, cmd, pattern, basePath string, maxDept
 		args = append(args, "--max-depth="+strconv.Itoa(maxDepth))
 	}
 
-	args = append(args, "--", pattern, basePath)
+	// ugrep only reads ignore files it is told about
+	if respectIgnore {
+		args = append(args, "--ignore-files=.gitignore", "--ignore-files=.ignore")
+	}
 
-	log.Printf("[UGREP] Running: %s %v", cmd, args)
-	return runSearchCommand(ctx, cmd, args, progressFn)
+	return append(args, "--", pattern.Value, basePath)
 }
 
-func runSearchCommand(ctx context.Context, cmd string, args []string, progressFn func(found int)) ([]string, error) {
+func runSearchCommand(ctx context.Context, cmd string, args []string, pattern Directive, progressFn func(found int)) ([]ExternalSearchResult, error) {
 	log.Printf("[EXTERNAL_SEARCH] Running: %s %v", cmd, args)
 	
 	c := exec.CommandContext(ctx, cmd, args...)
@@ -197,35 +333,57 @@ func runSearchCommand(ctx context.Context, cmd string, args []string, progressFn
 		return nil, err
 	}
 
-	var results []string
-	scanner := bufio.NewScanner(stdout)
-	lastReport := 0
+	var results []ExternalSearchResult
+	reader := bufio.NewReaderSize(stdout, 64*1024)
+	locate := pattern.textLocator()
+	files, lastReport := 0, 0
+	lastPath := ""
+	var readErr error
 	
-	for scanner.Scan() {
+	for {
 		if ctx.Err() != nil {
 			c.Process.Kill()
 			return results, ctx.Err()
 		}
-		path := strings.TrimSpace(scanner.Text())
-		if path != "" {
-			// Normalize path
-			if abs, err := filepath.Abs(path); err == nil {
-				results = append(results, abs)
-			} else {
-				results = append(results, path)
+		line, err := readOutputLine(reader)
+		if err != nil {
+			if err != io.EOF {
+				readErr = err
 			}
-			
+			break
+		}
+
+		// Each line is "path NUL line:text"
+		path, rest, ok := strings.Cut(line, "\x00")
+		if !ok || path == "" {
+			continue
+		}
+		lineNum, text, _ := strings.Cut(strings.TrimPrefix(rest, ":"), ":")
+		n, err := strconv.Atoi(lineNum)
+		if err != nil {
+			continue
+		}
+
+		// Normalize path
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		results = append(results, newHit(path, n, text, locate(text)))
+
+		if path != lastPath {
+			lastPath = path
+			files++
 			// Report progress every 10 files found
-			if progressFn != nil && len(results)-lastReport >= 10 {
-				progressFn(len(results))
-				lastReport = len(results)
+			if progressFn != nil && files-lastReport >= 10 {
+				progressFn(files)
+				lastReport = files
 			}
 		}
 	}
 
 	// Final progress report
-	if progressFn != nil && len(results) > lastReport {
-		progressFn(len(results))
+	if progressFn != nil && files > lastReport {
+		progressFn(files)
 	}
 
 	// Wait for command to finish and check exit code
@@ -239,7 +397,7 @@ func runSearchCommand(ctx context.Context, cmd string, args []string, progressFn
 				if exitCode == 1 {
 					// Exit code 1 = no matches found, not an error
 					log.Printf("[EXTERNAL_SEARCH] Complete: no matches found")
-					return results, scanner.Err()
+					return results, readErr
 				}
 				// Exit code 2+ = real error
 				log.Printf("[EXTERNAL_SEARCH] Error: exit code %d: %v", exitCode, err)
@@ -250,8 +408,24 @@ func runSearchCommand(ctx context.Context, cmd string, args []string, progressFn
 		return results, err
 	}
 
-	log.Printf("[EXTERNAL_SEARCH] Complete: found %d files", len(results))
-	return results, scanner.Err()
+	log.Printf("[EXTERNAL_SEARCH] Complete: found %d files", files)
+	return results, readErr
+}
+
+// readOutputLine reads one line of engine output, cutting lines longer than
+// the reader's buffer (the rest is discarded)
+func readOutputLine(r *bufio.Reader) (string, error) {
+	line, isPrefix, err := r.ReadLine()
+	if err != nil {
+		return "", err
+	}
+	text := string(line)
+	for isPrefix {
+		if _, isPrefix, err = r.ReadLine(); err != nil {
+			break
+		}
+	}
+	return text, nil
 }
 
 // MatchesExternalResults checks if a path is in the external search results
@@ -271,11 +445,23 @@ func GetEngineByName(name string) SearchEngine {
 		return EngineRipgrep
 	case "ugrep", "ug":
 		return EngineUgrep
+	case "fts", "index":
+		return EngineFTS
 	default:
 		return EngineBuiltin
 	}
 }
 
+// IsEngineAvailable reports whether an engine is among the available detected engines
+func IsEngineAvailable(engine SearchEngine, engines []EngineInfo) bool {
+	for _, e := range engines {
+		if e.Engine == engine && e.Available {
+			return true
+		}
+	}
+	return false
+}
+
 // GetEngineCommand returns the command for an engine from detected engines
 func GetEngineCommand(engine SearchEngine, engines []EngineInfo) string {
 	for _, e := range engines {
@@ -5,6 +5,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 )
@@ -17,6 +19,7 @@ func TestSearchEngine_String(t *testing.T) {
 		{EngineBuiltin, "builtin"},
 		{EngineRipgrep, "ripgrep"},
 		{EngineUgrep, "ugrep"},
+		{EngineFTS, "fts"},
 		{SearchEngine(999), "builtin"}, // Unknown defaults to builtin
 	}
 
@@ -65,6 +68,8 @@ func TestGetEngineByName(t *testing.T) {
 		{"Ripgrep", EngineRipgrep},
 		{"ugrep", EngineUgrep},
 		{"ug", EngineUgrep},
+		{"fts", EngineFTS},
+		{"index", EngineFTS},
 		{"unknown", EngineBuiltin}, // Unknown defaults to builtin
 		{"", EngineBuiltin},        // Empty defaults to builtin
 	}
@@ -140,6 +145,23 @@ func TestMatchesExternalResults(t *testing.T) {
 	}
 }
 
+func TestIsEngineAvailable(t *testing.T) {
+	engines := []EngineInfo{
+		{Engine: EngineBuiltin, Available: true},
+		{Engine: EngineRipgrep, Available: true},
+		{Engine: EngineFTS, Available: false},
+	}
+	if !IsEngineAvailable(EngineRipgrep, engines) {
+		t.Error("ripgrep should be available")
+	}
+	if IsEngineAvailable(EngineFTS, engines) {
+		t.Error("fts should not be available when it isn't configured")
+	}
+	if IsEngineAvailable(EngineUgrep, engines) {
+		t.Error("ugrep should not be available when it wasn't detected")
+	}
+}
+
 func TestSearchEngine_Constants(t *testing.T) {
 	// Verify constants have distinct values
 	if EngineBuiltin == EngineRipgrep || EngineRipgrep == EngineUgrep {
@@ -199,16 +221,19 @@ func TestSearchWithEngine_Ripgrep(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	results, err := SearchWithEngine(ctx, EngineRipgrep, rgPath, "hello", tmpDir, 1, nil)
+	results, err := SearchWithEngine(ctx, EngineRipgrep, rgPath, Directive{Type: DirContents, Value: "hello"}, tmpDir, 1, true, nil)
 	if err != nil {
 		t.Fatalf("SearchWithEngine failed: %v", err)
 	}
 
-	// Should find match.txt
+	// Should find match.txt, with the matching line
 	found := false
-	for _, path := range results {
-		if filepath.Base(path) == "match.txt" {
+	for _, hit := range results {
+		if filepath.Base(hit.Path) == "match.txt" {
 			found = true
+			if hit.Line != 1 || hit.Content != "hello world" || hit.Content[hit.Start:hit.End] != "hello" {
+				t.Errorf("hit = %+v, want line 1 \"hello world\" with \"hello\" highlighted", hit)
+			}
 			break
 		}
 	}
@@ -217,8 +242,8 @@ func TestSearchWithEngine_Ripgrep(t *testing.T) {
 	}
 
 	// Should NOT find nomatch.txt
-	for _, path := range results {
-		if filepath.Base(path) == "nomatch.txt" {
+	for _, hit := range results {
+		if filepath.Base(hit.Path) == "nomatch.txt" {
 			t.Error("nomatch.txt should not be in results")
 		}
 	}
@@ -247,15 +272,15 @@ func TestSearchWithEngine_Ugrep(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	results, err := SearchWithEngine(ctx, EngineUgrep, ugPath, "func", tmpDir, 1, nil)
+	results, err := SearchWithEngine(ctx, EngineUgrep, ugPath, Directive{Type: DirContents, Value: "func"}, tmpDir, 1, true, nil)
 	if err != nil {
 		t.Fatalf("SearchWithEngine failed: %v", err)
 	}
 
 	// Should find match.go
 	found := false
-	for _, path := range results {
-		if filepath.Base(path) == "match.go" {
+	for _, hit := range results {
+		if filepath.Base(hit.Path) == "match.go" {
 			found = true
 			break
 		}
@@ -274,7 +299,7 @@ func TestSearchWithEngine_Builtin(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	results, err := SearchWithEngine(ctx, EngineBuiltin, "", "test", tmpDir, 1, nil)
+	results, err := SearchWithEngine(ctx, EngineBuiltin, "", Directive{Type: DirContents, Value: "test"}, tmpDir, 1, true, nil)
 
 	// Builtin engine should return error or empty results when called via SearchWithEngine
 	// because SearchWithEngine is for external engines only
@@ -300,7 +325,7 @@ func TestSearchWithEngine_Cancellation(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel() // Cancel immediately
 
-	_, err = SearchWithEngine(ctx, EngineRipgrep, rgPath, "content", tmpDir, 10, nil)
+	_, err = SearchWithEngine(ctx, EngineRipgrep, rgPath, Directive{Type: DirContents, Value: "content"}, tmpDir, 10, true, nil)
 
 	// Should return quickly due to cancellation
 	// The error may or may not be set depending on timing
@@ -326,7 +351,7 @@ func TestSearchWithEngine_WithProgress(t *testing.T) {
 		progressCalled = true
 	}
 
-	_, err = SearchWithEngine(ctx, EngineRipgrep, rgPath, "searchterm", tmpDir, 1, progressFn)
+	_, err = SearchWithEngine(ctx, EngineRipgrep, rgPath, Directive{Type: DirContents, Value: "searchterm"}, tmpDir, 1, true, progressFn)
 	if err != nil {
 		t.Fatalf("SearchWithEngine failed: %v", err)
 	}
@@ -346,3 +371,138 @@ func BenchmarkGetEngineByName(b *testing.B) {
 		}
 	}
 }
+
+func TestEngineArgs_TextModes(t *testing.T) {
+	testCases := []struct {
+		query   string
+		ripgrep []string
+		ugrep   []string
+	}{
+		{"contents:foo.bar", []string{"--fixed-strings", "--ignore-case"}, []string{"-F", "-i"}},
+		{"case:contents:Foo", []string{"--fixed-strings", "--case-sensitive"}, []string{"-F"}},
+		{"contents:/fo+/", []string{"--ignore-case"}, []string{"-P", "-i"}},
+		{"case:contents:/Fo+/", []string{"--case-sensitive"}, []string{"-P"}},
+	}
+
+	contains := func(args []string, flag string) bool {
+		for _, a := range args {
+			if a == flag {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, tc := range testCases {
+		d, ok := Parse(tc.query).RequiredContent()
+		if !ok {
+			t.Fatalf("query %q: no contents directive", tc.query)
+		}
+		for _, engine := range []struct {
+			name  string
+			args  []string
+			want  []string
+			flags []string
+		}{
+			{"ripgrep", ripgrepArgs(d, "/base", 1, true), tc.ripgrep, []string{"--fixed-strings", "--ignore-case", "--case-sensitive"}},
+			{"ugrep", ugrepArgs(d, "/base", 1, true), tc.ugrep, []string{"-F", "-P", "-i"}},
+		} {
+			for _, flag := range engine.flags {
+				if want := contains(engine.want, flag); contains(engine.args, flag) != want {
+					t.Errorf("%s args for %q: %s present = %v, want %v (%v)", engine.name, tc.query, flag, !want, want, engine.args)
+				}
+			}
+			// The pattern always follows "--" so it can't be taken for a flag
+			n := len(engine.args)
+			if engine.args[n-3] != "--" || engine.args[n-2] != d.Value || engine.args[n-1] != "/base" {
+				t.Errorf("%s args for %q end with %v", engine.name, tc.query, engine.args[n-3:])
+			}
+		}
+	}
+}
+
+// TestSearchWithEngine_TextModes checks the installed engines agree with the builtin matcher
+func TestSearchWithEngine_TextModes(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"upper.txt":   "TODO: later",
+		"lower.txt":   "todo: later",
+		"literal.txt": "a.b",
+		"other.txt":   "axb",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var engines []EngineInfo
+	for _, e := range DetectEngines() {
+		if e.Engine != EngineBuiltin && e.Available {
+			engines = append(engines, e)
+		}
+	}
+	if len(engines) == 0 {
+		t.Skip("no external search engine installed")
+	}
+
+	for _, query := range []string{"contents:todo", "case:contents:TODO", "contents:a.b", "contents:/a.b/", "contents:/^todo/"} {
+		d, _ := Parse(query).RequiredContent()
+		var want []string
+		for name, content := range files {
+			if d.MatchText(content) {
+				want = append(want, name)
+			}
+		}
+		sort.Strings(want)
+
+		for _, e := range engines {
+			hits, err := SearchWithEngine(context.Background(), e.Engine, e.Command, d, tmpDir, 1, true, nil)
+			if err != nil {
+				t.Errorf("%s %q: %v", e.Name, query, err)
+				continue
+			}
+			var got []string
+			for _, hit := range hits {
+				got = append(got, filepath.Base(hit.Path))
+			}
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Errorf("%s %q: got %v, builtin matcher gives %v", e.Name, query, got, want)
+			}
+		}
+	}
+}
+
+func TestFindHits_Snippets(t *testing.T) {
+	long := strings.Repeat("x", 200) + "needle" + strings.Repeat("y", 200)
+	text := "\t\tfirst Needle\r\n" + long + "\nnone\nnéedle NEEDLE\n"
+
+	hits := FindHits(Parse("contents:needle").Directives[0], "/f", text, 10)
+	if len(hits) != 3 {
+		t.Fatalf("got %d hits, want 3: %+v", len(hits), hits)
+	}
+
+	for _, h := range hits {
+		if got := h.Content[h.Start:h.End]; !strings.EqualFold(got, "needle") {
+			t.Errorf("line %d: highlighted %q in %q", h.Line, got, h.Content)
+		}
+	}
+	// Indentation and the CR are dropped; the column is in the full line
+	if h := hits[0]; h.Line != 1 || h.Content != "first Needle" || h.Column != 8 {
+		t.Errorf("first hit = %+v", h)
+	}
+	// Long lines are cut to a window around the match
+	if h := hits[1]; h.Line != 2 || h.Column != 200 || len(h.Content) > maxSnippetLen+2*len("…") ||
+		!strings.HasPrefix(h.Content, "…") || !strings.HasSuffix(h.Content, "…") {
+		t.Errorf("long line hit = %+v", h)
+	}
+	// The first match of the line is highlighted
+	if h := hits[2]; h.Line != 4 || h.Column != len("néedle ") {
+		t.Errorf("last hit = %+v", h)
+	}
+
+	if hits := FindHits(Parse("contents:needle").Directives[0], "/f", text, 1); len(hits) != 1 {
+		t.Errorf("limit 1 gave %d hits", len(hits))
+	}
+}
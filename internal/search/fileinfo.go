@@ -0,0 +1,149 @@
+package search
+
+import (
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RelPath returns path relative to root with forward slashes, as path:
+// directives see it. Paths outside root are returned whole.
+func RelPath(root, path string) string {
+	if root != "" {
+		if rel, err := filepath.Rel(root, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
+// MatchInfo reports whether a file satisfies a type:, perm:, owner:, group:,
+// created:, accessed: or empty: directive. Attributes the platform doesn't
+// record, like birth times on some file systems, never match.
+func (d Directive) MatchInfo(path string, info os.FileInfo) bool {
+	// Index entries only know names, sizes and times
+	if info.Sys() == nil {
+		if fi, err := os.Stat(path); err == nil {
+			info = fi
+		}
+	}
+
+	switch d.Type {
+	case DirType:
+		switch d.Value {
+		case "file":
+			return info.Mode().IsRegular()
+		case "dir":
+			return info.IsDir()
+		case "symlink":
+			// Searches see what links point to, so look at the link itself
+			fi, err := os.Lstat(path)
+			return err == nil && fi.Mode()&os.ModeSymlink != 0
+		case "exec":
+			return info.Mode().IsRegular() && isExecutable(info)
+		}
+		return false
+
+	case DirPerm:
+		perm := int64(info.Mode().Perm())
+		switch d.Operator {
+		case OpGreater:
+			return perm&d.NumValue != 0
+		case OpLess:
+			return perm&d.NumValue == 0
+		}
+		return perm == d.NumValue
+
+	case DirOwner:
+		uid, _ := fileOwner(info)
+		return uid != "" && (d.Value == uid || d.Value == userName(uid))
+
+	case DirGroup:
+		_, gid := fileOwner(info)
+		return gid != "" && (d.Value == gid || d.Value == groupName(gid))
+
+	case DirCreated:
+		if d.TimeVal.IsZero() {
+			return true
+		}
+		t := fileCreated(path, info)
+		return !t.IsZero() && CompareTime(t, d.TimeVal, d.Operator)
+
+	case DirAccessed:
+		if d.TimeVal.IsZero() {
+			return true
+		}
+		t := fileAccessed(info)
+		return !t.IsZero() && CompareTime(t, d.TimeVal, d.Operator)
+
+	case DirEmpty:
+		want := true
+		switch d.Value {
+		case "no", "false", "0", "off":
+			want = false
+		}
+		return isEmpty(path, info) == want
+	}
+	return true
+}
+
+// isEmpty reports whether a file has no bytes or a directory no entries
+func isEmpty(path string, info os.FileInfo) bool {
+	if !info.IsDir() {
+		return info.Size() == 0
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	_, err = f.Readdirnames(1)
+	return err == io.EOF
+}
+
+// Owner names are looked up once per id, since a search checks many files
+var (
+	namesMu    sync.Mutex
+	userNames  = make(map[string]string)
+	groupNames = make(map[string]string)
+)
+
+// userName returns the name of a user id, or "" if it has none
+func userName(uid string) string {
+	namesMu.Lock()
+	defer namesMu.Unlock()
+	name, ok := userNames[uid]
+	if !ok {
+		if u, err := user.LookupId(uid); err == nil {
+			name = u.Username
+		}
+		userNames[uid] = name
+	}
+	return name
+}
+
+// groupName returns the name of a group id, or "" if it has none
+func groupName(gid string) string {
+	namesMu.Lock()
+	defer namesMu.Unlock()
+	name, ok := groupNames[gid]
+	if !ok {
+		if g, err := user.LookupGroupId(gid); err == nil {
+			name = g.Name
+		}
+		groupNames[gid] = name
+	}
+	return name
+}
+
+// timespec converts seconds and nanoseconds since the epoch, where zero means unknown
+func timespec(sec, nsec int64) time.Time {
+	if sec == 0 && nsec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, nsec)
+}
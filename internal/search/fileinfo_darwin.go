@@ -0,0 +1,37 @@
+package search
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// fileOwner returns the numeric user and group ids owning a file
+func fileOwner(info os.FileInfo) (uid, gid string) {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return strconv.FormatUint(uint64(st.Uid), 10), strconv.FormatUint(uint64(st.Gid), 10)
+	}
+	return "", ""
+}
+
+// fileAccessed returns the last access time of a file
+func fileAccessed(info os.FileInfo) time.Time {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return timespec(st.Atimespec.Sec, st.Atimespec.Nsec)
+	}
+	return time.Time{}
+}
+
+// fileCreated returns the birth time of a file
+func fileCreated(path string, info os.FileInfo) time.Time {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return timespec(st.Birthtimespec.Sec, st.Birthtimespec.Nsec)
+	}
+	return time.Time{}
+}
+
+// isExecutable reports whether anyone may execute a file
+func isExecutable(info os.FileInfo) bool {
+	return info.Mode().Perm()&0o111 != 0
+}
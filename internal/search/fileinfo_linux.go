@@ -0,0 +1,41 @@
+package search
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// fileOwner returns the numeric user and group ids owning a file
+func fileOwner(info os.FileInfo) (uid, gid string) {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return strconv.FormatUint(uint64(st.Uid), 10), strconv.FormatUint(uint64(st.Gid), 10)
+	}
+	return "", ""
+}
+
+// fileAccessed returns the last access time of a file
+func fileAccessed(info os.FileInfo) time.Time {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return timespec(st.Atim.Sec, st.Atim.Nsec)
+	}
+	return time.Time{}
+}
+
+// fileCreated returns the birth time of a file, which stat doesn't report on
+// Linux; statx does on file systems that record it
+func fileCreated(path string, info os.FileInfo) time.Time {
+	var stx unix.Statx_t
+	if err := unix.Statx(unix.AT_FDCWD, path, 0, unix.STATX_BTIME, &stx); err != nil || stx.Mask&unix.STATX_BTIME == 0 {
+		return time.Time{}
+	}
+	return timespec(stx.Btime.Sec, int64(stx.Btime.Nsec))
+}
+
+// isExecutable reports whether anyone may execute a file
+func isExecutable(info os.FileInfo) bool {
+	return info.Mode().Perm()&0o111 != 0
+}
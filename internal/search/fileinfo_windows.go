@@ -0,0 +1,40 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// fileOwner returns "" as Windows files are owned by security identifiers,
+// which owner: and group: don't support
+func fileOwner(info os.FileInfo) (uid, gid string) {
+	return "", ""
+}
+
+// fileAccessed returns the last access time of a file
+func fileAccessed(info os.FileInfo) time.Time {
+	if fa, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
+		return time.Unix(0, fa.LastAccessTime.Nanoseconds())
+	}
+	return time.Time{}
+}
+
+// fileCreated returns the creation time of a file
+func fileCreated(path string, info os.FileInfo) time.Time {
+	if fa, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
+		return time.Unix(0, fa.CreationTime.Nanoseconds())
+	}
+	return time.Time{}
+}
+
+// isExecutable reports whether a file has an extension Windows runs
+func isExecutable(info os.FileInfo) bool {
+	switch strings.ToLower(filepath.Ext(info.Name())) {
+	case ".exe", ".com", ".bat", ".cmd", ".ps1":
+		return true
+	}
+	return false
+}
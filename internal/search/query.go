@@ -1,10 +1,13 @@
 package search
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -20,9 +23,20 @@ const (
 	DirSize
 	DirModified
 	DirRecursive
+	DirIgnore
+	DirIndex
+	DirType     // file, dir, symlink or exec (type:executable)
+	DirPerm     // Permission bits
+	DirOwner    // Owning user name or id
+	DirGroup    // Owning group name or id
+	DirPath     // Path relative to the search root
+	DirCreated  // Birth time, where the platform records it
+	DirAccessed // Last access time
+	DirEmpty    // Empty files and directories
 )
 
-// Comparison operators for size/date
+// Comparison operators for size/date. perm: uses OpEquals for exact octal
+// modes, OpGreater for "+" (any of the bits set) and OpLess for "-" (none set).
 type Operator int
 
 const (
@@ -39,23 +53,164 @@ type Directive struct {
 	Type     DirectiveType
 	Value    string
 	Operator Operator
-	NumValue int64     // Parsed size in bytes
+	NumValue int64     // Parsed size in bytes, or permission bits
 	TimeVal  time.Time // Parsed date
+
+	// Text matching mode for filename:, contents: and path:
+	Regex         bool           // Value is a regular expression (re:, regex: or /pattern/)
+	CaseSensitive bool           // case: modifier; text matches ignore case otherwise
+	Err           error          // Invalid regular expression
+	re            *regexp.Regexp // Compiled Value when Regex is set; locates plain case-insensitive contents: otherwise
+}
+
+// MatchName reports whether a file name satisfies a filename: directive.
+// Plain values are substrings or * globs.
+func (d Directive) MatchName(name string) bool {
+	if d.Regex {
+		return d.re != nil && d.re.MatchString(name)
+	}
+	if d.CaseSensitive {
+		return MatchGlob(name, d.Value)
+	}
+	return MatchGlob(strings.ToLower(name), strings.ToLower(d.Value))
+}
+
+// MatchPath reports whether a slash-separated path relative to the search
+// root satisfies a path: directive, with the same rules as MatchName
+func (d Directive) MatchPath(rel string) bool {
+	return d.MatchName(rel)
+}
+
+// MatchText reports whether text (e.g. file contents) satisfies a contents: directive
+func (d Directive) MatchText(text string) bool {
+	if d.Regex {
+		return d.re != nil && d.re.MatchString(text)
+	}
+	if d.CaseSensitive {
+		return strings.Contains(text, d.Value)
+	}
+	return strings.Contains(strings.ToLower(text), strings.ToLower(d.Value))
+}
+
+// textLocator returns a function finding the byte range of the directive's
+// first match in a line, or nil when the line does not match
+func (d Directive) textLocator() func(line string) []int {
+	switch {
+	case d.Regex:
+		if d.re == nil {
+			return func(string) []int { return nil }
+		}
+		return d.re.FindStringIndex
+	case d.CaseSensitive:
+		return func(line string) []int {
+			if i := strings.Index(line, d.Value); i >= 0 {
+				return []int{i, i + len(d.Value)}
+			}
+			return nil
+		}
+	}
+	// Lower-casing can change byte lengths, so locate with a folding regex
+	if d.re == nil {
+		return foldingRegexp(d.Value).FindStringIndex
+	}
+	return d.re.FindStringIndex
+}
+
+// foldingRegexp matches value literally, ignoring case
+func foldingRegexp(value string) *regexp.Regexp {
+	return regexp.MustCompile("(?i)" + regexp.QuoteMeta(value))
+}
+
+// String formats the directive the way it would be typed
+func (d Directive) String() string {
+	value := d.Value
+	// Quote values that would otherwise parse as operators
+	if strings.Contains(value, " ") || strings.HasPrefix(value, "(") || (len(value) > 1 && value[0] == '-') {
+		value = `"` + value + `"`
+	}
+	if d.Regex {
+		value = "/" + d.Value + "/"
+	}
+	prefix := ""
+	if d.CaseSensitive {
+		prefix = "case:"
+	}
+
+	switch d.Type {
+	case DirContents:
+		return prefix + "contents:" + value
+	case DirExt:
+		return "ext:" + strings.TrimPrefix(value, ".")
+	case DirSize:
+		return "size:" + value
+	case DirModified:
+		return "modified:" + value
+	case DirRecursive:
+		return "recursive:" + value
+	case DirIgnore:
+		return "ignore:" + value
+	case DirIndex:
+		return "index:" + value
+	case DirType:
+		return "type:" + value
+	case DirPerm:
+		return "perm:" + value
+	case DirOwner:
+		return "owner:" + value
+	case DirGroup:
+		return "group:" + value
+	case DirPath:
+		return prefix + "path:" + value
+	case DirCreated:
+		return "created:" + value
+	case DirAccessed:
+		return "accessed:" + value
+	case DirEmpty:
+		return "empty:" + value
+	}
+	return prefix + value
 }
 
-// Query holds parsed search directives
+// NodeKind is the type of a query expression node
+type NodeKind int
+
+const (
+	NodeDirective NodeKind = iota // Leaf: a single directive
+	NodeAnd                       // All children match
+	NodeOr                        // Any child matches
+	NodeNot                       // The single child does not match
+)
+
+// Node is one node of a parsed query expression
+type Node struct {
+	Kind      NodeKind
+	Directive Directive // For NodeDirective
+	Children  []*Node   // Operands of NodeAnd/NodeOr, the negated node of NodeNot
+}
+
+// Query holds a parsed search expression
 type Query struct {
-	Directives []Directive
+	Root       *Node       // Filter expression (nil matches everything)
+	Directives []Directive // Every directive in the query, in order, including control directives
 	Raw        string
+	Err        error // First invalid directive (e.g. a bad regular expression)
 }
 
-// Parse parses a search string into directives
+// Parse parses a search string into an expression over directives
 // Examples:
 //   - "foo" -> filename:foo
 //   - "contents:hello" -> search file contents for "hello"
 //   - "ext:go" -> files with .go extension
 //   - "size:>1MB" -> files larger than 1MB
 //   - "modified:>2024-01-01" -> files modified after Jan 1, 2024
+//
+// Terms separated by spaces must all match (AND binds tighter than OR):
+//   - "ext:go OR ext:rs" -> files with either extension
+//   - "-vendor" or "NOT vendor" -> names not containing "vendor"
+//   - "test (ext:go OR ext:rs)" -> parentheses group terms
+//
+// Control directives like recursive: are collected in Directives but are not
+// part of the expression.
 func Parse(input string) *Query {
 	q := &Query{Raw: input}
 	input = strings.TrimSpace(input)
@@ -63,17 +218,286 @@ func Parse(input string) *Query {
 		return q
 	}
 
-	// Split by spaces, but respect quotes
-	parts := splitRespectingQuotes(input)
-
-	for _, part := range parts {
-		d := parseDirective(part)
-		q.Directives = append(q.Directives, d)
+	p := &parser{tokens: tokenize(input)}
+	for p.pos < len(p.tokens) {
+		// A stray ")" has no group to close; skip it and keep parsing
+		if n := p.parseOr(); n != nil {
+			q.Root = combine(NodeAnd, []*Node{q.Root, n})
+		}
+		if p.pos < len(p.tokens) {
+			p.pos++
+		}
+	}
+	q.Directives = p.directives
+	for _, d := range q.Directives {
+		if d.Err != nil {
+			q.Err = d.Err
+			break
+		}
 	}
 
 	return q
 }
 
+// tokenKind distinguishes operators from search terms
+type tokenKind int
+
+const (
+	tokWord  tokenKind = iota
+	tokOpen            // (
+	tokClose           // )
+	tokNot             // - prefix
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	quoted bool // Contained quotes, so it is never a keyword
+}
+
+// tokenize splits a query into words and operators, respecting quotes.
+// Parentheses are only operators at the start of a word or when they close
+// a group; "file(1).txt" stays a single word. A leading "-" negates the
+// word or group that follows it.
+func tokenize(s string) []token {
+	var tokens []token
+	var current strings.Builder
+	quoted := false
+	inQuotes := false
+	quoteChar := rune(0)
+	depth := 0      // Open groups
+	wordParens := 0 // Parentheses opened inside the current word
+	runes := []rune(s)
+
+	flush := func() {
+		if current.Len() > 0 || quoted {
+			tokens = append(tokens, token{kind: tokWord, text: current.String(), quoted: quoted})
+		}
+		current.Reset()
+		quoted = false
+		wordParens = 0
+	}
+
+	for i, r := range runes {
+		atWordStart := current.Len() == 0 && !quoted
+		switch {
+		case inQuotes:
+			if r == quoteChar {
+				inQuotes = false
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			inQuotes, quoteChar, quoted = true, r, true
+		case r == ' ' || r == '\t':
+			flush()
+		case r == '-' && atWordStart && i+1 < len(runes) && runes[i+1] != ' ' && runes[i+1] != '\t':
+			tokens = append(tokens, token{kind: tokNot, text: "-"})
+		case r == '(' && atWordStart:
+			tokens = append(tokens, token{kind: tokOpen, text: "("})
+			depth++
+		case r == '(':
+			wordParens++
+			current.WriteRune(r)
+		case r == ')' && wordParens > 0:
+			wordParens--
+			current.WriteRune(r)
+		case r == ')' && depth > 0:
+			flush()
+			tokens = append(tokens, token{kind: tokClose, text: ")"})
+			depth--
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+
+	return tokens
+}
+
+// parser is a recursive descent parser over tokens:
+//
+//	or    = and { "OR" and }
+//	and   = unary { ["AND"] unary }
+//	unary = ("NOT" | "-") unary | "(" or ")" | word
+type parser struct {
+	tokens     []token
+	pos        int
+	directives []Directive
+}
+
+// keyword reports whether the token at pos is the given operator keyword
+func (p *parser) keyword(word string) bool {
+	if p.pos >= len(p.tokens) {
+		return false
+	}
+	t := p.tokens[p.pos]
+	return t.kind == tokWord && !t.quoted && t.text == word
+}
+
+// atEnd reports whether the current group (or the input) has ended
+func (p *parser) atEnd() bool {
+	return p.pos >= len(p.tokens) || p.tokens[p.pos].kind == tokClose
+}
+
+func (p *parser) parseOr() *Node {
+	var terms []*Node
+	for {
+		if n := p.parseAnd(); n != nil {
+			terms = append(terms, n)
+		}
+		if !p.keyword("OR") {
+			return combine(NodeOr, terms)
+		}
+		p.pos++
+	}
+}
+
+func (p *parser) parseAnd() *Node {
+	var terms []*Node
+	for !p.atEnd() && !p.keyword("OR") {
+		if p.keyword("AND") {
+			p.pos++
+			continue
+		}
+		if n := p.parseUnary(); n != nil {
+			terms = append(terms, n)
+		}
+	}
+	return combine(NodeAnd, terms)
+}
+
+func (p *parser) parseUnary() *Node {
+	t := p.tokens[p.pos]
+	p.pos++
+
+	switch {
+	case t.kind == tokOpen:
+		n := p.parseOr()
+		if p.pos < len(p.tokens) {
+			p.pos++ // Closing ")"; a missing one is implied at the end
+		}
+		return n
+
+	case t.kind == tokNot || (t.kind == tokWord && !t.quoted && t.text == "NOT"):
+		if p.atEnd() || p.keyword("OR") {
+			// Nothing to negate: search for the operator itself
+			return p.leaf(t.text)
+		}
+		operand := p.parseUnary()
+		if operand == nil {
+			return nil
+		}
+		return &Node{Kind: NodeNot, Children: []*Node{operand}}
+	}
+
+	return p.leaf(t.text)
+}
+
+// leaf records a directive and returns its node (nil for control directives)
+func (p *parser) leaf(text string) *Node {
+	d := parseDirective(text)
+	p.directives = append(p.directives, d)
+	if d.Type == DirRecursive || d.Type == DirIgnore || d.Type == DirIndex {
+		return nil
+	}
+	return &Node{Kind: NodeDirective, Directive: d}
+}
+
+// combine joins nodes under an AND or OR, flattening nested nodes of the same kind
+func combine(kind NodeKind, nodes []*Node) *Node {
+	var children []*Node
+	for _, n := range nodes {
+		switch {
+		case n == nil:
+		case n.Kind == kind:
+			children = append(children, n.Children...)
+		default:
+			children = append(children, n)
+		}
+	}
+	switch len(children) {
+	case 0:
+		return nil
+	case 1:
+		return children[0]
+	}
+	return &Node{Kind: kind, Children: children}
+}
+
+// String formats the expression with every group parenthesized
+func (n *Node) String() string {
+	switch n.Kind {
+	case NodeNot:
+		return "-" + n.Children[0].String()
+	case NodeAnd, NodeOr:
+		sep := " "
+		if n.Kind == NodeOr {
+			sep = " OR "
+		}
+		parts := make([]string, len(n.Children))
+		for i, c := range n.Children {
+			parts[i] = c.String()
+		}
+		return "(" + strings.Join(parts, sep) + ")"
+	}
+	return n.Directive.String()
+}
+
+// Eval evaluates the expression, calling match for the directives it needs.
+// AND and OR stop as soon as the result is known, and try operands that
+// don't read file contents first.
+func (n *Node) Eval(match func(d Directive) bool) bool {
+	switch n.Kind {
+	case NodeDirective:
+		return match(n.Directive)
+	case NodeNot:
+		return !n.Children[0].Eval(match)
+	}
+
+	// OR is decided by the first true operand, AND by the first false one
+	decided := n.Kind == NodeOr
+	for _, contents := range []bool{false, true} {
+		for _, c := range n.Children {
+			if c.readsContents() == contents && c.Eval(match) == decided {
+				return decided
+			}
+		}
+	}
+	return !decided
+}
+
+// readsContents reports whether evaluating the node may read file contents
+func (n *Node) readsContents() bool {
+	if n.Kind == NodeDirective {
+		return n.Directive.Type == DirContents
+	}
+	for _, c := range n.Children {
+		if c.readsContents() {
+			return true
+		}
+	}
+	return false
+}
+
+// positiveContents appends the contents: directives a match can be due to,
+// which excludes those under a NOT
+func (n *Node) positiveContents(negated bool, out []Directive) []Directive {
+	switch n.Kind {
+	case NodeDirective:
+		if n.Directive.Type == DirContents && !negated {
+			out = append(out, n.Directive)
+		}
+	case NodeNot:
+		return n.Children[0].positiveContents(!negated, out)
+	default:
+		for _, c := range n.Children {
+			out = c.positiveContents(negated, out)
+		}
+	}
+	return out
+}
+
 func splitRespectingQuotes(s string) []string {
 	var parts []string
 	var current strings.Builder
@@ -105,7 +529,61 @@ func splitRespectingQuotes(s string) []string {
 	return parts
 }
 
+// parseDirective parses one term, including its text matching modifiers:
+//   - "case:" makes the term case-sensitive, e.g. case:contents:TODO
+//   - "re:"/"regex:" or a /pattern/ value makes it a regular expression,
+//     e.g. re:^test_.*\.go$ or contents:/func \w+Handler/
+//
+// Modifiers apply to filename: (the default), contents: and path: terms.
 func parseDirective(s string) Directive {
+	var regex, caseSensitive bool
+	term := s
+	for {
+		lower := strings.ToLower(term)
+		if strings.HasPrefix(lower, "case:") {
+			caseSensitive, term = true, term[len("case:"):]
+		} else if strings.HasPrefix(lower, "regex:") {
+			regex, term = true, term[len("regex:"):]
+		} else if strings.HasPrefix(lower, "re:") {
+			regex, term = true, term[len("re:"):]
+		} else {
+			break
+		}
+	}
+	if term == "" {
+		// A lone modifier is just a search term
+		return Directive{Type: DirFilename, Value: s}
+	}
+
+	d := parseTerm(term)
+	if d.Type != DirFilename && d.Type != DirContents && d.Type != DirPath {
+		return d
+	}
+	if len(d.Value) > 2 && strings.HasPrefix(d.Value, "/") && strings.HasSuffix(d.Value, "/") {
+		regex, d.Value = true, d.Value[1:len(d.Value)-1]
+	}
+	d.Regex, d.CaseSensitive = regex, caseSensitive
+	if regex {
+		// ripgrep and ugrep match line by line, so ^ and $ anchor lines in contents
+		expr := d.Value
+		if d.Type == DirContents {
+			expr = "(?m)" + expr
+		}
+		if !caseSensitive {
+			expr = "(?i)" + expr
+		}
+		if d.re, d.Err = regexp.Compile(expr); d.Err != nil {
+			d.Err = fmt.Errorf("invalid regular expression %q: %w", d.Value, d.Err)
+		}
+	} else if !caseSensitive && d.Type == DirContents {
+		// Compiled once here rather than for every file with hits
+		d.re = foldingRegexp(d.Value)
+	}
+	return d
+}
+
+// parseTerm parses a directive:value term (or a plain filename term)
+func parseTerm(s string) Directive {
 	// Check for directive:value pattern
 	if idx := strings.Index(s, ":"); idx > 0 {
 		directive := strings.ToLower(s[:idx])
@@ -119,7 +597,17 @@ func parseDirective(s string) Directive {
 		case "contents", "content", "text", "body":
 			return Directive{Type: DirContents, Value: value}
 
-		case "ext", "extension", "type":
+		case "type":
+			if kind, ok := fileKinds[strings.ToLower(value)]; ok {
+				return Directive{Type: DirType, Value: kind}
+			}
+			// type: was an alias of ext: before it knew file kinds
+			if !strings.HasPrefix(value, ".") {
+				value = "." + value
+			}
+			return Directive{Type: DirExt, Value: strings.ToLower(value)}
+
+		case "ext", "extension":
 			if !strings.HasPrefix(value, ".") {
 				value = "." + value
 			}
@@ -135,6 +623,30 @@ func parseDirective(s string) Directive {
 			t := parseDate(dateStr)
 			return Directive{Type: DirModified, Value: value, Operator: op, TimeVal: t}
 
+		case "created", "birth":
+			op, dateStr := parseOperator(value)
+			return Directive{Type: DirCreated, Value: value, Operator: op, TimeVal: parseDate(dateStr)}
+
+		case "accessed", "atime":
+			op, dateStr := parseOperator(value)
+			return Directive{Type: DirAccessed, Value: value, Operator: op, TimeVal: parseDate(dateStr)}
+
+		case "perm", "mode":
+			return parsePerm(value)
+
+		case "owner", "user":
+			return Directive{Type: DirOwner, Value: value}
+
+		case "group":
+			return Directive{Type: DirGroup, Value: value}
+
+		case "path":
+			return Directive{Type: DirPath, Value: filepath.ToSlash(value)}
+
+		case "empty":
+			// empty: alone means empty; empty:no means not empty
+			return Directive{Type: DirEmpty, Value: strings.ToLower(value)}
+
 		case "recursive", "recurse", "r", "depth":
 			// Parse depth value, default to 2 if not specified or invalid
 			depth := int64(2)
@@ -144,6 +656,14 @@ func parseDirective(s string) Directive {
 				}
 			}
 			return Directive{Type: DirRecursive, Value: value, NumValue: depth}
+
+		case "ignore":
+			// ignore:off searches paths excluded by .gitignore/.ignore files
+			return Directive{Type: DirIgnore, Value: strings.ToLower(value)}
+
+		case "index":
+			// index: answers the query from the filename index instead of walking
+			return Directive{Type: DirIndex, Value: strings.ToLower(value)}
 		}
 	}
 
@@ -151,6 +671,75 @@ func parseDirective(s string) Directive {
 	return Directive{Type: DirFilename, Value: s}
 }
 
+// fileKinds maps the values type: accepts to the kinds MatchInfo checks.
+// Names that are also extensions (d, f, l, x, link, exec) keep their ext:
+// meaning, so queries written before type: knew kinds still work.
+var fileKinds = map[string]string{
+	"file": "file",
+	"dir":  "dir", "directory": "dir", "folder": "dir",
+	"symlink":    "symlink",
+	"executable": "exec",
+}
+
+// parsePerm parses a perm: value: an octal mode like 755 that must match
+// exactly, or a symbolic [ugoa][+-][rwx] test like +x, u+w or o-w
+func parsePerm(value string) Directive {
+	d := Directive{Type: DirPerm, Value: value, Operator: OpEquals}
+	if n, err := strconv.ParseUint(value, 8, 32); err == nil && n <= 0o7777 {
+		d.NumValue = int64(n)
+		return d
+	}
+
+	i := strings.IndexAny(value, "+-")
+	who, what := value, ""
+	if i >= 0 {
+		who, what = value[:i], value[i+1:]
+		d.Operator = OpGreater
+		if value[i] == '-' {
+			d.Operator = OpLess
+		}
+	}
+	if who == "" {
+		who = "a"
+	}
+	var shift []uint
+	for _, c := range who {
+		switch c {
+		case 'u':
+			shift = append(shift, 6)
+		case 'g':
+			shift = append(shift, 3)
+		case 'o':
+			shift = append(shift, 0)
+		case 'a':
+			shift = append(shift, 6, 3, 0)
+		default:
+			i = -1
+		}
+	}
+	var bits int64
+	for _, c := range what {
+		switch c {
+		case 'r':
+			bits |= 4
+		case 'w':
+			bits |= 2
+		case 'x':
+			bits |= 1
+		default:
+			i = -1
+		}
+	}
+	if i < 0 || bits == 0 {
+		d.Err = fmt.Errorf("invalid permission %q", value)
+		return d
+	}
+	for _, s := range shift {
+		d.NumValue |= bits << s
+	}
+	return d
+}
+
 func parseOperator(s string) (Operator, string) {
 	s = strings.TrimSpace(s)
 	switch {
@@ -236,12 +825,16 @@ func parseDate(s string) time.Time {
 	return time.Time{}
 }
 
+// MaxContentSize is the largest file whose contents are searched
+const MaxContentSize = 10 * 1024 * 1024
+
 // Matcher evaluates files against a query
 type Matcher struct {
 	query           *Query
 	contentFunc     func(path string) (string, error)
 	ctx             context.Context
 	externalResults map[string]bool // Results from external search engine (ripgrep/ugrep)
+	root            string          // Search root that path: directives are relative to
 }
 
 // NewMatcher creates a new Matcher for the given query
@@ -290,10 +883,8 @@ func NewMatcherWithContext(ctx context.Context, q *Query) *Matcher {
 				n, err := f.Read(chunk)
 				if n > 0 {
 					// Check for binary content (null bytes) - skip binary files
-					for i := 0; i < n; i++ {
-						if chunk[i] == 0 {
-							return "", nil // Empty string means no match for binary files
-						}
+					if IsBinary(chunk[:n]) {
+						return "", nil // Empty string means no match for binary files
 					}
 					buf.Write(chunk[:n])
 				}
@@ -310,6 +901,12 @@ func NewMatcherWithContext(ctx context.Context, q *Query) *Matcher {
 	}
 }
 
+// IsBinary reports whether data looks like part of a binary file (it contains
+// a null byte). Content searches and the content index skip binary files.
+func IsBinary(data []byte) bool {
+	return bytes.IndexByte(data, 0) >= 0
+}
+
 // SetContentFunc allows setting a custom content reader (e.g., for mmap or caching)
 func (m *Matcher) SetContentFunc(f func(path string) (string, error)) {
 	m.contentFunc = f
@@ -321,26 +918,57 @@ func (m *Matcher) SetExternalResults(results map[string]bool) {
 	m.externalResults = results
 }
 
-// Match checks if a file matches all directives in the query (AND logic)
+// SetRoot sets the directory the search started from, which path:
+// directives match relative to
+func (m *Matcher) SetRoot(root string) {
+	m.root = root
+}
+
+// fileText holds a file's contents once a directive has read them, so the
+// other directives of the same match don't read the file again
+type fileText struct {
+	text string
+	err  error
+	read bool
+}
+
+// Match checks if a file satisfies the query expression
 func (m *Matcher) Match(path string, info os.FileInfo) bool {
-	if len(m.query.Directives) == 0 {
+	if m.query.Root == nil {
 		return true
 	}
+	var text fileText
+	return m.query.Root.Eval(func(d Directive) bool {
+		return m.matchDirective(d, path, info, &text)
+	})
+}
 
-	// All directives must match (implicit AND)
-	for _, d := range m.query.Directives {
-		if !m.matchDirective(d, path, info) {
-			return false
-		}
+// MatchHits is Match that also returns the lines of a matching file that
+// satisfy its contents: directives (at most MaxHitsPerFile)
+func (m *Matcher) MatchHits(path string, info os.FileInfo) (bool, []ExternalSearchResult) {
+	if m.query.Root == nil {
+		return true, nil
+	}
+	var text fileText
+	matched := m.query.Root.Eval(func(d Directive) bool {
+		return m.matchDirective(d, path, info, &text)
+	})
+	// Only files whose contents the match actually read have hits
+	if !matched || !text.read || text.err != nil {
+		return matched, nil
 	}
 
-	return true
+	var hits []ExternalSearchResult
+	for _, d := range m.query.Root.positiveContents(false, nil) {
+		hits = append(hits, FindHits(d, path, text.text, MaxHitsPerFile)...)
+	}
+	return true, mergeHits(hits)
 }
 
-func (m *Matcher) matchDirective(d Directive, path string, info os.FileInfo) bool {
+func (m *Matcher) matchDirective(d Directive, path string, info os.FileInfo, text *fileText) bool {
 	switch d.Type {
 	case DirFilename:
-		return MatchGlob(strings.ToLower(info.Name()), strings.ToLower(d.Value))
+		return d.MatchName(info.Name())
 
 	case DirContents:
 		if info.IsDir() {
@@ -356,14 +984,17 @@ func (m *Matcher) matchDirective(d Directive, path string, info os.FileInfo) boo
 		
 		// Builtin content search
 		// Skip large files (>10MB) for content search
-		if info.Size() > 10*1024*1024 {
+		if info.Size() > MaxContentSize {
 			return false
 		}
-		content, err := m.contentFunc(path)
-		if err != nil {
+		if !text.read {
+			text.text, text.err = m.contentFunc(path)
+			text.read = true
+		}
+		if text.err != nil {
 			return false
 		}
-		return strings.Contains(strings.ToLower(content), strings.ToLower(d.Value))
+		return d.MatchText(text.text)
 
 	case DirExt:
 		ext := strings.ToLower(filepath.Ext(info.Name()))
@@ -378,8 +1009,14 @@ func (m *Matcher) matchDirective(d Directive, path string, info os.FileInfo) boo
 		}
 		return CompareTime(info.ModTime(), d.TimeVal, d.Operator)
 
-	case DirRecursive:
-		// Recursive is a control directive, not a filter - always matches
+	case DirPath:
+		return d.MatchPath(RelPath(m.root, path))
+
+	case DirType, DirPerm, DirOwner, DirGroup, DirCreated, DirAccessed, DirEmpty:
+		return d.MatchInfo(path, info)
+
+	case DirRecursive, DirIgnore, DirIndex:
+		// Control directives, not filters - always match
 		return true
 	}
 
@@ -481,6 +1118,48 @@ func (q *Query) GetContentPattern() string {
 	return ""
 }
 
+// RequiredContent returns the query's contents: directive when it is the only
+// one and every match must satisfy it (it is neither negated nor inside an OR).
+// Only then can the list of files an external engine found stand in for it.
+func (q *Query) RequiredContent() (Directive, bool) {
+	count := 0
+	for _, d := range q.Directives {
+		if d.Type == DirContents {
+			count++
+		}
+	}
+	if count != 1 {
+		return Directive{}, false
+	}
+
+	for _, d := range q.RequiredDirectives() {
+		if d.Type == DirContents {
+			return d, true
+		}
+	}
+	return Directive{}, false
+}
+
+// RequiredDirectives returns the directives every result must satisfy: the
+// top-level AND terms that are plain directives (not negated or inside an OR)
+func (q *Query) RequiredDirectives() []Directive {
+	if q.Root == nil {
+		return nil
+	}
+
+	terms := []*Node{q.Root}
+	if q.Root.Kind == NodeAnd {
+		terms = q.Root.Children
+	}
+	var required []Directive
+	for _, n := range terms {
+		if n.Kind == NodeDirective {
+			required = append(required, n.Directive)
+		}
+	}
+	return required
+}
+
 // HasRecursive returns true if query includes recursive directive
 func (q *Query) HasRecursive() bool {
 	for _, d := range q.Directives {
@@ -505,6 +1184,36 @@ func (q *Query) GetRecursiveDepth(defaultDepth int) int {
 	return 1 // Not recursive, depth 1
 }
 
+// RespectIgnore reports whether the search skips paths excluded by
+// .gitignore and .ignore files: ignore:off and ignore:on override def
+func (q *Query) RespectIgnore(def bool) bool {
+	for _, d := range q.Directives {
+		if d.Type == DirIgnore {
+			switch d.Value {
+			case "off", "no", "false", "0":
+				return false
+			case "on", "yes", "true", "1":
+				return true
+			}
+		}
+	}
+	return def
+}
+
+// UseIndex reports whether the query asks for the filename index (index:)
+func (q *Query) UseIndex() bool {
+	for _, d := range q.Directives {
+		if d.Type == DirIndex {
+			switch d.Value {
+			case "off", "no", "false", "0":
+				return false
+			}
+			return true
+		}
+	}
+	return false
+}
+
 // IsEmpty returns true if query has no directives
 func (q *Query) IsEmpty() bool {
 	return len(q.Directives) == 0
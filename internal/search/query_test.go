@@ -1,6 +1,7 @@
 package search
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -216,6 +217,125 @@ func TestParse_RecursiveDirective(t *testing.T) {
 	}
 }
 
+func TestParse_IgnoreDirective(t *testing.T) {
+	testCases := []struct {
+		input string
+		def   bool
+		want  bool
+	}{
+		{"ignore:off *.go", true, false},
+		{"ignore:no", true, false},
+		{"ignore:ON", false, true},
+		{"ignore:", true, true}, // No value keeps the default
+		{"*.go", true, true},
+		{"*.go", false, false},
+	}
+
+	for _, tc := range testCases {
+		q := Parse(tc.input)
+		if got := q.RespectIgnore(tc.def); got != tc.want {
+			t.Errorf("Parse(%q).RespectIgnore(%v) = %v, want %v", tc.input, tc.def, got, tc.want)
+		}
+	}
+
+	// ignore: is a control directive, not a filter
+	if q := Parse("ignore:off"); q.Root != nil {
+		t.Errorf("ignore:off built a filter node: %+v", q.Root)
+	}
+}
+
+func TestParse_IndexDirective(t *testing.T) {
+	testCases := []struct {
+		input string
+		want  bool
+	}{
+		{"index: ext:go", true},
+		{"index:on report", true},
+		{"index:off report", false},
+		{"report", false},
+	}
+	for _, tc := range testCases {
+		if got := Parse(tc.input).UseIndex(); got != tc.want {
+			t.Errorf("Parse(%q).UseIndex() = %v, want %v", tc.input, got, tc.want)
+		}
+	}
+
+	// Only plain top-level AND terms are required of every result
+	q := Parse("index: ext:go size:>1KB (foo OR bar) -test")
+	var types []DirectiveType
+	for _, d := range q.RequiredDirectives() {
+		types = append(types, d.Type)
+	}
+	if fmt.Sprint(types) != fmt.Sprint([]DirectiveType{DirExt, DirSize}) {
+		t.Errorf("RequiredDirectives types = %v, want [ext size]", types)
+	}
+}
+
+func TestParse_FileDirectives(t *testing.T) {
+	testCases := []struct {
+		input   string
+		typ     DirectiveType
+		value   string
+		op      Operator
+		num     int64
+		wantErr bool
+	}{
+		{"type:file", DirType, "file", OpNone, 0, false},
+		{"type:directory", DirType, "dir", OpNone, 0, false},
+		{"type:symlink", DirType, "symlink", OpNone, 0, false},
+		{"type:executable", DirType, "exec", OpNone, 0, false},
+		{"type:d", DirExt, ".d", OpNone, 0, false}, // Still D source files
+		{"type:x", DirExt, ".x", OpNone, 0, false},
+		{"type:link", DirExt, ".link", OpNone, 0, false},
+		{"type:exec", DirExt, ".exec", OpNone, 0, false},
+		{"perm:755", DirPerm, "755", OpEquals, 0o755, false},
+		{"perm:0644", DirPerm, "0644", OpEquals, 0o644, false},
+		{"perm:+x", DirPerm, "+x", OpGreater, 0o111, false},
+		{"perm:u+rw", DirPerm, "u+rw", OpGreater, 0o600, false},
+		{"perm:go-w", DirPerm, "go-w", OpLess, 0o022, false},
+		{"perm:+q", DirPerm, "+q", OpGreater, 0, true},
+		{"perm:999", DirPerm, "999", OpEquals, 0, true},
+		{"owner:root", DirOwner, "root", OpNone, 0, false},
+		{"user:1000", DirOwner, "1000", OpNone, 0, false},
+		{"group:staff", DirGroup, "staff", OpNone, 0, false},
+		{"path:src/*.go", DirPath, "src/*.go", OpNone, 0, false},
+		{"created:>2024-01-01", DirCreated, ">2024-01-01", OpGreater, 0, false},
+		{"accessed:<today", DirAccessed, "<today", OpLess, 0, false},
+		{"empty:", DirEmpty, "", OpNone, 0, false},
+		{"empty:NO", DirEmpty, "no", OpNone, 0, false},
+	}
+	for _, tc := range testCases {
+		q := Parse(tc.input)
+		if len(q.Directives) != 1 {
+			t.Fatalf("input %q: expected 1 directive, got %d", tc.input, len(q.Directives))
+		}
+		d := q.Directives[0]
+		if d.Type != tc.typ || d.Value != tc.value || d.Operator != tc.op {
+			t.Errorf("input %q: got type %d value %q op %d, want %d %q %d", tc.input, d.Type, d.Value, d.Operator, tc.typ, tc.value, tc.op)
+		}
+		if (d.Err != nil) != tc.wantErr {
+			t.Errorf("input %q: err = %v, want error %v", tc.input, d.Err, tc.wantErr)
+		}
+		if !tc.wantErr && tc.typ == DirPerm && d.NumValue != tc.num {
+			t.Errorf("input %q: bits = %o, want %o", tc.input, d.NumValue, tc.num)
+		}
+	}
+
+	// ctime: is the inode change time elsewhere, not the birth time created: matches
+	if d := Parse("ctime:>2024-01-01").Directives[0]; d.Type == DirCreated {
+		t.Errorf("ctime: parsed as created: %+v", d)
+	}
+
+	// path: takes the text modifiers
+	d := Parse("case:path:/^src/.+_test\\.go$/").Directives[0]
+	if !d.Regex || !d.CaseSensitive || !d.MatchPath("src/a_test.go") || d.MatchPath("SRC/a_test.go") {
+		t.Errorf("case:path:/regex/ = %+v", d)
+	}
+	if got := RelPath(filepath.Join("a", "b"), filepath.Join("a", "b", "c", "d.txt")); got != "c/d.txt" {
+		t.Errorf("RelPath = %q, want c/d.txt", got)
+	}
+}
+
 func TestParse_MultipleDirectives(t *testing.T) {
 	q := Parse("*.go contents:func ext:go size:>1KB")
 	if len(q.Directives) != 4 {
@@ -582,3 +702,247 @@ func TestMatcher_Match(t *testing.T) {
 		}
 	}
 }
+
+func TestParse_BooleanPrecedence(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"a b", "(a b)"},
+		{"a OR b", "(a OR b)"},
+		{"a b OR c", "((a b) OR c)"},
+		{"a OR b c", "(a OR (b c))"},
+		{"a AND b OR c AND d", "((a b) OR (c d))"},
+		{"(a OR b) c", "((a OR b) c)"},
+		{"a (b OR (c OR d))", "(a (b OR c OR d))"},
+		{"-a b", "(-a b)"},
+		{"NOT a OR b", "(-a OR b)"},
+		{"-(a OR b)", "-(a OR b)"},
+		{"NOT NOT a", "--a"},
+		{"ext:go OR ext:rs", "(ext:go OR ext:rs)"},
+		{"-contents:TODO size:>1KB", "(-contents:TODO size:>1KB)"},
+		{"(a OR b", "(a OR b)"},
+		{"a recursive:3 OR b", "(a OR b)"},
+	}
+
+	for _, tc := range testCases {
+		q := Parse(tc.input)
+		if q.Root == nil {
+			t.Fatalf("input %q: expected an expression, got none", tc.input)
+		}
+		if got := q.Root.String(); got != tc.expected {
+			t.Errorf("input %q: expected %s, got %s", tc.input, tc.expected, got)
+		}
+	}
+}
+
+func TestParse_BooleanLiterals(t *testing.T) {
+	// Operators that can't apply, quoted keywords and parentheses inside names are search terms
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"file(1).txt", "file(1).txt"},
+		{`"OR"`, "OR"},
+		{"or", "or"},
+		{"a -", "(a -)"},
+		{"NOT", "NOT"},
+		{`"-v"`, `"-v"`},
+		{`"a OR b"`, `"a OR b"`},
+	}
+
+	for _, tc := range testCases {
+		q := Parse(tc.input)
+		if q.Root == nil {
+			t.Fatalf("input %q: expected an expression, got none", tc.input)
+		}
+		if got := q.Root.String(); got != tc.expected {
+			t.Errorf("input %q: expected %s, got %s", tc.input, tc.expected, got)
+		}
+	}
+
+	if q := Parse("recursive:2"); q.Root != nil || !q.HasRecursive() {
+		t.Errorf("recursive: should be a control directive outside the expression, got root %v", q.Root)
+	}
+}
+
+func TestQuery_RequiredContent(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"contents:foo", "foo"},
+		{"contents:foo ext:go", "foo"},
+		{"contents:foo (ext:go OR ext:rs)", "foo"},
+		{"contents:foo OR ext:go", ""},
+		{"-contents:foo", ""},
+		{"contents:foo contents:bar", ""},
+		{"ext:go", ""},
+	}
+
+	for _, tc := range testCases {
+		q := Parse(tc.input)
+		d, ok := q.RequiredContent()
+		if ok != (tc.expected != "") || d.Value != tc.expected {
+			t.Errorf("Parse(%q).RequiredContent(): expected %q, got %q (%v)", tc.input, tc.expected, d.Value, ok)
+		}
+	}
+}
+
+func TestMatcher_MatchBoolean(t *testing.T) {
+	tmpDir := t.TempDir()
+	goFile := filepath.Join(tmpDir, "main.go")
+	rsFile := filepath.Join(tmpDir, "lib.rs")
+	vendorFile := filepath.Join(tmpDir, "vendor_util.go")
+	for path, content := range map[string]string{goFile: "TODO", rsFile: "fn main() {}", vendorFile: "package util"} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		query    string
+		path     string
+		expected bool
+	}{
+		{"ext:go OR ext:rs", goFile, true},
+		{"ext:go OR ext:rs", rsFile, true},
+		{"ext:go -vendor", vendorFile, false},
+		{"ext:go NOT vendor", goFile, true},
+		{"-(ext:go OR ext:rs)", rsFile, false},
+		{"main ext:rs OR vendor", goFile, false},
+		{"main ext:rs OR vendor", rsFile, false},
+		{"main (ext:rs OR ext:go)", goFile, true},
+		{"contents:TODO OR ext:rs", goFile, true},
+		{"contents:TODO OR ext:rs", vendorFile, false},
+		{"-contents:TODO ext:go", vendorFile, true},
+	}
+
+	for _, tc := range testCases {
+		m := NewMatcher(Parse(tc.query))
+		info, err := os.Stat(tc.path)
+		if err != nil {
+			t.Fatalf("could not stat %s: %v", tc.path, err)
+		}
+		if got := m.Match(tc.path, info); got != tc.expected {
+			t.Errorf("Match(%q, %s): expected %v, got %v", tc.query, filepath.Base(tc.path), tc.expected, got)
+		}
+	}
+}
+
+func TestParse_TextModes(t *testing.T) {
+	testCases := []struct {
+		input         string
+		typ           DirectiveType
+		value         string
+		regex         bool
+		caseSensitive bool
+	}{
+		{"foo", DirFilename, "foo", false, false},
+		{"re:^test_", DirFilename, "^test_", true, false},
+		{"regex:^test_", DirFilename, "^test_", true, false},
+		{"/^test_/", DirFilename, "^test_", true, false},
+		{"case:Makefile", DirFilename, "Makefile", false, true},
+		{"case:re:^Make", DirFilename, "^Make", true, true},
+		{`contents:"/func \w+/"`, DirContents, "func \\w+", true, false},
+		{"re:contents:TODO|FIXME", DirContents, "TODO|FIXME", true, false},
+		{"case:contents:TODO", DirContents, "TODO", false, true},
+		{"case:", DirFilename, "case:", false, false},
+		{"/", DirFilename, "/", false, false},
+	}
+
+	for _, tc := range testCases {
+		q := Parse(tc.input)
+		if q.Err != nil {
+			t.Fatalf("input %q: unexpected error %v", tc.input, q.Err)
+		}
+		d := q.Directives[0]
+		if d.Type != tc.typ || d.Value != tc.value || d.Regex != tc.regex || d.CaseSensitive != tc.caseSensitive {
+			t.Errorf("input %q: got type=%d value=%q regex=%v case=%v, want type=%d value=%q regex=%v case=%v",
+				tc.input, d.Type, d.Value, d.Regex, d.CaseSensitive, tc.typ, tc.value, tc.regex, tc.caseSensitive)
+		}
+	}
+
+	if q := Parse("contents:/(unclosed/"); q.Err == nil {
+		t.Error("expected an error for an invalid regular expression")
+	}
+}
+
+func TestMatcher_MatchTextModes(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "Makefile")
+	if err := os.WriteFile(path, []byte("build:\n\tgo build ./...\n# TODO: tests\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		query    string
+		expected bool
+	}{
+		{"makefile", true},
+		{"case:makefile", false},
+		{"case:Makefile", true},
+		{"re:^make", true},
+		{"case:re:^make", false},
+		{"/file$/", true},
+		{"contents:todo", true},
+		{"case:contents:todo", false},
+		{"case:contents:TODO", true},
+		{`contents:"/go build \./\.\./"`, true},
+		{"contents:/^#\\s+todo$/", false}, // $ anchors the end of the line
+		{"contents:/^#\\s+todo/", true},   // ^ anchors a line, like ripgrep and ugrep
+		{"contents:go.build", false},      // Plain values are literal text
+		{"contents:/go.build/", true},
+	}
+
+	for _, tc := range testCases {
+		m := NewMatcher(Parse(tc.query))
+		if got := m.Match(path, info); got != tc.expected {
+			t.Errorf("Match(%q): expected %v, got %v", tc.query, tc.expected, got)
+		}
+	}
+}
+
+func TestMatcher_MatchHits(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "main.go")
+	content := "package main\n\n// TODO: flags\nfunc main() {\n\t// todo: tests\n}\n// TODO: docs\n// TODO: more\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		query   string
+		matched bool
+		lines   []int
+	}{
+		{"contents:todo", true, []int{3, 5, 7}}, // Capped at MaxHitsPerFile
+		{"case:contents:todo", true, []int{5}},
+		{"contents:func contents:package", true, []int{1, 4}},
+		{"ext:go -contents:missing", true, nil}, // Negated terms give no hits
+		{"main OR contents:missing", true, nil}, // Contents not read for the match
+		{"contents:missing", false, nil},
+	}
+
+	for _, tc := range testCases {
+		matched, hits := NewMatcher(Parse(tc.query)).MatchHits(path, info)
+		if matched != tc.matched {
+			t.Errorf("MatchHits(%q): matched = %v, want %v", tc.query, matched, tc.matched)
+		}
+		var lines []int
+		for _, h := range hits {
+			lines = append(lines, h.Line)
+		}
+		if fmt.Sprint(lines) != fmt.Sprint(tc.lines) {
+			t.Errorf("MatchHits(%q): lines %v, want %v", tc.query, lines, tc.lines)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package store
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/justyntemme/razor/internal/debug"
+)
+
+// ContentDoc is one file to record in the content index. Binary files are
+// recorded without a Body so unchanged ones aren't read again on rescans.
+type ContentDoc struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+	Binary  bool
+	Body    string
+}
+
+// ContentFile is the state of a file when it was last indexed
+type ContentFile struct {
+	Size    int64
+	ModTime time.Time
+}
+
+// ContentIndex is the full-text index of file contents in razor.db. It uses
+// SQLite FTS5 with the trigram tokenizer, so any substring of three or more
+// characters can be looked up ignoring case, like a contents: search. Like
+// FileIndex it is used directly by its indexer and the search goroutine.
+type ContentIndex struct {
+	conn *sql.DB
+}
+
+// ContentIndex returns the content index of an opened database
+func (d *DB) ContentIndex() *ContentIndex {
+	return &ContentIndex{conn: d.conn}
+}
+
+// Known returns the indexed files below dir, down to maxDepth levels (0 for no limit)
+func (x *ContentIndex) Known(dir string, maxDepth int) (map[string]ContentFile, error) {
+	prefix, upper := pathRange(dir)
+	maxAbs := depthLimit(dir, maxDepth)
+
+	rows, err := x.conn.Query(`
+		SELECT path, size, mtime FROM content_files WHERE path >= ? AND path < ? AND depth <= ?
+	`, prefix, upper, maxAbs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	known := make(map[string]ContentFile)
+	for rows.Next() {
+		var path string
+		var f ContentFile
+		var mtime int64
+		if err := rows.Scan(&path, &f.Size, &mtime); err != nil {
+			return nil, err
+		}
+		f.ModTime = time.Unix(0, mtime)
+		known[path] = f
+	}
+	return known, rows.Err()
+}
+
+// Touch records that unchanged files were seen in scan generation gen
+func (x *ContentIndex) Touch(paths []string, gen int64) error {
+	for len(paths) > 0 {
+		n := min(len(paths), indexBatchSize)
+		tx, err := x.conn.Begin()
+		if err != nil {
+			return err
+		}
+		for _, path := range paths[:n] {
+			if _, err := tx.Exec("UPDATE content_files SET gen = ? WHERE path = ?", gen, path); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		paths = paths[n:]
+	}
+	return nil
+}
+
+// Put indexes new or changed files in scan generation gen
+func (x *ContentIndex) Put(docs []ContentDoc, gen int64) error {
+	tx, err := x.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, doc := range docs {
+		var id int64
+		err := tx.QueryRow("SELECT id FROM content_files WHERE path = ?", doc.Path).Scan(&id)
+		switch {
+		case err == sql.ErrNoRows:
+			res, err := tx.Exec(`
+				INSERT INTO content_files (path, depth, size, mtime, gen) VALUES (?, ?, ?, ?, ?)
+			`, doc.Path, pathDepth(doc.Path), doc.Size, doc.ModTime.UnixNano(), gen)
+			if err != nil {
+				return err
+			}
+			if id, err = res.LastInsertId(); err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		default:
+			if _, err := tx.Exec("UPDATE content_files SET size = ?, mtime = ?, gen = ? WHERE id = ?",
+				doc.Size, doc.ModTime.UnixNano(), gen, id); err != nil {
+				return err
+			}
+			if _, err := tx.Exec("DELETE FROM content_fts WHERE rowid = ?", id); err != nil {
+				return err
+			}
+		}
+		if !doc.Binary {
+			if _, err := tx.Exec("INSERT INTO content_fts (rowid, body) VALUES (?, ?)", id, doc.Body); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// Sweep deletes the files below dir, down to maxDepth levels (0 for no
+// limit), that were not seen in scan generation gen
+func (x *ContentIndex) Sweep(dir string, maxDepth int, gen int64) error {
+	prefix, upper := pathRange(dir)
+	maxAbs := depthLimit(dir, maxDepth)
+
+	tx, err := x.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	const stale = "FROM content_files WHERE path >= ? AND path < ? AND depth <= ? AND gen < ?"
+	if _, err := tx.Exec("DELETE FROM content_fts WHERE rowid IN (SELECT id "+stale+")", prefix, upper, maxAbs, gen); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE "+stale, prefix, upper, maxAbs, gen); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SetScanned records that a full scan of root finished
+func (x *ContentIndex) SetScanned(root string, t time.Time) error {
+	_, err := x.conn.Exec(`
+		INSERT INTO content_roots (path, scanned) VALUES (?, ?)
+		ON CONFLICT(path) DO UPDATE SET scanned = excluded.scanned
+	`, root, t.Unix())
+	return err
+}
+
+// Scanned returns when root was last fully scanned (zero if never)
+func (x *ContentIndex) Scanned(root string) time.Time {
+	var ts int64
+	if err := x.conn.QueryRow("SELECT scanned FROM content_roots WHERE path = ?", root).Scan(&ts); err != nil {
+		return time.Time{}
+	}
+	return time.Unix(ts, 0)
+}
+
+// Query returns the indexed files below root, down to maxDepth levels (0 for
+// no limit), whose contents contain text ignoring case. text needs at least
+// three characters for the trigram index to find anything.
+func (x *ContentIndex) Query(root string, maxDepth int, text string) ([]string, error) {
+	prefix, upper := pathRange(root)
+	maxAbs := depthLimit(root, maxDepth)
+
+	// A quoted FTS5 string is a phrase, which the trigram tokenizer matches as a substring
+	phrase := `"` + strings.ReplaceAll(text, `"`, `""`) + `"`
+	debug.Log(debug.STORE, "ContentIndex.Query: root=%q depth=%d match=%s", root, maxDepth, phrase)
+
+	rows, err := x.conn.Query(`
+		SELECT f.path FROM content_fts
+		JOIN content_files f ON f.id = content_fts.rowid
+		WHERE content_fts MATCH ? AND f.path >= ? AND f.path < ? AND f.depth <= ?
+	`, phrase, prefix, upper, maxAbs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, rows.Err()
+}
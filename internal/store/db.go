@@ -20,6 +20,11 @@ const (
 	// Recent files operations
 	AddRecentFile
 	FetchRecentFiles
+	// Undo/redo journal operations
+	AddJournalEntry
+	SetJournalUndone
+	DeleteJournalEntry
+	FetchJournal
 )
 
 // File permission constant
@@ -42,15 +47,17 @@ type RecentFileEntry struct {
 
 type Request struct {
 	Op    EventType
-	Path  string // For recent files
-	Query string // For search history
-	Limit int    // For search history/recent files limit
+	Path  string       // For recent files
+	Query string       // For search history
+	Limit int          // For search history/recent files limit
+	Entry JournalEntry // For journal operations
 }
 
 type Response struct {
 	Op            EventType
 	SearchHistory []SearchHistoryEntry
 	RecentFiles   []RecentFileEntry
+	Journal       []JournalEntry
 	Err           error
 }
 
@@ -75,7 +82,8 @@ func (d *DB) Open(dbPath string) error {
 		return err
 	}
 
-	db, err := sql.Open("sqlite", dbPath)
+	// The filename indexer writes from its own goroutine, so wait for locks instead of failing
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)")
 	if err != nil {
 		debug.Log(debug.STORE, "Failed to open db: %v", err)
 		return err
@@ -88,8 +96,8 @@ func (d *DB) Open(dbPath string) error {
 		}
 	}
 
-	// Database schema: only history tables (search history and recent files)
-	// User settings and favorites are stored in config.json
+	// Database schema: history tables (search history, recent files, file operation journal)
+	// and the optional filename and content indexes. User settings and favorites are stored in config.json
 	schema := `
 		CREATE TABLE IF NOT EXISTS search_history (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -106,6 +114,43 @@ func (d *DB) Open(dbPath string) error {
 			timestamp DATETIME DEFAULT CURRENT_TIMESTAMP
 		);
 		CREATE INDEX IF NOT EXISTS idx_recent_files_timestamp ON recent_files(timestamp DESC);
+		CREATE TABLE IF NOT EXISTS file_journal (
+			id INTEGER PRIMARY KEY,
+			label TEXT NOT NULL,
+			steps TEXT NOT NULL,
+			undone INTEGER NOT NULL DEFAULT 0,
+			timestamp DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TABLE IF NOT EXISTS file_index (
+			path TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			parent TEXT NOT NULL,
+			depth INTEGER NOT NULL,
+			is_dir INTEGER NOT NULL DEFAULT 0,
+			size INTEGER NOT NULL DEFAULT 0,
+			mtime INTEGER NOT NULL DEFAULT 0,
+			gen INTEGER NOT NULL DEFAULT 0
+		);
+		CREATE INDEX IF NOT EXISTS idx_file_index_parent ON file_index(parent);
+		CREATE TABLE IF NOT EXISTS index_roots (
+			path TEXT PRIMARY KEY,
+			scanned INTEGER NOT NULL DEFAULT 0
+		);
+		CREATE TABLE IF NOT EXISTS content_files (
+			id INTEGER PRIMARY KEY,
+			path TEXT NOT NULL UNIQUE,
+			depth INTEGER NOT NULL,
+			size INTEGER NOT NULL DEFAULT 0,
+			mtime INTEGER NOT NULL DEFAULT 0,
+			gen INTEGER NOT NULL DEFAULT 0
+		);
+		CREATE VIRTUAL TABLE IF NOT EXISTS content_fts USING fts5(
+			body, tokenize = 'trigram', content = '', contentless_delete = 1
+		);
+		CREATE TABLE IF NOT EXISTS content_roots (
+			path TEXT PRIMARY KEY,
+			scanned INTEGER NOT NULL DEFAULT 0
+		);
 	`
 	if _, err := db.Exec(schema); err != nil {
 		debug.Log(debug.STORE, "Failed to create schema: %v", err)
@@ -131,6 +176,14 @@ func (d *DB) Start() {
 			d.addRecentFile(req.Path)
 		case FetchRecentFiles:
 			d.fetchRecentFiles(req.Limit)
+		case AddJournalEntry:
+			d.addJournalEntry(req.Entry)
+		case SetJournalUndone:
+			d.setJournalUndone(req.Entry.ID, req.Entry.Undone)
+		case DeleteJournalEntry:
+			d.deleteJournalEntry(req.Entry.ID)
+		case FetchJournal:
+			d.fetchJournal()
 		}
 	}
 }
@@ -0,0 +1,263 @@
+package store
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/justyntemme/razor/internal/debug"
+)
+
+// indexBatchSize is the number of rows written per transaction while indexing,
+// so searches and history writes aren't locked out for a whole scan
+const indexBatchSize = 1000
+
+// IndexEntry is one file or directory in the filename index
+type IndexEntry struct {
+	Path    string
+	Name    string
+	IsDir   bool
+	Size    int64
+	ModTime time.Time
+}
+
+// IndexQuery narrows an index lookup in SQL. It can only exclude entries, so
+// callers re-check the results against the full search query.
+type IndexQuery struct {
+	Root     string   // Only entries below Root
+	MaxDepth int      // Levels below Root to include, 0 for no limit
+	Names    []string // Names contain each of these, ignoring ASCII case
+	Suffixes []string // Names end with each of these, ignoring ASCII case
+
+	MinSize        int64     // Inclusive lower bound on size
+	MaxSize        int64     // Inclusive upper bound on size, negative for none
+	ModifiedAfter  time.Time // Inclusive lower bound on mtime, zero for none
+	ModifiedBefore time.Time // Inclusive upper bound on mtime, zero for none
+}
+
+// FileIndex is the persistent filename index in razor.db. Unlike the rest of
+// the store it is used directly by the indexer and the search goroutine rather
+// than through RequestChan, since scans and lookups stream large result sets.
+type FileIndex struct {
+	conn *sql.DB
+}
+
+// FileIndex returns the filename index of an opened database
+func (d *DB) FileIndex() *FileIndex {
+	return &FileIndex{conn: d.conn}
+}
+
+// pathDepth is the number of separators in a clean absolute path
+func pathDepth(path string) int {
+	if path == string(filepath.Separator) || filepath.Dir(path) == path {
+		return 0
+	}
+	return strings.Count(strings.TrimRight(path, string(filepath.Separator)), string(filepath.Separator))
+}
+
+// depthLimit returns the largest depth column value within maxDepth levels
+// below dir (0 for no limit)
+func depthLimit(dir string, maxDepth int) int {
+	if maxDepth <= 0 {
+		return 1 << 30
+	}
+	return pathDepth(dir) + maxDepth
+}
+
+// pathRange returns bounds such that prefix <= p < upper holds exactly for the
+// paths strictly inside dir, so lookups use the primary key
+func pathRange(dir string) (prefix, upper string) {
+	prefix = dir
+	if !strings.HasSuffix(prefix, string(filepath.Separator)) {
+		prefix += string(filepath.Separator)
+	}
+	return prefix, prefix[:len(prefix)-1] + string(rune(filepath.Separator+1))
+}
+
+// likeEscape escapes the LIKE wildcards in s for use with ESCAPE '\'
+func likeEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// Upsert records entries as present in scan generation gen
+func (x *FileIndex) Upsert(entries []IndexEntry, gen int64) error {
+	for len(entries) > 0 {
+		n := min(len(entries), indexBatchSize)
+		if err := x.upsertBatch(entries[:n], gen); err != nil {
+			return err
+		}
+		entries = entries[n:]
+	}
+	return nil
+}
+
+func (x *FileIndex) upsertBatch(entries []IndexEntry, gen int64) error {
+	tx, err := x.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO file_index (path, name, parent, depth, is_dir, size, mtime, gen)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(path) DO UPDATE SET
+			is_dir = excluded.is_dir, size = excluded.size, mtime = excluded.mtime, gen = excluded.gen
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, e := range entries {
+		isDir := 0
+		if e.IsDir {
+			isDir = 1
+		}
+		if _, err := stmt.Exec(e.Path, e.Name, filepath.Dir(e.Path), pathDepth(e.Path),
+			isDir, e.Size, e.ModTime.UnixNano(), gen); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Sweep deletes the entries below dir, down to maxDepth levels (0 for no
+// limit), that were not seen in scan generation gen, along with everything
+// inside directories that disappeared
+func (x *FileIndex) Sweep(dir string, maxDepth int, gen int64) error {
+	prefix, upper := pathRange(dir)
+	maxAbs := depthLimit(dir, maxDepth)
+
+	rows, err := x.conn.Query(`
+		SELECT path FROM file_index
+		WHERE path >= ? AND path < ? AND depth <= ? AND gen < ? AND is_dir = 1
+	`, prefix, upper, maxAbs, gen)
+	if err != nil {
+		return err
+	}
+	var goneDirs []string
+	for rows.Next() {
+		var path string
+		if rows.Scan(&path) == nil {
+			goneDirs = append(goneDirs, path)
+		}
+	}
+	rows.Close()
+
+	if _, err := x.conn.Exec(`
+		DELETE FROM file_index WHERE path >= ? AND path < ? AND depth <= ? AND gen < ?
+	`, prefix, upper, maxAbs, gen); err != nil {
+		return err
+	}
+	for _, gone := range goneDirs {
+		if err := x.Remove(gone); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Remove deletes everything inside dir from the index
+func (x *FileIndex) Remove(dir string) error {
+	prefix, upper := pathRange(dir)
+	_, err := x.conn.Exec("DELETE FROM file_index WHERE path >= ? AND path < ?", prefix, upper)
+	return err
+}
+
+// ChildDirs returns the directories directly inside dir
+func (x *FileIndex) ChildDirs(dir string) (map[string]bool, error) {
+	rows, err := x.conn.Query("SELECT path FROM file_index WHERE parent = ? AND is_dir = 1", dir)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dirs := make(map[string]bool)
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err == nil {
+			dirs[path] = true
+		}
+	}
+	return dirs, rows.Err()
+}
+
+// SetScanned records that a full scan of root finished
+func (x *FileIndex) SetScanned(root string, t time.Time) error {
+	_, err := x.conn.Exec(`
+		INSERT INTO index_roots (path, scanned) VALUES (?, ?)
+		ON CONFLICT(path) DO UPDATE SET scanned = excluded.scanned
+	`, root, t.Unix())
+	return err
+}
+
+// Scanned returns when root was last fully scanned (zero if never)
+func (x *FileIndex) Scanned(root string) time.Time {
+	var ts int64
+	if err := x.conn.QueryRow("SELECT scanned FROM index_roots WHERE path = ?", root).Scan(&ts); err != nil {
+		return time.Time{}
+	}
+	return time.Unix(ts, 0)
+}
+
+// Query calls fn for every indexed entry satisfying q, stopping at the first error
+func (x *FileIndex) Query(q IndexQuery, fn func(IndexEntry) error) error {
+	prefix, upper := pathRange(q.Root)
+	where := []string{"path >= ?", "path < ?"}
+	args := []any{prefix, upper}
+
+	if q.MaxDepth > 0 {
+		where = append(where, "depth <= ?")
+		args = append(args, pathDepth(q.Root)+q.MaxDepth)
+	}
+	for _, name := range q.Names {
+		where = append(where, `name LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+likeEscape(name)+"%")
+	}
+	for _, suffix := range q.Suffixes {
+		where = append(where, `name LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+likeEscape(suffix))
+	}
+	if q.MinSize > 0 {
+		where = append(where, "size >= ?")
+		args = append(args, q.MinSize)
+	}
+	if q.MaxSize >= 0 {
+		where = append(where, "size <= ?")
+		args = append(args, q.MaxSize)
+	}
+	if !q.ModifiedAfter.IsZero() {
+		where = append(where, "mtime >= ?")
+		args = append(args, q.ModifiedAfter.UnixNano())
+	}
+	if !q.ModifiedBefore.IsZero() {
+		where = append(where, "mtime <= ?")
+		args = append(args, q.ModifiedBefore.UnixNano())
+	}
+
+	sqlQuery := "SELECT path, name, is_dir, size, mtime FROM file_index WHERE " + strings.Join(where, " AND ")
+	debug.Log(debug.STORE, "FileIndex.Query: %s %v", sqlQuery, args)
+
+	rows, err := x.conn.Query(sqlQuery, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e IndexEntry
+		var isDir int
+		var mtime int64
+		if err := rows.Scan(&e.Path, &e.Name, &isDir, &e.Size, &mtime); err != nil {
+			return err
+		}
+		e.IsDir = isDir == 1
+		e.ModTime = time.Unix(0, mtime)
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,126 @@
+package store
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/justyntemme/razor/internal/debug"
+)
+
+// journalLimit is the number of file operations kept for undo/redo
+const journalLimit = 100
+
+// JournalOp identifies the kind of change a journal step made
+type JournalOp string
+
+const (
+	JournalMove   JournalOp = "move"   // Src was moved or renamed to Dst
+	JournalCopy   JournalOp = "copy"   // Src was copied to Dst
+	JournalTrash  JournalOp = "trash"  // Src was moved to the trash
+	JournalCreate JournalOp = "create" // Dst was created empty
+)
+
+// JournalStep is a single reversible change within a file operation
+type JournalStep struct {
+	Op    JournalOp `json:"op"`
+	Src   string    `json:"src,omitempty"`
+	Dst   string    `json:"dst,omitempty"`
+	IsDir bool      `json:"isDir,omitempty"`
+}
+
+// JournalEntry is one user-visible file operation (e.g. a paste of several files)
+type JournalEntry struct {
+	ID        int64 // Assigned by the caller so entries can be addressed before they are written
+	Label     string
+	Steps     []JournalStep
+	Undone    bool
+	Timestamp time.Time
+}
+
+// addJournalEntry records a new operation. Entries that were undone can no longer
+// be redone once a new operation happens, so they are dropped.
+func (d *DB) addJournalEntry(entry JournalEntry) {
+	steps, err := json.Marshal(entry.Steps)
+	if err != nil {
+		debug.Log(debug.STORE, "addJournalEntry marshal error: %v", err)
+		return
+	}
+
+	debug.Log(debug.STORE, "addJournalEntry: %d %q (%d steps)", entry.ID, entry.Label, len(entry.Steps))
+
+	if _, err := d.conn.Exec("DELETE FROM file_journal WHERE undone = 1"); err != nil {
+		debug.Log(debug.STORE, "addJournalEntry clear redo error: %v", err)
+	}
+
+	if _, err := d.conn.Exec(`
+		INSERT INTO file_journal (id, label, steps, undone, timestamp)
+		VALUES (?, ?, ?, 0, ?)
+	`, entry.ID, entry.Label, string(steps), entry.Timestamp.UTC().Format("2006-01-02 15:04:05")); err != nil {
+		debug.Log(debug.STORE, "addJournalEntry error: %v", err)
+		return
+	}
+
+	// Prune to keep only the last journalLimit entries
+	if _, err := d.conn.Exec(`
+		DELETE FROM file_journal
+		WHERE id NOT IN (
+			SELECT id FROM file_journal
+			ORDER BY id DESC
+			LIMIT ?
+		)
+	`, journalLimit); err != nil {
+		debug.Log(debug.STORE, "addJournalEntry prune error: %v", err)
+	}
+}
+
+// setJournalUndone marks an entry as undone (redo stack) or done (undo stack)
+func (d *DB) setJournalUndone(id int64, undone bool) {
+	value := 0
+	if undone {
+		value = 1
+	}
+	if _, err := d.conn.Exec("UPDATE file_journal SET undone = ? WHERE id = ?", value, id); err != nil {
+		debug.Log(debug.STORE, "setJournalUndone error: %v", err)
+	}
+}
+
+// deleteJournalEntry removes an entry that can no longer be undone or redone
+func (d *DB) deleteJournalEntry(id int64) {
+	if _, err := d.conn.Exec("DELETE FROM file_journal WHERE id = ?", id); err != nil {
+		debug.Log(debug.STORE, "deleteJournalEntry error: %v", err)
+	}
+}
+
+// fetchJournal returns all journal entries, oldest first
+func (d *DB) fetchJournal() {
+	rows, err := d.conn.Query(`
+		SELECT id, label, steps, undone, timestamp
+		FROM file_journal
+		ORDER BY id ASC
+	`)
+	if err != nil {
+		debug.Log(debug.STORE, "fetchJournal error: %v", err)
+		d.ResponseChan <- Response{Op: FetchJournal, Err: err}
+		return
+	}
+	defer rows.Close()
+
+	var entries []JournalEntry
+	for rows.Next() {
+		var entry JournalEntry
+		var steps string
+		var undone int
+		if err := rows.Scan(&entry.ID, &entry.Label, &steps, &undone, &entry.Timestamp); err != nil {
+			continue
+		}
+		if err := json.Unmarshal([]byte(steps), &entry.Steps); err != nil {
+			debug.Log(debug.STORE, "fetchJournal: skipping entry %d: %v", entry.ID, err)
+			continue
+		}
+		entry.Undone = undone == 1
+		entries = append(entries, entry)
+	}
+
+	debug.Log(debug.STORE, "fetchJournal: returning %d entries", len(entries))
+	d.ResponseChan <- Response{Op: FetchJournal, Journal: entries}
+}
@@ -0,0 +1,103 @@
+package store
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func openTestDB(t *testing.T) *DB {
+	t.Helper()
+	db := NewDB()
+	if err := db.Open(filepath.Join(t.TempDir(), "razor.db")); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(db.Close)
+	return db
+}
+
+// journal fetches the persisted journal
+func journal(t *testing.T, db *DB) []JournalEntry {
+	t.Helper()
+	db.fetchJournal()
+	resp := <-db.ResponseChan
+	if resp.Err != nil {
+		t.Fatal(resp.Err)
+	}
+	return resp.Journal
+}
+
+func journalIDs(entries []JournalEntry) string {
+	ids := ""
+	for _, e := range entries {
+		ids += fmt.Sprintf("%d", e.ID)
+		if e.Undone {
+			ids += "u"
+		}
+		ids += " "
+	}
+	return ids
+}
+
+func TestJournalRoundTrip(t *testing.T) {
+	db := openTestDB(t)
+	stamp := time.Date(2024, 1, 15, 10, 30, 45, 0, time.UTC)
+	steps := []JournalStep{
+		{Op: JournalTrash, Src: "/x/old report.txt"},
+		{Op: JournalMove, Src: "/x/a", Dst: "/y/a", IsDir: true},
+		{Op: JournalCopy, Src: "/x/b.zip/c", Dst: "/y/c"},
+		{Op: JournalCreate, Dst: "/y/new"},
+	}
+
+	// Entries come back by ID, whatever order they were written in
+	for _, id := range []int64{20, 10, 30} {
+		db.addJournalEntry(JournalEntry{ID: id, Label: fmt.Sprintf("Op %d", id), Steps: steps, Timestamp: stamp})
+	}
+	entries := journal(t, db)
+	if got := journalIDs(entries); got != "10 20 30 " {
+		t.Fatalf("journal = %s", got)
+	}
+	e := entries[0]
+	if e.Label != "Op 10" || !e.Timestamp.Equal(stamp) || !reflect.DeepEqual(e.Steps, steps) {
+		t.Errorf("entry = %+v", e)
+	}
+
+	db.setJournalUndone(30, true)
+	db.setJournalUndone(20, true)
+	if got := journalIDs(journal(t, db)); got != "10 20u 30u " {
+		t.Errorf("after undo = %s", got)
+	}
+	db.setJournalUndone(20, false)
+	if got := journalIDs(journal(t, db)); got != "10 20 30u " {
+		t.Errorf("after redo = %s", got)
+	}
+
+	// A new operation drops what could have been redone
+	db.addJournalEntry(JournalEntry{ID: 40, Label: "Op 40", Steps: steps, Timestamp: stamp})
+	if got := journalIDs(journal(t, db)); got != "10 20 40 " {
+		t.Errorf("after a new operation = %s", got)
+	}
+
+	db.deleteJournalEntry(20)
+	if got := journalIDs(journal(t, db)); got != "10 40 " {
+		t.Errorf("after delete = %s", got)
+	}
+}
+
+func TestJournalLimit(t *testing.T) {
+	db := openTestDB(t)
+	step := []JournalStep{{Op: JournalCreate, Dst: "/y/new"}}
+	for id := int64(1); id <= journalLimit+5; id++ {
+		db.addJournalEntry(JournalEntry{ID: id, Label: "Op", Steps: step, Timestamp: time.Now()})
+	}
+
+	entries := journal(t, db)
+	if len(entries) != journalLimit {
+		t.Fatalf("kept %d entries, want %d", len(entries), journalLimit)
+	}
+	if entries[0].ID != 6 || entries[len(entries)-1].ID != journalLimit+5 {
+		t.Errorf("kept entries %d to %d, want the newest", entries[0].ID, entries[len(entries)-1].ID)
+	}
+}
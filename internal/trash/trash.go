@@ -4,10 +4,16 @@
 package trash
 
 import (
+	"errors"
 	"os"
 	"time"
 )
 
+// ErrDestinationExists is returned by Restore and RestoreTo when something
+// already occupies the restore location. Callers should resolve the conflict
+// (replace, keep both, skip) and retry.
+var ErrDestinationExists = errors.New("restore destination already exists")
+
 // Item represents a file or directory in the trash
 type Item struct {
 	Name         string    // Original filename
@@ -36,6 +42,11 @@ func RestoreTo(item Item, destPath string) error {
 	return restoreTo(item, destPath)
 }
 
+// CanRestore returns true if Restore and RestoreTo are supported on this platform.
+func CanRestore() bool {
+	return canRestore()
+}
+
 // List returns all items currently in the trash.
 func List() ([]Item, error) {
 	return list()
@@ -128,6 +128,11 @@ func copyDirRecursive(src, dst string) error {
 	return nil
 }
 
+func canRestore() bool {
+	// macOS doesn't track original paths, so items can only be copied out
+	return false
+}
+
 func restore(item Item) error {
 	// macOS doesn't track original paths - restore is not supported
 	// Users should use Copy/Cut to move files out of trash
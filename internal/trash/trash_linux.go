@@ -5,17 +5,23 @@ package trash
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 )
 
 // Linux uses the freedesktop.org trash specification.
-// Trash location: ~/.local/share/Trash/
-// Structure:
+// Home trash location: ~/.local/share/Trash/
+// Files on other mounts are trashed on their own volume ("top directory" trash):
+//   - $topdir/.Trash/$uid/  - if $topdir/.Trash exists, is not a symlink and is sticky
+//   - $topdir/.Trash-$uid/  - otherwise (created on demand)
+//
+// Structure of every trash directory:
 //   - files/     - actual trashed files
 //   - info/      - .trashinfo metadata files
 //
@@ -23,6 +29,51 @@ import (
 // [Trash Info]
 // Path=/original/path/to/file
 // DeletionDate=2024-01-15T10:30:45
+//
+// In top directory trashes Path= is relative to $topdir.
+
+// trashDir is a single trash directory: either the home trash or a per-volume trash
+type trashDir struct {
+	path   string // Directory containing files/ and info/
+	topdir string // Mount point the trash belongs to (empty for the home trash)
+}
+
+func (t trashDir) filesPath() string {
+	return filepath.Join(t.path, "files")
+}
+
+func (t trashDir) infoPath() string {
+	return filepath.Join(t.path, "info")
+}
+
+// ensure creates the files/ and info/ directories if they don't exist
+func (t trashDir) ensure() error {
+	if err := os.MkdirAll(t.filesPath(), 0700); err != nil {
+		return fmt.Errorf("cannot create trash files directory: %w", err)
+	}
+	if err := os.MkdirAll(t.infoPath(), 0700); err != nil {
+		return fmt.Errorf("cannot create trash info directory: %w", err)
+	}
+	return nil
+}
+
+// originalPath converts a Path= value into an absolute path
+func (t trashDir) originalPath(infoPath string) string {
+	if t.topdir != "" && !filepath.IsAbs(infoPath) {
+		return filepath.Join(t.topdir, infoPath)
+	}
+	return infoPath
+}
+
+// infoPathFor converts an absolute path into the Path= value stored in .trashinfo
+func (t trashDir) infoPathFor(absPath string) string {
+	if t.topdir != "" {
+		if rel, err := filepath.Rel(t.topdir, absPath); err == nil && rel != ".." && !strings.HasPrefix(rel, "../") {
+			return rel
+		}
+	}
+	return absPath
+}
 
 func getPath() string {
 	// Check XDG_DATA_HOME first
@@ -37,50 +88,202 @@ func getPath() string {
 	return filepath.Join(dataHome, "Trash")
 }
 
-func getFilesPath() string {
-	return filepath.Join(getPath(), "files")
+func homeTrash() trashDir {
+	return trashDir{path: getPath()}
 }
 
-func getInfoPath() string {
-	return filepath.Join(getPath(), "info")
+// volumeTrashDirs returns the shared ($topdir/.Trash/$uid) and per-user
+// ($topdir/.Trash-$uid) trash directories of a mount point
+func volumeTrashDirs(topdir string) (shared, private trashDir) {
+	uid := strconv.Itoa(os.Getuid())
+	shared = trashDir{path: filepath.Join(topdir, ".Trash", uid), topdir: topdir}
+	private = trashDir{path: filepath.Join(topdir, ".Trash-"+uid), topdir: topdir}
+	return shared, private
 }
 
-func isAvailable() bool {
-	trashPath := getPath()
-	if trashPath == "" {
+// sharedTrashValid reports whether $topdir/.Trash may be used.
+// The spec requires it to be a real directory (not a symlink) with the sticky bit set.
+func sharedTrashValid(topdir string) bool {
+	info, err := os.Lstat(filepath.Join(topdir, ".Trash"))
+	if err != nil {
 		return false
 	}
-	// Try to create trash directories if they don't exist
-	filesPath := getFilesPath()
-	infoPath := getInfoPath()
+	return info.IsDir() && info.Mode()&os.ModeSymlink == 0 && info.Mode()&os.ModeSticky != 0
+}
 
-	if err := os.MkdirAll(filesPath, 0700); err != nil {
-		return false
+// deviceOf returns the st_dev of a path without following symlinks
+func deviceOf(path string) (uint64, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return 0, err
 	}
-	if err := os.MkdirAll(infoPath, 0700); err != nil {
-		return false
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, fmt.Errorf("cannot determine device of %s", path)
 	}
-	return true
+	return uint64(st.Dev), nil
 }
 
-func moveToTrash(path string) error {
-	// Ensure trash directories exist
-	filesPath := getFilesPath()
-	infoPath := getInfoPath()
+// existingAncestor returns path or its closest ancestor that exists
+func existingAncestor(path string) string {
+	for {
+		if _, err := os.Lstat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
 
-	if err := os.MkdirAll(filesPath, 0700); err != nil {
-		return fmt.Errorf("cannot create trash files directory: %w", err)
+// findTopdir returns the mount point containing path by walking up
+// until the device number changes
+func findTopdir(path string, dev uint64) string {
+	dir := path
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		if parentDev, err := deviceOf(parent); err != nil || parentDev != dev {
+			return dir
+		}
+		dir = parent
 	}
-	if err := os.MkdirAll(infoPath, 0700); err != nil {
-		return fmt.Errorf("cannot create trash info directory: %w", err)
+}
+
+// trashDirFor picks the trash directory for a file: the home trash if the file
+// lives on the same device, otherwise a trash in the file's own top directory.
+// This keeps moveToTrash a rename and avoids EXDEV for files on other mounts.
+func trashDirFor(absPath string) (trashDir, error) {
+	home := homeTrash()
+	fileDev, err := deviceOf(absPath)
+	if err != nil {
+		return trashDir{}, err
+	}
+	if homeDev, err := deviceOf(existingAncestor(home.path)); err == nil && homeDev == fileDev {
+		return home, home.ensure()
+	}
+
+	topdir := findTopdir(absPath, fileDev)
+	shared, private := volumeTrashDirs(topdir)
+	if sharedTrashValid(topdir) {
+		if err := shared.ensure(); err == nil {
+			return shared, nil
+		}
+	}
+
+	// $topdir/.Trash-$uid must not be a symlink or a plain file
+	if info, err := os.Lstat(private.path); err == nil && (!info.IsDir() || info.Mode()&os.ModeSymlink != 0) {
+		return trashDir{}, fmt.Errorf("invalid trash directory %s", private.path)
+	}
+	if err := private.ensure(); err != nil {
+		return trashDir{}, fmt.Errorf("cannot create trash on %s: %w", topdir, err)
+	}
+	return private, nil
+}
+
+// allTrashDirs returns the home trash followed by the trash directories of every mounted volume
+func allTrashDirs() []trashDir {
+	home := homeTrash()
+	dirs := []trashDir{home}
+	seen := map[string]bool{home.path: true}
+	for _, topdir := range mountPoints() {
+		shared, private := volumeTrashDirs(topdir)
+		if sharedTrashValid(topdir) && !seen[shared.path] {
+			seen[shared.path] = true
+			dirs = append(dirs, shared)
+		}
+		if !seen[private.path] {
+			seen[private.path] = true
+			dirs = append(dirs, private)
+		}
+	}
+	return dirs
+}
+
+// pseudoFSTypes are kernel file systems that never hold a trash
+var pseudoFSTypes = map[string]bool{
+	"proc": true, "sysfs": true, "devtmpfs": true, "devpts": true, "cgroup": true, "cgroup2": true,
+	"securityfs": true, "debugfs": true, "tracefs": true, "pstore": true, "bpf": true, "mqueue": true,
+	"hugetlbfs": true, "configfs": true, "fusectl": true, "binfmt_misc": true, "autofs": true,
+}
+
+// mountPoints returns every mounted volume that may hold a trash. Unlike the
+// sidebar's drive list, nothing under /run is left out: udisks mounts
+// removable media in /run/media/$USER.
+func mountPoints() []string {
+	file, err := os.Open("/proc/self/mounts")
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+	return parseMounts(file)
+}
+
+// parseMounts reads the mount points of a mounts table in fstab format
+func parseMounts(r io.Reader) []string {
+	var mounts []string
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || pseudoFSTypes[fields[2]] {
+			continue
+		}
+		mountPoint := unescapeMount(fields[1])
+		if !seen[mountPoint] {
+			seen[mountPoint] = true
+			mounts = append(mounts, mountPoint)
+		}
+	}
+	return mounts
+}
+
+// unescapeMount decodes the octal escapes (\040 for a space) of a mount point
+func unescapeMount(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if n, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func isAvailable() bool {
+	if getPath() == "" {
+		return false
 	}
+	// Try to create trash directories if they don't exist
+	return homeTrash().ensure() == nil
+}
 
+func moveToTrash(path string) error {
 	// Get absolute path
 	absPath, err := filepath.Abs(path)
 	if err != nil {
 		return err
 	}
 
+	// Pick the trash on the same device (and ensure its directories exist)
+	dir, err := trashDirFor(absPath)
+	if err != nil {
+		return err
+	}
+	filesPath := dir.filesPath()
+	infoPath := dir.infoPath()
+
 	// Generate unique name in trash
 	baseName := filepath.Base(absPath)
 	destName := baseName
@@ -101,7 +304,7 @@ func moveToTrash(path string) error {
 
 	// Create .trashinfo file
 	infoContent := fmt.Sprintf("[Trash Info]\nPath=%s\nDeletionDate=%s\n",
-		url.PathEscape(absPath),
+		url.PathEscape(dir.infoPathFor(absPath)),
 		time.Now().Format("2006-01-02T15:04:05"))
 
 	infoFilePath := filepath.Join(infoPath, destName+".trashinfo")
@@ -119,19 +322,69 @@ func moveToTrash(path string) error {
 	return nil
 }
 
+func canRestore() bool {
+	return true
+}
+
+// infoFileFor returns the .trashinfo path for a file in a trash "files" directory.
+// The info directory is always a sibling of the files directory.
+func infoFileFor(trashPath string) string {
+	root := filepath.Dir(filepath.Dir(trashPath))
+	return filepath.Join(root, "info", filepath.Base(trashPath)+".trashinfo")
+}
+
 func restore(item Item) error {
-	// Restore not supported - use Copy or Cut instead for consistency across platforms
-	return fmt.Errorf("restore not supported - use Copy or Cut instead")
+	if item.OriginalPath == "" {
+		return fmt.Errorf("original location of %s is unknown", item.Name)
+	}
+	return restoreTo(item, item.OriginalPath)
 }
 
 func restoreTo(item Item, destPath string) error {
-	// Restore not supported - use Copy or Cut instead for consistency across platforms
-	return fmt.Errorf("restore not supported - use Copy or Cut instead")
+	if item.TrashPath == "" {
+		return fmt.Errorf("trash path of %s is unknown", item.Name)
+	}
+
+	// Never overwrite - the caller decides how to handle conflicts
+	if _, err := os.Lstat(destPath); err == nil {
+		return fmt.Errorf("%w: %s", ErrDestinationExists, destPath)
+	}
+
+	// The original parent may have been deleted after the item was trashed
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return fmt.Errorf("cannot recreate parent directory: %w", err)
+	}
+
+	if err := os.Rename(item.TrashPath, destPath); err != nil {
+		return fmt.Errorf("cannot restore from trash: %w", err)
+	}
+
+	// Per the spec, the .trashinfo is removed once the file is out of the trash
+	os.Remove(infoFileFor(item.TrashPath)) // Ignore error
+
+	return nil
 }
 
 func list() ([]Item, error) {
-	filesPath := getFilesPath()
-	infoPath := getInfoPath()
+	var items []Item
+	for i, dir := range allTrashDirs() {
+		dirItems, err := listDir(dir)
+		if err != nil {
+			// Only the home trash is fatal; unreadable volumes are skipped
+			if i == 0 {
+				return nil, err
+			}
+			continue
+		}
+		items = append(items, dirItems...)
+	}
+	return items, nil
+}
+
+// listDir returns the items of a single trash directory
+func listDir(dir trashDir) ([]Item, error) {
+	filesPath := dir.filesPath()
+	infoPath := dir.infoPath()
 
 	entries, err := os.ReadDir(filesPath)
 	if err != nil {
@@ -162,7 +415,7 @@ func list() ([]Item, error) {
 		// Parse .trashinfo file
 		infoFilePath := filepath.Join(infoPath, entry.Name()+".trashinfo")
 		if origPath, delTime, err := parseTrashInfo(infoFilePath); err == nil {
-			item.OriginalPath = origPath
+			item.OriginalPath = dir.originalPath(origPath)
 			if !delTime.IsZero() {
 				item.DeletedAt = delTime
 			}
@@ -206,8 +459,19 @@ func parseTrashInfo(path string) (originalPath string, deletionDate time.Time, e
 }
 
 func empty() error {
-	filesPath := getFilesPath()
-	infoPath := getInfoPath()
+	var lastErr error
+	for _, dir := range allTrashDirs() {
+		if err := emptyDir(dir); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// emptyDir permanently deletes everything in a single trash directory
+func emptyDir(dir trashDir) error {
+	filesPath := dir.filesPath()
+	infoPath := dir.infoPath()
 
 	// Remove all files in files directory
 	entries, err := os.ReadDir(filesPath)
@@ -246,8 +510,7 @@ func deleteItem(item Item) error {
 	}
 
 	// Remove the .trashinfo file
-	infoFile := filepath.Join(getInfoPath(), filepath.Base(item.TrashPath)+".trashinfo")
-	os.Remove(infoFile) // Ignore error
+	os.Remove(infoFileFor(item.TrashPath)) // Ignore error
 
 	return nil
 }
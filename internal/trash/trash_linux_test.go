@@ -0,0 +1,228 @@
+//go:build linux
+
+package trash
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMoveToTrashAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(dir, "data"))
+	path := filepath.Join(dir, "work", "notes.txt")
+	os.MkdirAll(filepath.Dir(path), 0o755)
+	os.WriteFile(path, []byte("notes"), 0o644)
+
+	if err := moveToTrash(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Lstat(path); !os.IsNotExist(err) {
+		t.Fatalf("file still in place after trashing: %v", err)
+	}
+	item := findItem(t, path)
+	if filepath.Dir(item.TrashPath) != homeTrash().filesPath() {
+		t.Errorf("trashed to %s, want the home trash", item.TrashPath)
+	}
+
+	// The original location is taken again: nothing is overwritten
+	os.WriteFile(path, []byte("new notes"), 0o644)
+	if err := restore(item); !errors.Is(err, ErrDestinationExists) {
+		t.Fatalf("restore onto an existing file error = %v, want ErrDestinationExists", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new notes" {
+		t.Errorf("existing file changed to %q", data)
+	}
+	if _, err := os.Lstat(item.TrashPath); err != nil {
+		t.Fatalf("item left the trash after a refused restore: %v", err)
+	}
+
+	// Restoring elsewhere recreates missing parents and drops the .trashinfo
+	dst := filepath.Join(dir, "gone", "deeper", "notes.txt")
+	if err := restoreTo(item, dst); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(dst); err != nil || string(data) != "notes" {
+		t.Errorf("restored file = %q, %v", data, err)
+	}
+	if _, err := os.Lstat(infoFileFor(item.TrashPath)); !os.IsNotExist(err) {
+		t.Errorf(".trashinfo kept after restoring: %v", err)
+	}
+	items, err := list()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, it := range items {
+		if it.TrashPath == item.TrashPath {
+			t.Errorf("restored item still listed: %+v", it)
+		}
+	}
+}
+
+func TestMoveToTrashSameName(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(dir, "data"))
+	path := filepath.Join(dir, "report.txt")
+	for _, content := range []string{"first", "second"} {
+		os.WriteFile(path, []byte(content), 0o644)
+		if err := moveToTrash(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	items, err := listDir(homeTrash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, item := range items {
+		if item.OriginalPath != path {
+			t.Errorf("%s original path = %q, want %q", item.Name, item.OriginalPath, path)
+		}
+		names = append(names, item.Name)
+	}
+	if got := strings.Join(names, ","); got != "report.1.txt,report.txt" {
+		t.Errorf("trashed names = %s", got)
+	}
+}
+
+func TestTrashInfoPaths(t *testing.T) {
+	volume := trashDir{path: "/run/media/me/USB/.Trash-1000", topdir: "/run/media/me/USB"}
+	home := trashDir{path: "/home/me/.local/share/Trash"}
+
+	tests := []struct {
+		dir      trashDir
+		abs      string
+		infoPath string
+	}{
+		{volume, "/run/media/me/USB/photos/a.jpg", "photos/a.jpg"},
+		{volume, "/run/media/me/USB/..hidden", "..hidden"},
+		{volume, "/home/me/a.jpg", "/home/me/a.jpg"},
+		{home, "/home/me/a.jpg", "/home/me/a.jpg"},
+	}
+	for _, tt := range tests {
+		if got := tt.dir.infoPathFor(tt.abs); got != tt.infoPath {
+			t.Errorf("infoPathFor(%q) = %q, want %q", tt.abs, got, tt.infoPath)
+		}
+		if got := tt.dir.originalPath(tt.infoPath); got != tt.abs {
+			t.Errorf("originalPath(%q) = %q, want %q", tt.infoPath, got, tt.abs)
+		}
+	}
+}
+
+func TestListDirRelativePaths(t *testing.T) {
+	topdir := t.TempDir()
+	_, private := volumeTrashDirs(topdir)
+	if err := private.ensure(); err != nil {
+		t.Fatal(err)
+	}
+	infos := map[string]string{
+		"rel.txt": "Path=docs/rel%20file.txt\nDeletionDate=2024-01-15T10:30:45\n",
+		"abs.txt": "Path=/elsewhere/abs.txt\nDeletionDate=2024-01-15T10:30:45\n",
+	}
+	for name, info := range infos {
+		os.WriteFile(filepath.Join(private.filesPath(), name), nil, 0o644)
+		os.WriteFile(filepath.Join(private.infoPath(), name+".trashinfo"), []byte("[Trash Info]\n"+info), 0o600)
+	}
+
+	items, err := listDir(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"rel.txt": filepath.Join(topdir, "docs", "rel file.txt"),
+		"abs.txt": "/elsewhere/abs.txt",
+	}
+	if len(items) != len(want) {
+		t.Fatalf("got %d items, want %d", len(items), len(want))
+	}
+	for _, item := range items {
+		if item.OriginalPath != want[item.Name] {
+			t.Errorf("%s original path = %q, want %q", item.Name, item.OriginalPath, want[item.Name])
+		}
+		if item.DeletedAt.Year() != 2024 {
+			t.Errorf("%s deleted at %v", item.Name, item.DeletedAt)
+		}
+	}
+}
+
+func TestSharedTrashValid(t *testing.T) {
+	topdir := t.TempDir()
+	if sharedTrashValid(topdir) {
+		t.Error("missing .Trash counted as valid")
+	}
+
+	trash := filepath.Join(topdir, ".Trash")
+	os.Mkdir(trash, 0o777)
+	os.Chmod(trash, 0o777) // Not sticky
+	if sharedTrashValid(topdir) {
+		t.Error(".Trash without the sticky bit counted as valid")
+	}
+	os.Chmod(trash, 0o777|os.ModeSticky)
+	if !sharedTrashValid(topdir) {
+		t.Error("sticky .Trash not counted as valid")
+	}
+
+	// A symlink to a valid trash is refused
+	other := t.TempDir()
+	os.Symlink(trash, filepath.Join(other, ".Trash"))
+	if sharedTrashValid(other) {
+		t.Error("symlinked .Trash counted as valid")
+	}
+}
+
+func TestFindTopdir(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a", "b")
+	os.MkdirAll(path, 0o755)
+	dev, err := deviceOf(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	topdir := findTopdir(path, dev)
+	if rel, err := filepath.Rel(topdir, path); err != nil || strings.HasPrefix(rel, "..") {
+		t.Fatalf("topdir %s does not contain %s", topdir, path)
+	}
+	if topDev, _ := deviceOf(topdir); topDev != dev {
+		t.Errorf("topdir %s is on another device", topdir)
+	}
+	if parent := filepath.Dir(topdir); parent != topdir {
+		if parentDev, _ := deviceOf(parent); parentDev == dev {
+			t.Errorf("parent %s of topdir %s is on the same device", parent, topdir)
+		}
+	}
+}
+
+func TestParseMounts(t *testing.T) {
+	table := `sysfs /sys sysfs rw,nosuid 0 0
+proc /proc proc rw,nosuid 0 0
+/dev/nvme0n1p2 / ext4 rw,relatime 0 0
+tmpfs /run tmpfs rw,nosuid 0 0
+/dev/sdb1 /run/media/me/USB\040STICK vfat rw,nosuid 0 0
+/dev/sdc1 /mnt/backup ext4 rw 0 0
+/dev/sdc1 /mnt/backup ext4 rw 0 0
+`
+	got := strings.Join(parseMounts(strings.NewReader(table)), ",")
+	if want := "/,/run,/run/media/me/USB STICK,/mnt/backup"; got != want {
+		t.Errorf("parseMounts = %s, want %s", got, want)
+	}
+}
+
+// findItem returns the trashed item that came from path
+func findItem(t *testing.T, path string) Item {
+	t.Helper()
+	items, err := list()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range items {
+		if item.OriginalPath == path {
+			return item
+		}
+	}
+	t.Fatalf("%s not found in the trash", path)
+	return Item{}
+}
@@ -97,6 +97,11 @@ func moveToTrash(path string) error {
 	return nil
 }
 
+func canRestore() bool {
+	// The Recycle Bin has no direct restore API; restoring is done via Explorer
+	return false
+}
+
 func restore(item Item) error {
 	// Windows Shell API doesn't provide a direct restore API
 	// We would need to use IShellItem2::InvokeVerb("undelete")
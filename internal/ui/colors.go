@@ -22,6 +22,7 @@ var (
 	colAccent    = color.NRGBA{R: 66, G: 133, B: 244, A: 255}
 	colDirective = color.NRGBA{R: 103, G: 58, B: 183, A: 255}   // Purple for directives
 	colDirectiveBg = color.NRGBA{R: 237, G: 231, B: 246, A: 255} // Light purple background
+	colMatchBg     = color.NRGBA{R: 255, G: 236, B: 153, A: 255} // Yellow behind content-search matches
 	// Config error banner colors
 	colErrorBannerBg   = color.NRGBA{R: 220, G: 53, B: 69, A: 255}   // Red background
 	colErrorBannerText = color.NRGBA{R: 139, G: 69, B: 0, A: 255}    // Dark orange text (readable on red)
@@ -102,7 +102,7 @@ func (r *Renderer) Layout(gtx layout.Context, state *State) UIEvent {
 				r.multiSelectMode = false // Exit multi-select mode
 				r.lastClickIndex = -1 // Clear click tracking
 				r.lastClickTime = time.Time{}
-				if !r.settingsOpen && !r.deleteConfirmOpen && !r.createDialogOpen && !state.Conflict.Active {
+				if !r.settingsOpen && !r.deleteConfirmOpen && !r.createDialogOpen && !r.saveSearchOpen && !r.compressOpen && !r.batchRenameOpen && !state.Conflict.Active && !state.Report.Active {
 					eventOut = UIEvent{Action: ActionClearSelection}
 					gtx.Execute(key.FocusCmd{Tag: keyTag})
 				}
@@ -205,8 +205,7 @@ func (r *Renderer) Layout(gtx layout.Context, state *State) UIEvent {
 						children = append(children,
 							// File list takes remaining space
 							layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
-								r.fileListOffset = image.Pt(horizontalOffset, verticalOffset)
-								return r.layoutFileList(gtx, state, keyTag, &eventOut)
+								return r.layoutFileArea(gtx, state, keyTag, &eventOut, image.Pt(horizontalOffset, verticalOffset))
 							}),
 							// Resize handle (draggable divider)
 							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
@@ -228,8 +227,7 @@ func (r *Renderer) Layout(gtx layout.Context, state *State) UIEvent {
 					} else {
 						children = append(children,
 							layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
-								r.fileListOffset = image.Pt(horizontalOffset, verticalOffset)
-								return r.layoutFileList(gtx, state, keyTag, &eventOut)
+								return r.layoutFileArea(gtx, state, keyTag, &eventOut, image.Pt(horizontalOffset, verticalOffset))
 							}),
 						)
 					}
@@ -237,20 +235,29 @@ func (r *Renderer) Layout(gtx layout.Context, state *State) UIEvent {
 					return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, children...)
 				}),
 
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return r.layoutJobsPanel(gtx, state, &eventOut)
+				}),
+
 				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 					return r.layoutProgressBar(gtx, state)
 				}),
 			)
 		}),
 
-		layout.Stacked(func(gtx layout.Context) layout.Dimensions { return r.layoutFileMenu(gtx, &eventOut) }),
+		layout.Stacked(func(gtx layout.Context) layout.Dimensions { return r.layoutFileMenu(gtx, state, &eventOut) }),
 		layout.Stacked(func(gtx layout.Context) layout.Dimensions { return r.layoutContextMenu(gtx, state, &eventOut) }),
 		layout.Stacked(func(gtx layout.Context) layout.Dimensions { return r.layoutSearchHistoryOverlay(gtx) }),
 		layout.Expanded(func(gtx layout.Context) layout.Dimensions { return r.layoutSettingsModal(gtx, &eventOut) }),
 		layout.Expanded(func(gtx layout.Context) layout.Dimensions { return r.layoutHotkeysModal(gtx) }),
 		layout.Expanded(func(gtx layout.Context) layout.Dimensions { return r.layoutDeleteConfirm(gtx, state, &eventOut) }),
 		layout.Expanded(func(gtx layout.Context) layout.Dimensions { return r.layoutCreateDialog(gtx, state, &eventOut) }),
+		layout.Expanded(func(gtx layout.Context) layout.Dimensions { return r.layoutSaveSearchDialog(gtx, state, &eventOut) }),
+		layout.Expanded(func(gtx layout.Context) layout.Dimensions { return r.layoutCompressDialog(gtx, state, &eventOut) }),
+		layout.Expanded(func(gtx layout.Context) layout.Dimensions { return r.layoutBatchRenameDialog(gtx, &eventOut) }),
 		layout.Expanded(func(gtx layout.Context) layout.Dimensions { return r.layoutConflictDialog(gtx, state, &eventOut) }),
+		layout.Expanded(func(gtx layout.Context) layout.Dimensions { return r.layoutReportDialog(gtx, state, &eventOut) }),
+		layout.Expanded(func(gtx layout.Context) layout.Dimensions { return r.layoutSyncPlanDialog(gtx, state, &eventOut) }),
 		// Toast notifications (always on top)
 		layout.Expanded(func(gtx layout.Context) layout.Dimensions { return r.layoutToast(gtx, r.Theme) }),
 	)
@@ -3,15 +3,18 @@ package ui
 import (
 	"fmt"
 	"image/color"
+	"io"
 	"path/filepath"
 	"strings"
 
 	"gioui.org/font"
+	"gioui.org/io/clipboard"
 	"gioui.org/layout"
 	"gioui.org/unit"
 	"gioui.org/widget"
 	"gioui.org/widget/material"
 
+	"github.com/justyntemme/razor/internal/fs"
 	"github.com/justyntemme/razor/internal/trash"
 )
 
@@ -163,6 +166,167 @@ func (r *Renderer) layoutCreateDialog(gtx layout.Context, state *State, eventOut
 	})
 }
 
+func (r *Renderer) layoutSaveSearchDialog(gtx layout.Context, state *State, eventOut *UIEvent) layout.Dimensions {
+	if !r.saveSearchOpen {
+		return layout.Dimensions{}
+	}
+
+	save := func() {
+		name := strings.TrimSpace(r.saveSearchEditor.Text())
+		if name != "" {
+			r.saveSearchOpen = false
+			*eventOut = UIEvent{Action: ActionSaveSearch, Path: r.saveSearchQuery, SavedSearch: name}
+		}
+	}
+	for {
+		evt, ok := r.saveSearchEditor.Update(gtx)
+		if !ok {
+			break
+		}
+		if _, ok := evt.(widget.SubmitEvent); ok {
+			save()
+		}
+	}
+	if r.saveSearchOK.Clicked(gtx) {
+		r.onLeftClick()
+		save()
+	}
+	if r.saveSearchCancel.Clicked(gtx) {
+		r.onLeftClick()
+		r.saveSearchOpen = false
+	}
+
+	return r.modalBackdrop(gtx, 350, &r.saveSearchCancel, func(gtx layout.Context) layout.Dimensions {
+		return r.modalContent(gtx, "Save Search", colBlack,
+			// Body content
+			func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						lbl := material.Body2(r.Theme, "Name in the sidebar:")
+						lbl.Color = colGray
+						return lbl.Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return widget.Border{Color: colLightGray, Width: unit.Dp(1), CornerRadius: unit.Dp(4)}.Layout(gtx,
+							func(gtx layout.Context) layout.Dimensions {
+								return layout.Inset{Top: unit.Dp(8), Bottom: unit.Dp(8), Left: unit.Dp(12), Right: unit.Dp(12)}.Layout(gtx,
+									func(gtx layout.Context) layout.Dimensions {
+										ed := material.Editor(r.Theme, &r.saveSearchEditor, "search name")
+										return ed.Layout(gtx)
+									})
+							})
+					}),
+					layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						lbl := material.Caption(r.Theme, r.saveSearchQuery+"  in  "+state.CurrentPath)
+						lbl.Color = colGray
+						lbl.MaxLines = 2
+						return lbl.Layout(gtx)
+					}),
+				)
+			},
+			// Button row
+			func(gtx layout.Context) layout.Dimensions {
+				return r.dialogButtonRow(gtx, &r.saveSearchCancel, &r.saveSearchOK, "Cancel", "Save", ButtonPrimary)
+			},
+		)
+	})
+}
+
+// compressFormats are the kinds of archive "Compress to..." writes
+var compressFormats = []struct{ key, label string }{
+	{".zip", "zip"},
+	{".tar.gz", "tar.gz"},
+	{".tar.zst", "tar.zst"},
+}
+
+func (r *Renderer) layoutCompressDialog(gtx layout.Context, state *State, eventOut *UIEvent) layout.Dimensions {
+	if !r.compressOpen {
+		return layout.Dimensions{}
+	}
+
+	compress := func() {
+		// A typed extension gives way to the chosen format
+		name := fs.TrimArchiveExt(strings.TrimSpace(r.compressEditor.Text()))
+		if name != "" {
+			r.compressOpen = false
+			*eventOut = UIEvent{Action: ActionCompress, Paths: r.compressPaths, FileName: name + r.compressFormat.Value}
+		}
+	}
+	for {
+		evt, ok := r.compressEditor.Update(gtx)
+		if !ok {
+			break
+		}
+		if _, ok := evt.(widget.SubmitEvent); ok {
+			compress()
+		}
+	}
+	if r.compressOK.Clicked(gtx) {
+		r.onLeftClick()
+		compress()
+	}
+	if r.compressCancel.Clicked(gtx) {
+		r.onLeftClick()
+		r.compressOpen = false
+	}
+
+	items := fmt.Sprintf("%d items", len(r.compressPaths))
+	if len(r.compressPaths) == 1 {
+		items = filepath.Base(r.compressPaths[0])
+	}
+
+	return r.modalBackdrop(gtx, 350, &r.compressCancel, func(gtx layout.Context) layout.Dimensions {
+		return r.modalContent(gtx, "Compress", colBlack,
+			// Body content
+			func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						lbl := material.Body2(r.Theme, "Archive name:")
+						lbl.Color = colGray
+						return lbl.Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return widget.Border{Color: colLightGray, Width: unit.Dp(1), CornerRadius: unit.Dp(4)}.Layout(gtx,
+							func(gtx layout.Context) layout.Dimensions {
+								return layout.Inset{Top: unit.Dp(8), Bottom: unit.Dp(8), Left: unit.Dp(12), Right: unit.Dp(12)}.Layout(gtx,
+									func(gtx layout.Context) layout.Dimensions {
+										ed := material.Editor(r.Theme, &r.compressEditor, "archive name")
+										return ed.Layout(gtx)
+									})
+							})
+					}),
+					layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						children := make([]layout.FlexChild, 0, len(compressFormats))
+						for _, f := range compressFormats {
+							children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								rb := material.RadioButton(r.Theme, &r.compressFormat, f.key, f.label)
+								rb.Color = colBlack
+								return layout.Inset{Right: unit.Dp(12)}.Layout(gtx, rb.Layout)
+							}))
+						}
+						return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, children...)
+					}),
+					layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						lbl := material.Caption(r.Theme, items+"  in  "+state.CurrentPath)
+						lbl.Color = colGray
+						lbl.MaxLines = 2
+						return lbl.Layout(gtx)
+					}),
+				)
+			},
+			// Button row
+			func(gtx layout.Context) layout.Dimensions {
+				return r.dialogButtonRow(gtx, &r.compressCancel, &r.compressOK, "Cancel", "Compress", ButtonPrimary)
+			},
+		)
+	})
+}
+
 func (r *Renderer) layoutConflictDialog(gtx layout.Context, state *State, eventOut *UIEvent) layout.Dimensions {
 	if !state.Conflict.Active {
 		return layout.Dimensions{}
@@ -307,6 +471,238 @@ func (r *Renderer) layoutConflictDialog(gtx layout.Context, state *State, eventO
 	})
 }
 
+// layoutReportDialog summarizes a finished multi-file operation: failures first, then skips
+func (r *Renderer) layoutReportDialog(gtx layout.Context, state *State, eventOut *UIEvent) layout.Dimensions {
+	if !state.Report.Active {
+		return layout.Dimensions{}
+	}
+
+	if r.reportCloseBtn.Clicked(gtx) {
+		r.onLeftClick()
+		state.Report = OperationReport{}
+		return layout.Dimensions{}
+	}
+	if r.reportRetryBtn.Clicked(gtx) {
+		r.onLeftClick()
+		state.Report = OperationReport{}
+		*eventOut = UIEvent{Action: ActionRetryReport}
+		return layout.Dimensions{}
+	}
+	if r.reportCopyBtn.Clicked(gtx) {
+		gtx.Execute(clipboard.WriteCmd{
+			Type: "application/text",
+			Data: io.NopCloser(strings.NewReader(reportText(state.Report))),
+		})
+		r.ShowSuccess("Copied error list to clipboard")
+	}
+
+	// Flatten both lists into rows with a header per section
+	type reportRow struct {
+		header string
+		item   ReportItem
+	}
+	report := state.Report
+	var rows []reportRow
+	if len(report.Failed) > 0 {
+		rows = append(rows, reportRow{header: fmt.Sprintf("Failed (%d)", len(report.Failed))})
+		for _, item := range report.Failed {
+			rows = append(rows, reportRow{item: item})
+		}
+	}
+	if len(report.Skipped) > 0 {
+		rows = append(rows, reportRow{header: fmt.Sprintf("Skipped (%d)", len(report.Skipped))})
+		for _, item := range report.Skipped {
+			rows = append(rows, reportRow{item: item})
+		}
+	}
+
+	return r.modalBackdrop(gtx, 500, nil, func(gtx layout.Context) layout.Dimensions {
+		return r.modalContent(gtx, report.Title, colDanger,
+			// Body content
+			func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						lbl := material.Body1(r.Theme, report.Message)
+						lbl.Color = colBlack
+						return lbl.Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						// Long reports scroll instead of growing off screen
+						gtx.Constraints.Max.Y = min(gtx.Constraints.Max.Y, gtx.Dp(unit.Dp(260)))
+						r.reportList.Axis = layout.Vertical
+						return r.reportList.Layout(gtx, len(rows), func(gtx layout.Context, i int) layout.Dimensions {
+							row := rows[i]
+							if row.header != "" {
+								return layout.Inset{Top: unit.Dp(4), Bottom: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+									lbl := material.Body2(r.Theme, row.header)
+									lbl.Font.Weight = font.Bold
+									lbl.Color = colGray
+									return lbl.Layout(gtx)
+								})
+							}
+							return layout.Inset{Bottom: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+								return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+									layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+										lbl := material.Body2(r.Theme, row.item.Path)
+										lbl.Color, lbl.MaxLines = colBlack, 1
+										return lbl.Layout(gtx)
+									}),
+									layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+										lbl := material.Caption(r.Theme, row.item.Reason)
+										lbl.Color, lbl.MaxLines = colGray, 2
+										return lbl.Layout(gtx)
+									}),
+								)
+							})
+						})
+					}),
+				)
+			},
+			// Button row
+			func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Spacing: layout.SpaceStart}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return r.styledButton(gtx, &r.reportCopyBtn, "Copy Errors", ButtonSecondary)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return r.styledButton(gtx, &r.reportCloseBtn, "Close", ButtonSecondary)
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						if !report.CanRetry {
+							return layout.Dimensions{}
+						}
+						return layout.Inset{Left: unit.Dp(12)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+							return r.styledButton(gtx, &r.reportRetryBtn, "Retry Failed", ButtonPrimary)
+						})
+					}),
+				)
+			},
+		)
+	})
+}
+
+// layoutSyncPlanDialog lists what a folder sync would copy, and what it
+// leaves alone, before anything is written
+func (r *Renderer) layoutSyncPlanDialog(gtx layout.Context, state *State, eventOut *UIEvent) layout.Dimensions {
+	if !state.SyncPlan.Active {
+		return layout.Dimensions{}
+	}
+
+	if r.syncCancelBtn.Clicked(gtx) {
+		r.onLeftClick()
+		state.SyncPlan = SyncPlan{}
+		return layout.Dimensions{}
+	}
+	if r.syncRunBtn.Clicked(gtx) {
+		r.onLeftClick()
+		state.SyncPlan = SyncPlan{}
+		*eventOut = UIEvent{Action: ActionRunSync}
+		return layout.Dimensions{}
+	}
+
+	type planRow struct {
+		header string
+		item   ReportItem
+	}
+	plan := state.SyncPlan
+	var rows []planRow
+	for _, section := range []struct {
+		name  string
+		items []ReportItem
+	}{{"Copy", plan.Copies}, {"Leave Alone", plan.Skipped}} {
+		if len(section.items) == 0 {
+			continue
+		}
+		rows = append(rows, planRow{header: fmt.Sprintf("%s (%d)", section.name, len(section.items))})
+		for _, item := range section.items {
+			rows = append(rows, planRow{item: item})
+		}
+	}
+
+	return r.modalBackdrop(gtx, 500, nil, func(gtx layout.Context) layout.Dimensions {
+		return r.modalContent(gtx, plan.Title, colAccent,
+			// Body content
+			func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						lbl := material.Body1(r.Theme, plan.Message)
+						lbl.Color = colBlack
+						return lbl.Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						gtx.Constraints.Max.Y = min(gtx.Constraints.Max.Y, gtx.Dp(unit.Dp(260)))
+						return r.syncPlanList.Layout(gtx, len(rows), func(gtx layout.Context, i int) layout.Dimensions {
+							row := rows[i]
+							if row.header != "" {
+								return layout.Inset{Top: unit.Dp(4), Bottom: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+									lbl := material.Body2(r.Theme, row.header)
+									lbl.Font.Weight = font.Bold
+									lbl.Color = colGray
+									return lbl.Layout(gtx)
+								})
+							}
+							return layout.Inset{Bottom: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+								return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+									layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+										lbl := material.Body2(r.Theme, row.item.Path)
+										lbl.Color, lbl.MaxLines = colBlack, 1
+										return lbl.Layout(gtx)
+									}),
+									layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+										return layout.Inset{Left: unit.Dp(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+											lbl := material.Caption(r.Theme, row.item.Reason)
+											lbl.Color, lbl.MaxLines = colGray, 1
+											return lbl.Layout(gtx)
+										})
+									}),
+								)
+							})
+						})
+					}),
+				)
+			},
+			// Button row
+			func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Spacing: layout.SpaceStart}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return r.styledButton(gtx, &r.syncCancelBtn, "Cancel", ButtonSecondary)
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						if len(plan.Copies) == 0 {
+							return layout.Dimensions{}
+						}
+						return layout.Inset{Left: unit.Dp(12)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+							return r.styledButton(gtx, &r.syncRunBtn, "Sync", ButtonPrimary)
+						})
+					}),
+				)
+			},
+		)
+	})
+}
+
+// reportText formats a report for the clipboard, one "path: reason" line per item
+func reportText(report OperationReport) string {
+	var sb strings.Builder
+	sb.WriteString(report.Title + "\n" + report.Message + "\n")
+	for _, section := range []struct {
+		name  string
+		items []ReportItem
+	}{{"Failed", report.Failed}, {"Skipped", report.Skipped}} {
+		if len(section.items) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n%s (%d):\n", section.name, len(section.items))
+		for _, item := range section.items {
+			sb.WriteString(item.Path + ": " + item.Reason + "\n")
+		}
+	}
+	return sb.String()
+}
+
 func formatSizeForDialog(bytes int64) string {
 	const unit = 1024
 	if bytes < unit {
@@ -92,7 +92,7 @@ func (r *Renderer) layoutFileList(gtx layout.Context, state *State, keyTag *layo
 						r.multiSelectMode = false
 						r.lastClickIndex = -1
 						r.lastClickTime = time.Time{}
-						if !r.settingsOpen && !r.deleteConfirmOpen && !r.createDialogOpen && !state.Conflict.Active {
+						if !r.settingsOpen && !r.deleteConfirmOpen && !r.createDialogOpen && !r.saveSearchOpen && !r.compressOpen && !r.batchRenameOpen && !state.Conflict.Active {
 							*eventOut = UIEvent{Action: ActionClearSelection}
 							gtx.Execute(key.FocusCmd{Tag: keyTag})
 						}
@@ -221,6 +221,7 @@ func (r *Renderer) layoutFileList(gtx layout.Context, state *State, keyTag *layo
 						r.menuIsDir = item.IsDir
 						_, r.menuIsFav = state.Favorites[item.Path]
 						r.menuIsBackground = false
+						r.menuSavedSearch = ""
 						gtx.Execute(op.InvalidateCmd{})
 						// Don't change selection on right-click - menu operations use collectSelectedPaths()
 						// which will get all selected items. Selection is only cleared after copy/cut.
@@ -290,6 +291,7 @@ func (r *Renderer) layoutFileList(gtx layout.Context, state *State, keyTag *layo
 					r.menuIsDir = true
 					r.menuIsFav = false
 					r.menuIsBackground = true
+					r.menuSavedSearch = ""
 					gtx.Execute(op.InvalidateCmd{})
 					// Don't clear selection on background right-click
 				}
@@ -267,6 +267,7 @@ func (r *Renderer) layoutFileGrid(gtx layout.Context, state *State, keyTag *layo
 			r.menuIsDir = true
 			r.menuIsFav = false
 			r.menuIsBackground = true
+			r.menuSavedSearch = ""
 			gtx.Execute(op.InvalidateCmd{})
 		}
 		r.bgRightClickPending = false
@@ -293,7 +294,7 @@ func (r *Renderer) layoutFileGrid(gtx layout.Context, state *State, keyTag *layo
 			r.onLeftClick()
 			r.CancelRename() // Cancel any active rename
 			r.multiSelectMode = false
-			if !r.settingsOpen && !r.deleteConfirmOpen && !r.createDialogOpen && !state.Conflict.Active {
+			if !r.settingsOpen && !r.deleteConfirmOpen && !r.createDialogOpen && !r.saveSearchOpen && !r.compressOpen && !r.batchRenameOpen && !state.Conflict.Active {
 				*eventOut = UIEvent{Action: ActionClearSelection}
 				gtx.Execute(key.FocusCmd{Tag: keyTag})
 			}
@@ -631,6 +632,7 @@ func (r *Renderer) layoutGridItem(gtx layout.Context, state *State, item *UIEntr
 			r.menuIsDir = item.IsDir
 			_, r.menuIsFav = state.Favorites[item.Path]
 			r.menuIsBackground = false
+			r.menuSavedSearch = ""
 			gtx.Execute(op.InvalidateCmd{})
 		}
 	}
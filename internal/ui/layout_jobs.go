@@ -0,0 +1,205 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+	"strings"
+	"time"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// jobControls holds the buttons for one row of the jobs panel
+type jobControls struct {
+	pauseBtn  widget.Clickable // Pause or Resume
+	cancelBtn widget.Clickable // Cancel, or Dismiss for failed jobs
+}
+
+// layoutJobsPanel lists background file operations with per-job progress and controls
+func (r *Renderer) layoutJobsPanel(gtx layout.Context, state *State, eventOut *UIEvent) layout.Dimensions {
+	if r.jobControls == nil {
+		r.jobControls = make(map[int64]*jobControls)
+	}
+
+	// Drop buttons for jobs that are gone
+	live := make(map[int64]bool, len(state.Jobs))
+	for _, job := range state.Jobs {
+		live[job.ID] = true
+	}
+	for id := range r.jobControls {
+		if !live[id] {
+			delete(r.jobControls, id)
+		}
+	}
+
+	if len(state.Jobs) == 0 {
+		return layout.Dimensions{}
+	}
+
+	// Handle button clicks
+	for _, job := range state.Jobs {
+		ctl := r.jobControls[job.ID]
+		if ctl == nil {
+			ctl = &jobControls{}
+			r.jobControls[job.ID] = ctl
+		}
+		if ctl.pauseBtn.Clicked(gtx) {
+			if job.Status == JobPaused {
+				*eventOut = UIEvent{Action: ActionResumeJob, JobID: job.ID}
+			} else {
+				*eventOut = UIEvent{Action: ActionPauseJob, JobID: job.ID}
+			}
+		}
+		if ctl.cancelBtn.Clicked(gtx) {
+			if job.Status == JobFailed {
+				*eventOut = UIEvent{Action: ActionDismissJob, JobID: job.ID}
+			} else {
+				*eventOut = UIEvent{Action: ActionCancelJob, JobID: job.ID}
+			}
+		}
+	}
+
+	// Keep the panel from crowding out the file list
+	if maxHeight := gtx.Dp(unit.Dp(180)); gtx.Constraints.Max.Y > maxHeight {
+		gtx.Constraints.Max.Y = maxHeight
+	}
+
+	return layout.Inset{Left: unit.Dp(8), Right: unit.Dp(8), Bottom: unit.Dp(8)}.Layout(gtx,
+		func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return r.layoutHorizontalSeparator(gtx, colLightGray)
+				}),
+				layout.Rigid(layout.Spacer{Height: unit.Dp(4)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Body2(r.Theme, fmt.Sprintf("Jobs (%d)", len(state.Jobs)))
+					lbl.Font.Weight = font.Bold
+					lbl.Color = colGray
+					return lbl.Layout(gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					r.jobsList.Axis = layout.Vertical
+					return r.jobsList.Layout(gtx, len(state.Jobs), func(gtx layout.Context, i int) layout.Dimensions {
+						return r.layoutJobRow(gtx, state.Jobs[i])
+					})
+				}),
+			)
+		})
+}
+
+// layoutJobRow renders a single job: title, progress bar, details and controls
+func (r *Renderer) layoutJobRow(gtx layout.Context, job JobInfo) layout.Dimensions {
+	ctl := r.jobControls[job.ID]
+
+	return layout.Inset{Top: unit.Dp(4), Bottom: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						title := job.Label
+						switch job.Status {
+						case JobQueued:
+							title += " (queued)"
+						case JobPaused:
+							title += " (paused)"
+						case JobFailed:
+							title += " (failed)"
+						}
+						lbl := material.Body2(r.Theme, title)
+						lbl.Color, lbl.MaxLines = colBlack, 1
+						if job.Status == JobFailed {
+							lbl.Color = colDanger
+						}
+						return lbl.Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Height: unit.Dp(2)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return r.layoutJobBar(gtx, job)
+					}),
+					layout.Rigid(layout.Spacer{Height: unit.Dp(2)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						lbl := material.Caption(r.Theme, jobDetails(job))
+						lbl.Color, lbl.MaxLines = colGray, 1
+						return lbl.Layout(gtx)
+					}),
+				)
+			}),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if job.Status == JobFailed {
+					return layout.Dimensions{}
+				}
+				label := "Pause"
+				if job.Status == JobPaused {
+					label = "Resume"
+				}
+				return r.styledButton(gtx, &ctl.pauseBtn, label, ButtonSecondary)
+			}),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if job.Status == JobFailed {
+					return r.styledButton(gtx, &ctl.cancelBtn, "Dismiss", ButtonSecondary)
+				}
+				return r.styledButton(gtx, &ctl.cancelBtn, "Cancel", ButtonDanger)
+			}),
+		)
+	})
+}
+
+// layoutJobBar draws a thin determinate progress bar for a job
+func (r *Renderer) layoutJobBar(gtx layout.Context, job JobInfo) layout.Dimensions {
+	height := gtx.Dp(unit.Dp(6))
+	width := gtx.Constraints.Max.X
+	paint.FillShape(gtx.Ops, colLightGray, clip.Rect{Max: image.Pt(width, height)}.Op())
+
+	var pct float32
+	if job.BytesTotal > 0 {
+		pct = float32(job.BytesDone) / float32(job.BytesTotal)
+	} else if job.FilesTotal > 0 {
+		pct = float32(job.FilesDone) / float32(job.FilesTotal)
+	}
+	pct = min(pct, 1)
+
+	fill := colProgress
+	switch job.Status {
+	case JobPaused, JobQueued:
+		fill = colDisabled
+	case JobFailed:
+		fill = colDanger
+	}
+	paint.FillShape(gtx.Ops, fill, clip.Rect{Max: image.Pt(int(float32(width)*pct), height)}.Op())
+	return layout.Dimensions{Size: image.Pt(width, height)}
+}
+
+// jobDetails formats the status line under a job, e.g. "12.0 MB / 40.0 MB • 3/10 files • 5.1 MB/s • 6s left"
+func jobDetails(job JobInfo) string {
+	if job.Status == JobFailed {
+		return job.Err
+	}
+
+	var parts []string
+	if job.BytesTotal > 0 {
+		parts = append(parts, formatSize(job.BytesDone)+" / "+formatSize(job.BytesTotal))
+	}
+	if job.FilesTotal > 0 {
+		parts = append(parts, fmt.Sprintf("%d/%d files", job.FilesDone, job.FilesTotal))
+	}
+	if job.Status == JobRunning {
+		if job.Throughput > 0 {
+			parts = append(parts, formatSize(int64(job.Throughput))+"/s")
+		}
+		if job.ETA > 0 {
+			parts = append(parts, job.ETA.Round(time.Second).String()+" left")
+		}
+		if job.Current != "" {
+			parts = append(parts, job.Current)
+		}
+	}
+	return strings.Join(parts, " • ")
+}
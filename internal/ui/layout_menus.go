@@ -1,8 +1,10 @@
 package ui
 
 import (
+	"fmt"
 	"image"
 	"image/color"
+	"sort"
 
 	"gioui.org/layout"
 	"gioui.org/op"
@@ -13,6 +15,7 @@ import (
 	"gioui.org/widget/material"
 
 	"github.com/justyntemme/razor/internal/debug"
+	"github.com/justyntemme/razor/internal/fs"
 	"github.com/justyntemme/razor/internal/trash"
 )
 
@@ -102,7 +105,7 @@ func (r *Renderer) menuItemDanger(gtx layout.Context, btn *widget.Clickable, lab
 	return r.menuItemWithColor(gtx, btn, label, colDanger)
 }
 
-func (r *Renderer) layoutFileMenu(gtx layout.Context, eventOut *UIEvent) layout.Dimensions {
+func (r *Renderer) layoutFileMenu(gtx layout.Context, state *State, eventOut *UIEvent) layout.Dimensions {
 	if !r.fileMenuOpen {
 		return layout.Dimensions{}
 	}
@@ -123,6 +126,31 @@ func (r *Renderer) layoutFileMenu(gtx layout.Context, eventOut *UIEvent) layout.
 				}
 				return r.menuItem(gtx, &r.newTabBtn, "New Tab")
 			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if r.dualPaneBtn.Clicked(gtx) {
+					r.onLeftClick()
+					*eventOut = UIEvent{Action: ActionToggleDualPane}
+				}
+				label := "Dual Pane"
+				if state.DualPane {
+					label = "Single Pane"
+				}
+				return r.menuItem(gtx, &r.dualPaneBtn, label)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if !state.DualPane {
+					return layout.Dimensions{}
+				}
+				if r.comparePanesBtn.Clicked(gtx) {
+					r.onLeftClick()
+					left, right := state.CurrentPath, state.OtherPane.Path
+					if state.RightFocus {
+						left, right = right, left
+					}
+					*eventOut = UIEvent{Action: ActionCompareDirs, Paths: []string{left, right}}
+				}
+				return r.menuItem(gtx, &r.comparePanesBtn, "Compare Panes")
+			}),
 		}
 
 		// Separator and Settings
@@ -163,7 +191,9 @@ func (r *Renderer) layoutContextMenu(gtx layout.Context, state *State, eventOut
 	menuWidth := gtx.Dp(180)
 	menuHeight := gtx.Dp(280) // Full menu approximate height
 	if r.menuIsBackground {
-		menuHeight = gtx.Dp(100) // Background menu is shorter
+		menuHeight = gtx.Dp(130) // Background menu is shorter
+	} else if r.menuSavedSearch != "" {
+		menuHeight = gtx.Dp(40) // Single item
 	}
 
 	// Determine final position with flip logic
@@ -222,6 +252,10 @@ func (r *Renderer) layoutContextMenu(gtx layout.Context, state *State, eventOut
 		closeMenu()
 		*eventOut = UIEvent{Action: ActionPaste}
 	}
+	if r.pasteVerifyBtn.Clicked(gtx) {
+		closeMenu()
+		*eventOut = UIEvent{Action: ActionPaste, Verify: true}
+	}
 	if r.newFileBtn.Clicked(gtx) {
 		closeMenu()
 		r.ShowCreateDialog(false)
@@ -239,6 +273,11 @@ func (r *Renderer) layoutContextMenu(gtx layout.Context, state *State, eventOut
 		closeMenu()
 		*eventOut = UIEvent{Action: ActionEmptyTrash}
 	}
+	if r.restoreBtn.Clicked(gtx) {
+		closeMenu()
+		paths := r.collectSelectedPaths(state)
+		*eventOut = UIEvent{Action: ActionRestoreFromTrash, Paths: paths}
+	}
 	if r.permanentDeleteBtn.Clicked(gtx) {
 		closeMenu()
 		paths := r.collectSelectedPaths(state)
@@ -246,8 +285,8 @@ func (r *Renderer) layoutContextMenu(gtx layout.Context, state *State, eventOut
 	}
 	if r.renameBtn.Clicked(gtx) {
 		closeMenu()
-		// Start rename for the selected item
-		if state.SelectedIndex >= 0 && state.SelectedIndex < len(state.Entries) {
+		// Multi-selections get the batch dialog, a single item is renamed inline
+		if !r.ShowBatchRename(state) && state.SelectedIndex >= 0 && state.SelectedIndex < len(state.Entries) {
 			item := &state.Entries[state.SelectedIndex]
 			r.StartRename(state.SelectedIndex, item.Path, item.Name, item.IsDir)
 		}
@@ -260,10 +299,66 @@ func (r *Renderer) layoutContextMenu(gtx layout.Context, state *State, eventOut
 		}
 		*eventOut = UIEvent{Action: action, Path: r.menuPath}
 	}
+	if r.removeSearchBtn.Clicked(gtx) {
+		closeMenu()
+		*eventOut = UIEvent{Action: ActionRemoveSavedSearch, SavedSearch: r.menuSavedSearch}
+	}
 	if r.openInNewTabBtn.Clicked(gtx) {
 		closeMenu()
 		*eventOut = UIEvent{Action: ActionOpenInNewTab, Path: r.menuPath}
 	}
+	if r.findDupesBtn.Clicked(gtx) {
+		closeMenu()
+		// The background menu searches the current directory
+		dir := r.menuPath
+		if r.menuIsBackground || !r.menuIsDir {
+			dir = state.CurrentPath
+		}
+		*eventOut = UIEvent{Action: ActionFindDuplicates, Path: dir}
+	}
+	for _, item := range []struct {
+		btn  *widget.Clickable
+		mode DedupeMode
+	}{{&r.keepNewestBtn, DedupeKeepNewest}, {&r.keepOldestBtn, DedupeKeepOldest}, {&r.hardlinkBtn, DedupeHardlink}} {
+		if item.btn.Clicked(gtx) {
+			closeMenu()
+			*eventOut = UIEvent{Action: ActionResolveDuplicates, Paths: r.collectSelectedPaths(state), Dedupe: item.mode}
+		}
+	}
+	if r.compressBtn.Clicked(gtx) {
+		closeMenu()
+		r.ShowCompressDialog(state)
+	}
+	if r.extractHereBtn.Clicked(gtx) {
+		closeMenu()
+		*eventOut = UIEvent{Action: ActionExtractHere, Path: r.menuPath}
+	}
+	if r.extractFolderBtn.Clicked(gtx) {
+		closeMenu()
+		*eventOut = UIEvent{Action: ActionExtractToFolder, Path: r.menuPath}
+	}
+	if r.compareBtn.Clicked(gtx) {
+		closeMenu()
+		*eventOut = UIEvent{Action: ActionCompareDirs, Paths: selectedFolders(state)}
+	}
+	if r.compareContentsBtn.Clicked(gtx) {
+		closeMenu()
+		*eventOut = UIEvent{Action: ActionCompareDirs, Paths: []string{state.Compare.Left, state.Compare.Right}, CompareContents: !state.Compare.ByContent}
+	}
+	for _, item := range []struct {
+		btn  *widget.Clickable
+		mode fs.SyncMode
+	}{{&r.syncRightBtn, fs.SyncLeftToRight}, {&r.syncLeftBtn, fs.SyncRightToLeft}, {&r.syncBothBtn, fs.SyncBothWays}} {
+		if item.btn.Clicked(gtx) {
+			closeMenu()
+			// The background menu syncs everything, the item menu the selection
+			var paths []string
+			if !r.menuIsBackground {
+				paths = r.collectSelectedPaths(state)
+			}
+			*eventOut = UIEvent{Action: ActionPlanSync, Paths: paths, Sync: item.mode}
+		}
+	}
 	if r.openTerminalBtn.Clicked(gtx) {
 		closeMenu()
 		// For favorites/drives sidebar, use the clicked item's path
@@ -275,6 +370,63 @@ func (r *Renderer) layoutContextMenu(gtx layout.Context, state *State, eventOut
 		*eventOut = UIEvent{Action: ActionOpenTerminal, Path: termPath}
 	}
 
+	// Saved search in the sidebar: it's not a directory, so it can only be removed
+	if r.menuSavedSearch != "" {
+		return r.menuShell(gtx, 180, func(gtx layout.Context) layout.Dimensions {
+			return r.menuItemDanger(gtx, &r.removeSearchBtn, "Remove Saved Search")
+		})
+	}
+
+	// Compare view menu - syncs the selection, or everything from the background
+	if r.isCompareView {
+		return r.menuShell(gtx, 220, func(gtx layout.Context) layout.Dimensions {
+			selected := ""
+			if !r.menuIsBackground {
+				selected = "Selected "
+			}
+			contentsLabel := "Compare by Contents"
+			if state.Compare.ByContent {
+				contentsLabel = "Compare by Size and Date"
+			}
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					if r.menuIsBackground {
+						return layout.Dimensions{}
+					}
+					return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							if r.menuIsDir {
+								return layout.Dimensions{}
+							}
+							return r.menuItem(gtx, &r.openBtn, "Open")
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return r.menuItem(gtx, &r.openLocationBtn, "Open File Location")
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return r.layoutMenuSeparator(gtx)
+						}),
+					)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return r.menuItem(gtx, &r.syncRightBtn, "Sync "+selected+"Left → Right")
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return r.menuItem(gtx, &r.syncLeftBtn, "Sync "+selected+"Right → Left")
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return r.menuItem(gtx, &r.syncBothBtn, "Sync "+selected+"Both Ways")
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return r.layoutMenuSeparator(gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return r.menuItem(gtx, &r.compareContentsBtn, contentsLabel)
+				}),
+			)
+		})
+	}
+
 	// Background menu (right-click on empty space) shows limited options
 	if r.menuIsBackground {
 		return r.menuShell(gtx, 180, func(gtx layout.Context) layout.Dimensions {
@@ -296,6 +448,9 @@ func (r *Renderer) layoutContextMenu(gtx layout.Context, state *State, eventOut
 						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 							return r.menuItem(gtx, &r.pasteBtn, "Paste")
 						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return r.menuItem(gtx, &r.pasteVerifyBtn, "Paste and Verify")
+						}),
 					)
 				}),
 				// Separator and Open Terminal Here
@@ -305,15 +460,60 @@ func (r *Renderer) layoutContextMenu(gtx layout.Context, state *State, eventOut
 				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 					return r.menuItem(gtx, &r.openTerminalBtn, "Open Terminal Here")
 				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return r.menuItem(gtx, &r.findDupesBtn, "Find Duplicates")
+				}),
 			)
 		})
 	}
 
-	// Trash view context menu - shows Copy, Cut, and Permanently Delete
-	// Note: Restore is not supported on macOS/Windows as they don't track original paths
+	// Duplicates view context menu - acts on the groups of the selected files
+	if r.isDuplicatesView {
+		return r.menuShell(gtx, 200, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return r.menuItem(gtx, &r.openBtn, "Open")
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return r.menuItem(gtx, &r.openLocationBtn, "Open File Location")
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return r.layoutMenuSeparator(gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return r.menuItem(gtx, &r.keepNewestBtn, "Keep Newest, Trash Others")
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return r.menuItem(gtx, &r.keepOldestBtn, "Keep Oldest, Trash Others")
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return r.menuItem(gtx, &r.hardlinkBtn, "Replace With Hardlinks")
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return r.layoutMenuSeparator(gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					label := "Delete"
+					if trash.IsAvailable() {
+						label = trash.VerbPhrase()
+					}
+					return r.menuItemDanger(gtx, &r.deleteBtn, label)
+				}),
+			)
+		})
+	}
+
+	// Trash view context menu - shows Restore, Copy, Cut, and Permanently Delete
+	// Note: Restore is only shown where the platform supports it (not macOS/Windows)
 	if r.isTrashView {
 		return r.menuShell(gtx, 180, func(gtx layout.Context) layout.Dimensions {
 			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					if !trash.CanRestore() {
+						return layout.Dimensions{}
+					}
+					return r.menuItem(gtx, &r.restoreBtn, "Restore")
+				}),
 				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 					return r.menuItem(gtx, &r.copyBtn, "Copy")
 				}),
@@ -356,6 +556,39 @@ func (r *Renderer) layoutContextMenu(gtx layout.Context, state *State, eventOut
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 				return r.menuItem(gtx, &r.openTerminalBtn, "Open Terminal Here")
 			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if !r.menuIsDir {
+					return layout.Dimensions{}
+				}
+				return r.menuItem(gtx, &r.findDupesBtn, "Find Duplicates")
+			}),
+			// "Compare Folders" only shown with exactly two folders selected
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if len(selectedFolders(state)) != 2 {
+					return layout.Dimensions{}
+				}
+				return r.menuItem(gtx, &r.compareBtn, "Compare Folders")
+			}),
+			// "Extract" only shown for archives on this computer
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if r.menuIsDir || !fs.IsArchive(r.menuPath) || fs.IsRemote(r.menuPath) {
+					return layout.Dimensions{}
+				}
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return r.menuItem(gtx, &r.extractHereBtn, "Extract Here")
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return r.menuItem(gtx, &r.extractFolderBtn, "Extract to Folder")
+					}),
+				)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if n := len(state.SelectedIndices); n > 1 {
+					return r.menuItem(gtx, &r.compressBtn, fmt.Sprintf("Compress %d Items to...", n))
+				}
+				return r.menuItem(gtx, &r.compressBtn, "Compress to...")
+			}),
 			// "Open file location" only shown when viewing recent files
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 				if !r.isRecentView {
@@ -376,6 +609,15 @@ func (r *Renderer) layoutContextMenu(gtx layout.Context, state *State, eventOut
 				return r.menuItem(gtx, &r.pasteBtn, "Paste")
 			}),
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if state.Clipboard == nil {
+					return layout.Dimensions{}
+				}
+				return r.menuItem(gtx, &r.pasteVerifyBtn, "Paste and Verify")
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if n := len(state.SelectedIndices); n > 1 {
+					return r.menuItem(gtx, &r.renameBtn, fmt.Sprintf("Rename %d Items...", n))
+				}
 				return r.menuItem(gtx, &r.renameBtn, "Rename")
 			}),
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
@@ -410,3 +652,17 @@ func (r *Renderer) layoutContextMenu(gtx layout.Context, state *State, eventOut
 		)
 	})
 }
+
+// selectedFolders returns the paths of the multi-selection if it is made of
+// folders only
+func selectedFolders(state *State) []string {
+	var paths []string
+	for idx := range state.SelectedIndices {
+		if idx < 0 || idx >= len(state.Entries) || !state.Entries[idx].IsDir {
+			return nil
+		}
+		paths = append(paths, state.Entries[idx].Path)
+	}
+	sort.Strings(paths)
+	return paths
+}
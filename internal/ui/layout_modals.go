@@ -227,6 +227,8 @@ func (r *Renderer) layoutHotkeysModal(gtx layout.Context) layout.Dimensions {
 				{"New File", r.hotkeys.NewFile.String()},
 				{"New Folder", r.hotkeys.NewFolder.String()},
 				{"Select All", r.hotkeys.SelectAll.String()},
+				{"Undo", r.hotkeys.Undo.String()},
+				{"Redo", r.hotkeys.Redo.String()},
 			},
 		},
 		{
@@ -264,6 +266,15 @@ func (r *Renderer) layoutHotkeysModal(gtx layout.Context) layout.Dimensions {
 				{"Tab 6", r.hotkeys.Tab6.String()},
 			},
 		},
+		{
+			title: "DUAL PANE",
+			entries: []hotkeyEntry{
+				{"Toggle Dual Pane", r.hotkeys.DualPane.String()},
+				{"Switch Pane", r.hotkeys.SwitchPane.String()},
+				{"Copy to Other Pane", r.hotkeys.CopyToPane.String()},
+				{"Move to Other Pane", r.hotkeys.MoveToPane.String()},
+			},
+		},
 	}
 
 	// Helper to render a hotkey row
@@ -3,6 +3,7 @@ package ui
 import (
 	"image"
 	"image/color"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -16,6 +17,8 @@ import (
 	"gioui.org/unit"
 	"gioui.org/widget"
 	"gioui.org/widget/material"
+
+	"github.com/justyntemme/razor/internal/fs"
 )
 
 // Navigation bar layout - back/forward buttons, breadcrumb, search box
@@ -80,7 +83,7 @@ func (r *Renderer) layoutNavBar(gtx layout.Context, state *State, keyTag *layout
 					if !r.breadcrumbLastClicks[i].IsZero() && now.Sub(r.breadcrumbLastClicks[i]) < doubleClickInterval {
 						// Double-click: enter edit mode
 						r.isEditing = true
-						r.pathEditor.SetText(state.CurrentPath)
+						r.pathEditor.SetText(fs.DisplayPath(state.CurrentPath))
 						gtx.Execute(key.FocusCmd{Tag: &r.pathEditor})
 						r.breadcrumbLastClicks[i] = time.Time{} // Reset
 					} else {
@@ -95,7 +98,7 @@ func (r *Renderer) layoutNavBar(gtx layout.Context, state *State, keyTag *layout
 			// Single click on breadcrumb area (not on a segment) enters edit mode
 			if r.pathClick.Clicked(gtx) && !segmentClicked {
 				r.isEditing = true
-				r.pathEditor.SetText(state.CurrentPath)
+				r.pathEditor.SetText(fs.DisplayPath(state.CurrentPath))
 				gtx.Execute(key.FocusCmd{Tag: &r.pathEditor})
 			}
 
@@ -189,7 +192,17 @@ func (r *Renderer) layoutNavBar(gtx layout.Context, state *State, keyTag *layout
 				strings.Contains(lowerText, "modified:") ||
 				strings.Contains(lowerText, "filename:") ||
 				strings.Contains(lowerText, "recursive:") ||
-				strings.Contains(lowerText, "depth:")
+				strings.Contains(lowerText, "depth:") ||
+				strings.Contains(lowerText, "ignore:") ||
+				strings.Contains(lowerText, "index:") ||
+				strings.Contains(lowerText, "type:") ||
+				strings.Contains(lowerText, "perm:") ||
+				strings.Contains(lowerText, "owner:") ||
+				strings.Contains(lowerText, "group:") ||
+				strings.Contains(lowerText, "path:") ||
+				strings.Contains(lowerText, "created:") ||
+				strings.Contains(lowerText, "accessed:") ||
+				strings.Contains(lowerText, "empty:")
 
 			if hasDirectivePrefix {
 				// Directive detected - restore directory listing once
@@ -227,6 +240,12 @@ func (r *Renderer) layoutNavBar(gtx layout.Context, state *State, keyTag *layout
 				gtx.Execute(key.FocusCmd{Tag: keyTag})
 			}
 
+			// Handle "Save this search" button
+			if r.searchSaveBtn.Clicked(gtx) {
+				r.onLeftClick()
+				r.ShowSaveSearchDialog(state.SearchQuery)
+			}
+
 			// Only show/fetch history when search box is focused
 			// AND we haven't already set a search event (don't overwrite search-as-you-type)
 			if r.searchEditorFocused && eventOut.Action == ActionNone {
@@ -261,8 +280,10 @@ func (r *Renderer) layoutNavBar(gtx layout.Context, state *State, keyTag *layout
 
 			// Show clear button if there's text OR if we're showing search results
 			showClearBtn := r.searchEditor.Text() != "" || state.IsSearchResult
+			// Offer to save the search once it has run
+			showSaveBtn := state.IsSearchResult && state.SearchQuery != ""
 
-			return r.layoutSearchWithHistory(gtx, hasDirectivePrefix, showClearBtn)
+			return r.layoutSearchWithHistory(gtx, hasDirectivePrefix, showClearBtn, showSaveBtn)
 		}),
 	)
 }
@@ -409,7 +430,13 @@ func (r *Renderer) layoutBreadcrumb(gtx layout.Context, state *State, eventOut *
 						func(gtx layout.Context) layout.Dimensions {
 							return layout.Inset{Top: unit.Dp(2), Bottom: unit.Dp(2), Left: unit.Dp(6), Right: unit.Dp(6)}.Layout(gtx,
 								func(gtx layout.Context) layout.Dimensions {
-									badge := material.Caption(r.Theme, "Search Results")
+									label := "Search Results"
+									if r.isDuplicatesView {
+										label = "Duplicates"
+									} else if r.isCompareView {
+										label = "Comparing " + filepath.Base(state.Compare.Left) + " ↔ " + filepath.Base(state.Compare.Right)
+									}
+									badge := material.Caption(r.Theme, label)
 									badge.Color = colAccent
 									return badge.Layout(gtx)
 								})
@@ -0,0 +1,154 @@
+package ui
+
+import (
+	"image"
+	"image/color"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+)
+
+// Dual-pane layout - the focused pane is the regular file list, the other
+// pane a read-only list that takes focus when clicked
+
+// layoutFileArea lays out the file list, or in dual-pane mode both panes side
+// by side. offset is the area's position in the window, which drag hover
+// detection needs for the focused list.
+func (r *Renderer) layoutFileArea(gtx layout.Context, state *State, keyTag *layout.List, eventOut *UIEvent, offset image.Point) layout.Dimensions {
+	if !state.DualPane {
+		r.fileListOffset = offset
+		return r.layoutFileList(gtx, state, keyTag, eventOut)
+	}
+
+	dividerWidth := gtx.Dp(1)
+	leftWidth := (gtx.Constraints.Max.X - dividerWidth) / 2
+	rightWidth := gtx.Constraints.Max.X - dividerWidth - leftWidth
+	focusedX := 0
+	if state.RightFocus {
+		focusedX = leftWidth + dividerWidth
+	}
+
+	focused := func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				dims := r.layoutPaneHeader(gtx, state.CurrentPath, true)
+				r.fileListOffset = offset.Add(image.Pt(focusedX, dims.Size.Y))
+				return dims
+			}),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return r.layoutFileList(gtx, state, keyTag, eventOut)
+			}),
+		)
+	}
+	other := func(gtx layout.Context) layout.Dimensions {
+		if r.otherPaneBtn.Clicked(gtx) {
+			r.onLeftClick()
+			*eventOut = UIEvent{Action: ActionSwitchPane}
+		}
+		return r.otherPaneBtn.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return r.layoutOtherPane(gtx, &state.OtherPane)
+		})
+	}
+
+	left, right := focused, other
+	if state.RightFocus {
+		left, right = other, focused
+	}
+	return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			gtx.Constraints.Min.X, gtx.Constraints.Max.X = leftWidth, leftWidth
+			return left(gtx)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			paint.FillShape(gtx.Ops, color.NRGBA{A: 50}, clip.Rect{Max: image.Pt(dividerWidth, gtx.Constraints.Max.Y)}.Op())
+			return layout.Dimensions{Size: image.Pt(dividerWidth, gtx.Constraints.Max.Y)}
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			gtx.Constraints.Min.X, gtx.Constraints.Max.X = rightWidth, rightWidth
+			return right(gtx)
+		}),
+	)
+}
+
+// layoutPaneHeader shows a pane's directory, underlined in the accent color
+// for the focused pane
+func (r *Renderer) layoutPaneHeader(gtx layout.Context, path string, focused bool) layout.Dimensions {
+	macro := op.Record(gtx.Ops)
+	dims := layout.Inset{Top: unit.Dp(4), Bottom: unit.Dp(4), Left: unit.Dp(12), Right: unit.Dp(12)}.Layout(gtx,
+		func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Body2(r.Theme, truncatePathMiddle(path, 60))
+			lbl.MaxLines = 1
+			lbl.Color = colGray
+			if focused {
+				lbl.Color, lbl.Font.Weight = colBlack, font.Bold
+			}
+			return lbl.Layout(gtx)
+		})
+	call := macro.Stop()
+
+	lineColor, lineHeight := color.NRGBA{A: 50}, gtx.Dp(1)
+	if focused {
+		lineColor, lineHeight = colAccent, gtx.Dp(2)
+	}
+	width := gtx.Constraints.Max.X
+	call.Add(gtx.Ops)
+	paint.FillShape(gtx.Ops, lineColor, clip.Rect{Min: image.Pt(0, dims.Size.Y), Max: image.Pt(width, dims.Size.Y+lineHeight)}.Op())
+	return layout.Dimensions{Size: image.Pt(width, dims.Size.Y+lineHeight)}
+}
+
+// layoutOtherPane lists the entries of the pane without focus
+func (r *Renderer) layoutOtherPane(gtx layout.Context, pane *PaneView) layout.Dimensions {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return r.layoutPaneHeader(gtx, pane.Path, false)
+		}),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			gtx.Constraints.Min = gtx.Constraints.Max
+			return r.otherPaneList.Layout(gtx, len(pane.Entries), func(gtx layout.Context, i int) layout.Dimensions {
+				return r.layoutOtherPaneRow(gtx, &pane.Entries[i], i == pane.SelectedIndex)
+			})
+		}),
+	)
+}
+
+func (r *Renderer) layoutOtherPaneRow(gtx layout.Context, item *UIEntry, selected bool) layout.Dimensions {
+	macro := op.Record(gtx.Ops)
+	dims := layout.Inset{Top: unit.Dp(4), Bottom: unit.Dp(4), Left: unit.Dp(12 + 20*item.Depth), Right: unit.Dp(12)}.Layout(gtx,
+		func(gtx layout.Context) layout.Dimensions {
+			gtx.Constraints.Min.X = gtx.Constraints.Max.X
+			return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					name := item.Name
+					if item.IsDir {
+						name += "/"
+					}
+					lbl := material.Body2(r.Theme, name)
+					lbl.MaxLines, lbl.Color = 1, colBlack
+					if item.IsDir {
+						lbl.Color = colDirBlue
+					}
+					return lbl.Layout(gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					if item.IsDir {
+						return layout.Dimensions{}
+					}
+					lbl := material.Caption(r.Theme, formatSize(item.Size))
+					lbl.Color = colGray
+					return lbl.Layout(gtx)
+				}),
+			)
+		})
+	call := macro.Stop()
+
+	if selected {
+		paint.FillShape(gtx.Ops, colSelected, clip.Rect{Max: dims.Size}.Op())
+	}
+	call.Add(gtx.Ops)
+	return dims
+}
@@ -4,6 +4,7 @@ import (
 	"image"
 	"path/filepath"
 	"strings"
+	"unicode/utf8"
 
 	"gioui.org/font"
 	"gioui.org/layout"
@@ -242,6 +243,21 @@ func (r *Renderer) layoutTextPreview(gtx layout.Context) layout.Dimensions {
 				lbl.Font.Typeface = "monospace"
 				lbl.TextSize = unit.Sp(12)
 
+				// Highlight content-search matches by selecting them
+				for h, hit := range r.previewHits {
+					if hit.Line != i+1 || h >= len(r.previewHitLabels) {
+						continue
+					}
+					end := min(hit.Column+hit.End-hit.Start, len(line))
+					if hit.Column < end {
+						sel := &r.previewHitLabels[h]
+						sel.SetText(line)
+						sel.SetCaret(utf8.RuneCountInString(line[:hit.Column]), utf8.RuneCountInString(line[:end]))
+						lbl.State, lbl.SelectionColor = sel, colMatchBg
+					}
+					break
+				}
+
 				// Syntax coloring for JSON
 				if r.previewIsJSON {
 					// Color keys vs values (simple heuristic)
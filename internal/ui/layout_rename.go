@@ -0,0 +1,252 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/justyntemme/razor/internal/fs"
+)
+
+// Batch rename dialog: rules on top, live old → new preview below
+
+// batchRenameCases maps the case radio buttons to transforms
+var batchRenameCases = []struct {
+	key, label string
+	value      fs.RenameCase
+}{
+	{"keep", "Keep case", fs.CaseKeep},
+	{"lower", "lowercase", fs.CaseLower},
+	{"upper", "UPPERCASE", fs.CaseUpper},
+	{"title", "Title Case", fs.CaseTitle},
+}
+
+// batchRenameRulesFromEditors reads the rules currently entered in the dialog
+func (r *Renderer) batchRenameRulesFromEditors() fs.RenameRules {
+	rules := fs.RenameRules{
+		Find:      r.batchFindEditor.Text(),
+		Replace:   r.batchReplaceEditor.Text(),
+		Regex:     r.batchRegexCheck.Value,
+		Template:  r.batchTemplateEditor.Text(),
+		Start:     1,
+		Extension: strings.TrimSpace(r.batchExtEditor.Text()),
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(r.batchStartEditor.Text())); err == nil {
+		rules.Start = n
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(r.batchPaddingEditor.Text())); err == nil {
+		rules.Padding = min(max(n, 0), 10)
+	}
+	for _, c := range batchRenameCases {
+		if c.key == r.batchCaseEnum.Value {
+			rules.Case = c.value
+		}
+	}
+	return rules
+}
+
+// updateBatchRenamePreview recomputes the plan when the rules changed since the last frame
+func (r *Renderer) updateBatchRenamePreview() {
+	rules := r.batchRenameRulesFromEditors()
+	if rules == r.batchRenameRules {
+		return
+	}
+	r.batchRenameRules = rules
+	r.batchRenamePlan, r.batchRenameErr = nil, ""
+	plan, err := fs.PlanRename(r.batchRenameItems, rules, nil)
+	if err != nil {
+		r.batchRenameErr = err.Error()
+		return
+	}
+	r.batchRenamePlan = plan
+}
+
+// batchRenameReady reports whether the previewed plan can be applied
+func (r *Renderer) batchRenameReady() bool {
+	return r.batchRenamePlan != nil && r.batchRenamePlan.Problems == 0 && r.batchRenamePlan.Changes > 0
+}
+
+func (r *Renderer) layoutBatchRenameDialog(gtx layout.Context, eventOut *UIEvent) layout.Dimensions {
+	if !r.batchRenameOpen {
+		return layout.Dimensions{}
+	}
+
+	r.updateBatchRenamePreview()
+
+	if r.batchRenameOK.Clicked(gtx) {
+		r.onLeftClick()
+		if r.batchRenameReady() {
+			r.batchRenameOpen = false
+			paths := make([]string, len(r.batchRenameItems))
+			for i, item := range r.batchRenameItems {
+				paths[i] = item.Path
+			}
+			*eventOut = UIEvent{Action: ActionBatchRename, Paths: paths, Rename: r.batchRenameRules}
+		}
+	}
+	if r.batchRenameCancel.Clicked(gtx) {
+		r.onLeftClick()
+		r.batchRenameOpen = false
+	}
+
+	// Summary line under the rules
+	summary, summaryColor := "", colGray
+	switch plan := r.batchRenamePlan; {
+	case plan == nil:
+		summary, summaryColor = r.batchRenameErr, colDanger
+	case plan.Problems > 0:
+		summary, summaryColor = fmt.Sprintf("%d of %d names conflict", plan.Problems, len(plan.Entries)), colDanger
+	default:
+		summary = fmt.Sprintf("%d of %d items will be renamed", plan.Changes, len(plan.Entries))
+		if plan.Cycles > 0 {
+			summary += fmt.Sprintf(" (%d swap names)", plan.Cycles)
+		}
+	}
+
+	applyStyle := ButtonPrimary
+	if !r.batchRenameReady() {
+		applyStyle = ButtonDisabled
+	}
+
+	return r.modalBackdrop(gtx, 600, &r.batchRenameCancel, func(gtx layout.Context) layout.Dimensions {
+		return r.modalContent(gtx, fmt.Sprintf("Rename %d Items", len(r.batchRenameItems)), colBlack,
+			// Body content
+			func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+							layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+								return r.batchRenameField(gtx, "Find", &r.batchFindEditor, "text to replace")
+							}),
+							layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+							layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+								return r.batchRenameField(gtx, "Replace with", &r.batchReplaceEditor, "")
+							}),
+						)
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						cb := material.CheckBox(r.Theme, &r.batchRegexCheck, "Regular expression ($1 inserts a group)")
+						cb.Color = colBlack
+						return cb.Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return r.batchRenameField(gtx, "Name template: {name} {n} {date} {time} {year} {month} {day}", &r.batchTemplateEditor, "{name}")
+					}),
+					layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+							layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+								return r.batchRenameField(gtx, "Start {n} at", &r.batchStartEditor, "1")
+							}),
+							layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+							layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+								return r.batchRenameField(gtx, "Pad {n} to digits", &r.batchPaddingEditor, "0")
+							}),
+							layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+							layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+								return r.batchRenameField(gtx, "New extension", &r.batchExtEditor, "keep")
+							}),
+						)
+					}),
+					layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						children := make([]layout.FlexChild, 0, len(batchRenameCases))
+						for _, c := range batchRenameCases {
+							children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								rb := material.RadioButton(r.Theme, &r.batchCaseEnum, c.key, c.label)
+								rb.Color = colBlack
+								return layout.Inset{Right: unit.Dp(12)}.Layout(gtx, rb.Layout)
+							}))
+						}
+						return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, children...)
+					}),
+					layout.Rigid(layout.Spacer{Height: unit.Dp(12)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						lbl := material.Body2(r.Theme, summary)
+						lbl.Color = summaryColor
+						return lbl.Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+					layout.Rigid(r.layoutBatchRenamePreview),
+				)
+			},
+			// Button row
+			func(gtx layout.Context) layout.Dimensions {
+				return r.dialogButtonRow(gtx, &r.batchRenameCancel, &r.batchRenameOK, "Cancel", "Rename", applyStyle)
+			},
+		)
+	})
+}
+
+// batchRenameField is a caption above a bordered single-line editor
+func (r *Renderer) batchRenameField(gtx layout.Context, label string, ed *widget.Editor, hint string) layout.Dimensions {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Caption(r.Theme, label)
+			lbl.Color = colGray
+			return lbl.Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(4)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return widget.Border{Color: colLightGray, Width: unit.Dp(1), CornerRadius: unit.Dp(4)}.Layout(gtx,
+				func(gtx layout.Context) layout.Dimensions {
+					return layout.UniformInset(unit.Dp(6)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						return material.Editor(r.Theme, ed, hint).Layout(gtx)
+					})
+				})
+		}),
+	)
+}
+
+// layoutBatchRenamePreview lists every item as old → new, with conflicts in red
+func (r *Renderer) layoutBatchRenamePreview(gtx layout.Context) layout.Dimensions {
+	plan := r.batchRenamePlan
+	if plan == nil {
+		return layout.Dimensions{}
+	}
+
+	// Long selections scroll instead of growing off screen
+	gtx.Constraints.Max.Y = min(gtx.Constraints.Max.Y, gtx.Dp(unit.Dp(240)))
+	r.batchRenameList.Axis = layout.Vertical
+	return r.batchRenameList.Layout(gtx, len(plan.Entries), func(gtx layout.Context, i int) layout.Dimensions {
+		e := plan.Entries[i]
+		oldName, newName := filepath.Base(e.OldPath), filepath.Base(e.NewPath)
+
+		text, col := oldName+"  →  "+newName, colBlack
+		if !e.Changed() {
+			text, col = oldName+"  (unchanged)", colGray
+		}
+		note, noteCol := "", colGray
+		switch {
+		case e.Problem != "":
+			note, noteCol, col = e.Problem, colDanger, colDanger
+		case e.InCycle:
+			note = "swaps name with another item"
+		}
+
+		return layout.Inset{Bottom: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Body2(r.Theme, text)
+					lbl.Color, lbl.MaxLines = col, 1
+					return lbl.Layout(gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					if note == "" {
+						return layout.Dimensions{}
+					}
+					lbl := material.Caption(r.Theme, note)
+					lbl.Color, lbl.MaxLines = noteCol, 1
+					return lbl.Layout(gtx)
+				}),
+			)
+		})
+	})
+}
@@ -17,7 +17,7 @@ import (
 // Search bar layout - search input, history dropdown, directive pills
 
 // layoutSearchWithHistory renders the search box (dropdown is rendered as overlay in main Layout)
-func (r *Renderer) layoutSearchWithHistory(gtx layout.Context, hasDirectivePrefix, showClearBtn bool) layout.Dimensions {
+func (r *Renderer) layoutSearchWithHistory(gtx layout.Context, hasDirectivePrefix, showClearBtn, showSaveBtn bool) layout.Dimensions {
 	// Check for clicks on search box area to dismiss menus
 	if r.searchBoxClick.Clicked(gtx) {
 		r.onLeftClick()
@@ -46,6 +46,20 @@ func (r *Renderer) layoutSearchWithHistory(gtx layout.Context, hasDirectivePrefi
 								ed.TextSize = unit.Sp(13)
 								return ed.Layout(gtx)
 							}),
+							// Save search button
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								if !showSaveBtn {
+									return layout.Dimensions{}
+								}
+								return material.Clickable(gtx, &r.searchSaveBtn, func(gtx layout.Context) layout.Dimensions {
+									return layout.Inset{Left: unit.Dp(4), Right: unit.Dp(4)}.Layout(gtx,
+										func(gtx layout.Context) layout.Dimensions {
+											lbl := material.Body2(r.Theme, "☆")
+											lbl.Color = colAccent
+											return lbl.Layout(gtx)
+										})
+								})
+							}),
 							// Clear button
 							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 								if !showClearBtn {
@@ -188,12 +202,21 @@ func (r *Renderer) renderDirectivePill(gtx layout.Context, d DetectedDirective)
 	case "size":
 		bgColor = color.NRGBA{R: 227, G: 242, B: 253, A: 255} // Light blue
 		textColor = color.NRGBA{R: 21, G: 101, B: 192, A: 255} // Dark blue
-	case "modified":
+	case "modified", "created", "accessed":
 		bgColor = color.NRGBA{R: 252, G: 228, B: 236, A: 255} // Light pink
 		textColor = color.NRGBA{R: 173, G: 20, B: 87, A: 255} // Dark pink
 	case "recursive", "depth":
 		bgColor = color.NRGBA{R: 255, G: 249, B: 196, A: 255} // Light yellow
 		textColor = color.NRGBA{R: 158, G: 118, B: 0, A: 255} // Dark yellow/gold
+	case "ignore", "index":
+		bgColor = color.NRGBA{R: 236, G: 239, B: 241, A: 255} // Light blue-gray
+		textColor = color.NRGBA{R: 69, G: 90, B: 100, A: 255} // Dark blue-gray
+	case "type", "perm", "owner", "group", "empty":
+		bgColor = color.NRGBA{R: 239, G: 235, B: 233, A: 255} // Light brown
+		textColor = color.NRGBA{R: 93, G: 64, B: 55, A: 255}  // Dark brown
+	case "path":
+		bgColor = color.NRGBA{R: 224, G: 247, B: 250, A: 255} // Light cyan
+		textColor = color.NRGBA{R: 0, G: 131, B: 143, A: 255} // Dark cyan
 	}
 
 	// Short label for the directive type
@@ -209,9 +232,25 @@ func (r *Renderer) renderDirectivePill(gtx layout.Context, d DetectedDirective)
 			typeLabel = "name"
 		case "recursive":
 			typeLabel = "rec"
+		case "ignore":
+			typeLabel = "ign"
+		case "index":
+			typeLabel = "idx"
+		case "owner":
+			typeLabel = "own"
+		case "group":
+			typeLabel = "grp"
+		case "created":
+			typeLabel = "born"
+		case "accessed":
+			typeLabel = "acc"
 		}
 	}
 
+	if d.Negated {
+		typeLabel = "not " + typeLabel
+	}
+
 	return layout.Stack{}.Layout(gtx,
 		layout.Expanded(func(gtx layout.Context) layout.Dimensions {
 			rr := gtx.Dp(unit.Dp(10))
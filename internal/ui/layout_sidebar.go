@@ -228,9 +228,9 @@ func (r *Renderer) layoutFavoritesListContent(gtx layout.Context, state *State,
 			// Render row and capture events (uses previous frame's sidebarDropTarget for visual)
 			rowDims, leftClicked, rightClicked, _, dropEvt := r.renderFavoriteRow(gtx, fav)
 
-			// Track this row as a potential drop target if valid
+			// Track this row as a potential drop target if valid (saved searches aren't)
 			// Store bounds in SIDEBAR-LOCAL coordinates (like file list does)
-			if r.dragSourcePath != "" && fav.Path != r.dragSourcePath {
+			if r.dragSourcePath != "" && fav.Type != FavoriteTypeSearch && fav.Path != r.dragSourcePath {
 				r.sidebarHoverCandidates = append(r.sidebarHoverCandidates, dragHoverCandidate{
 					Path: fav.Path,
 					MinY: cumulativeY,
@@ -253,14 +253,22 @@ func (r *Renderer) layoutFavoritesListContent(gtx layout.Context, state *State,
 				r.menuIsDir = true
 				r.menuIsFav = fav.Type == FavoriteTypeNormal // Only normal favorites can be removed
 				r.menuIsBackground = false
+				r.menuSavedSearch = ""
+				if fav.Type == FavoriteTypeSearch {
+					r.menuSavedSearch = fav.Name
+				}
 			}
 
 			// Handle left-click
 			if leftClicked {
 				r.onLeftClick()
-				if fav.Type == FavoriteTypeTrash {
+				switch fav.Type {
+				case FavoriteTypeTrash:
 					*eventOut = UIEvent{Action: ActionShowTrash}
-				} else {
+				case FavoriteTypeSearch:
+					r.setSearchText(fav.Query)
+					*eventOut = UIEvent{Action: ActionRunSavedSearch, SavedSearch: fav.Name}
+				default:
 					*eventOut = UIEvent{Action: ActionNavigate, Path: fav.Path}
 				}
 			}
@@ -17,6 +17,8 @@ import (
 	"gioui.org/widget/material"
 
 	"github.com/justyntemme/razor/internal/config"
+	"github.com/justyntemme/razor/internal/fs"
+	"github.com/justyntemme/razor/internal/search"
 )
 
 type Renderer struct {
@@ -40,6 +42,7 @@ type Renderer struct {
 	breadcrumbLastClicks  []time.Time        // For double-click detection on segments
 	searchEditor        widget.Editor
 	searchClearBtn      widget.Clickable
+	searchSaveBtn       widget.Clickable // "Save this search" star in the search box
 	searchBoxClick      widget.Clickable // For dismissing menus when clicking search area
 	searchActive        bool
 	lastSearchQuery     string
@@ -51,8 +54,11 @@ type Renderer struct {
 	menuPath            string
 	menuIsDir, menuIsFav bool
 	menuIsBackground    bool // True when menu is shown on empty space (not on a file/folder)
+	menuSavedSearch     string // Name of the saved search the menu is for, if any
+	removeSearchBtn     widget.Clickable
 	openBtn, copyBtn    widget.Clickable
 	cutBtn, pasteBtn    widget.Clickable
+	pasteVerifyBtn      widget.Clickable
 	deleteBtn           widget.Clickable
 	renameBtn           widget.Clickable
 	favBtn              widget.Clickable
@@ -139,6 +145,13 @@ type Renderer struct {
 	createDialogOK     widget.Clickable
 	createDialogCancel widget.Clickable
 
+	// Save search dialog
+	saveSearchOpen   bool
+	saveSearchQuery  string // Query being saved
+	saveSearchEditor widget.Editor
+	saveSearchOK     widget.Clickable
+	saveSearchCancel widget.Clickable
+
 	// Conflict resolution dialog
 	conflictReplaceBtn  widget.Clickable
 	conflictKeepBothBtn widget.Clickable
@@ -146,6 +159,30 @@ type Renderer struct {
 	conflictStopBtn     widget.Clickable
 	conflictApplyToAll  widget.Bool
 
+	// Operation report dialog
+	reportCloseBtn widget.Clickable
+	reportRetryBtn widget.Clickable
+	reportCopyBtn  widget.Clickable
+	reportList     layout.List
+
+	// Batch rename dialog
+	batchRenameOpen     bool
+	batchRenameItems    []fs.RenameItem // Selected items in display order
+	batchFindEditor     widget.Editor
+	batchReplaceEditor  widget.Editor
+	batchTemplateEditor widget.Editor
+	batchStartEditor    widget.Editor
+	batchPaddingEditor  widget.Editor
+	batchExtEditor      widget.Editor
+	batchRegexCheck     widget.Bool
+	batchCaseEnum       widget.Enum
+	batchRenameOK       widget.Clickable
+	batchRenameCancel   widget.Clickable
+	batchRenameList     layout.List
+	batchRenamePlan     *fs.RenamePlan  // Preview for batchRenameRules (nil if the rules are invalid)
+	batchRenameErr      string          // Why the rules are invalid
+	batchRenameRules    fs.RenameRules  // Rules the preview was computed for
+
 	// Column sorting and resizing
 	headerBtns          [4]widget.Clickable
 	SortColumn          SortColumn
@@ -177,6 +214,10 @@ type Renderer struct {
 	// Animation state for indeterminate progress
 	progressAnimStart time.Time
 
+	// Background jobs panel
+	jobsList     layout.List
+	jobControls  map[int64]*jobControls // Buttons per job ID (pruned as jobs disappear)
+
 	// Theme settings
 	DarkMode      bool
 	darkModeCheck widget.Bool
@@ -214,6 +255,10 @@ type Renderer struct {
 	previewWidth        int            // Current preview pane width in pixels (after resize)
 	previewResizeHandle ResizeHandle   // Resize handle for preview pane
 
+	// Content-search matches highlighted in the text preview
+	previewHits      []search.ExternalSearchResult
+	previewHitLabels [search.MaxHitsPerFile]widget.Selectable // Selection state that draws each highlight
+
 	// Markdown preview state
 	previewIsMarkdown     bool             // Whether previewing a markdown file
 	previewMarkdownRender bool             // True = render markdown, False = show raw
@@ -236,6 +281,42 @@ type Renderer struct {
 	isTrashView         bool             // True when viewing trash
 	emptyTrashBtn       widget.Clickable // Button to empty trash (in context menu)
 	permanentDeleteBtn  widget.Clickable // Button to permanently delete (bypass trash)
+	restoreBtn          widget.Clickable // Button to restore from trash (in context menu)
+
+	// Duplicates view state
+	findDupesBtn     widget.Clickable // Context menu item to find duplicates below a folder
+	isDuplicatesView bool             // True when showing Find Duplicates results
+	keepNewestBtn    widget.Clickable // Trash all but the newest of each selected group
+	keepOldestBtn    widget.Clickable // Trash all but the oldest of each selected group
+	hardlinkBtn      widget.Clickable // Replace duplicates with hardlinks
+
+	// Dual-pane state
+	dualPaneBtn   widget.Clickable // File menu item to show or hide the second pane
+	otherPaneBtn  widget.Clickable // Click anywhere in the pane without focus to focus it
+	otherPaneList layout.List      // Scroll position of the pane without focus
+
+	// Compare view state
+	compareBtn         widget.Clickable // Context menu item to compare two selected folders
+	comparePanesBtn    widget.Clickable // File menu item to compare the two panes
+	isCompareView      bool             // True when showing a folder comparison
+	compareContentsBtn widget.Clickable // Compare again by contents (or by size and date)
+	syncRightBtn       widget.Clickable // Dry run of a left-to-right sync
+	syncLeftBtn        widget.Clickable // Dry run of a right-to-left sync
+	syncBothBtn        widget.Clickable // Dry run of a two-way sync
+	syncRunBtn         widget.Clickable // Run the sync of the dry-run dialog
+	syncCancelBtn      widget.Clickable
+	syncPlanList       layout.List
+
+	// Archive state
+	compressBtn      widget.Clickable // Context menu item to compress the selection
+	extractHereBtn   widget.Clickable // Extract an archive into its folder
+	extractFolderBtn widget.Clickable // Extract an archive into a folder named after it
+	compressOpen     bool             // "Compress to..." dialog
+	compressPaths    []string         // Items being compressed
+	compressEditor   widget.Editor    // Archive name, without extension
+	compressFormat   widget.Enum      // Extension of the archive
+	compressOK       widget.Clickable
+	compressCancel   widget.Clickable
 
 	// Preview pane close button
 	previewCloseBtn   widget.Clickable
@@ -262,6 +343,8 @@ func NewRenderer() *Renderer {
 	r.expandedDirs = make(map[string]bool)
 	r.treeIndent = 20 // 20dp per indentation level
 	r.favState.Axis = layout.Vertical
+	r.otherPaneList.Axis = layout.Vertical
+	r.syncPlanList.Axis = layout.Vertical
 	r.driveState.Axis = layout.Vertical
 	r.sidebarScroll.Axis = layout.Vertical
 	r.previewScroll.Axis = layout.Vertical
@@ -277,7 +360,13 @@ func NewRenderer() *Renderer {
 	r.pathEditor.SingleLine, r.pathEditor.Submit = true, true
 	r.searchEditor.SingleLine, r.searchEditor.Submit = true, true
 	r.createDialogEditor.SingleLine, r.createDialogEditor.Submit = true, true
+	r.saveSearchEditor.SingleLine, r.saveSearchEditor.Submit = true, true
+	r.compressEditor.SingleLine, r.compressEditor.Submit = true, true
+	r.compressFormat.Value = ".zip"
 	r.renameEditor.SingleLine, r.renameEditor.Submit = true, true
+	for _, ed := range []*widget.Editor{&r.batchFindEditor, &r.batchReplaceEditor, &r.batchTemplateEditor, &r.batchStartEditor, &r.batchPaddingEditor, &r.batchExtEditor} {
+		ed.SingleLine = true
+	}
 	r.searchEngine.Value = "builtin"
 	r.SelectedEngine = "builtin"
 
@@ -329,6 +418,43 @@ func (r *Renderer) ShowCreateDialog(isDir bool) {
 	r.createDialogEditor.SetText("")
 }
 
+// ShowSaveSearchDialog asks for a name to save query under, suggesting the query itself
+func (r *Renderer) ShowSaveSearchDialog(query string) {
+	r.saveSearchOpen = true
+	r.saveSearchQuery = query
+	r.saveSearchEditor.SetText(query)
+	r.saveSearchEditor.SetCaret(r.saveSearchEditor.Len(), 0) // Select all so typing replaces it
+}
+
+// ShowCompressDialog asks for the name and format of an archive of the
+// selection, suggesting the item's name, or the folder's for several items
+func (r *Renderer) ShowCompressDialog(state *State) {
+	paths := r.collectSelectedPaths(state)
+	if len(paths) == 0 {
+		return
+	}
+	name := filepath.Base(state.CurrentPath)
+	if len(paths) == 1 {
+		name = filepath.Base(paths[0])
+	}
+	r.compressOpen = true
+	r.compressPaths = paths
+	r.compressEditor.SetText(name)
+	r.compressEditor.SetCaret(r.compressEditor.Len(), 0) // Select all so typing replaces it
+}
+
+// setSearchText puts a query that is being run into the search box without
+// triggering search-as-you-type or the directive restore
+func (r *Renderer) setSearchText(query string) {
+	r.searchEditor.SetText(query)
+	r.lastSearchQuery = query
+	r.lastHistoryQuery = query // Prevent re-fetching
+	r.searchActive = true
+	r.directiveRestored = true
+	r.searchHistoryVisible = false
+	r.searchHistoryItems = nil
+}
+
 func (r *Renderer) StartRename(index int, path, name string, isDir bool) {
 	r.renameIndex = index
 	r.renamePath = path
@@ -355,6 +481,36 @@ func (r *Renderer) CancelRename() {
 	r.renamePath = ""
 }
 
+// ShowBatchRename opens the batch rename dialog for a multi-selection.
+// It returns false (and does nothing) when fewer than two items are selected.
+func (r *Renderer) ShowBatchRename(state *State) bool {
+	if len(state.SelectedIndices) < 2 {
+		return false
+	}
+
+	// Walk the entries so numbering follows the on-screen order
+	r.batchRenameItems = r.batchRenameItems[:0]
+	for i, entry := range state.Entries {
+		if state.SelectedIndices[i] {
+			r.batchRenameItems = append(r.batchRenameItems, fs.RenameItem{Path: entry.Path, IsDir: entry.IsDir, ModTime: entry.ModTime})
+		}
+	}
+
+	r.CancelRename()
+	r.batchFindEditor.SetText("")
+	r.batchReplaceEditor.SetText("")
+	r.batchTemplateEditor.SetText("{name}")
+	r.batchStartEditor.SetText("1")
+	r.batchPaddingEditor.SetText("0")
+	r.batchExtEditor.SetText("")
+	r.batchRegexCheck.Value = false
+	r.batchCaseEnum.Value = "keep"
+	r.batchRenamePlan = nil
+	r.batchRenameRules = fs.RenameRules{Template: "-"} // Forces the first preview
+	r.batchRenameOpen = true
+	return true
+}
+
 // ResetMultiSelect exits multi-select mode. Call this when navigating to a new directory.
 func (r *Renderer) ResetMultiSelect() {
 	r.multiSelectMode = false
@@ -48,7 +48,7 @@ func (r *Renderer) processGlobalInput(gtx layout.Context, state *State, keyTag e
 	}
 
 	// Skip if modal dialogs are open
-	if r.isEditing || r.settingsOpen || r.deleteConfirmOpen || r.createDialogOpen {
+	if r.isEditing || r.settingsOpen || r.deleteConfirmOpen || r.createDialogOpen || r.saveSearchOpen || r.compressOpen || r.batchRenameOpen {
 		return UIEvent{}
 	}
 
@@ -72,6 +72,29 @@ func (r *Renderer) processGlobalInput(gtx layout.Context, state *State, keyTag e
 
 		// Check configurable hotkeys
 		if r.hotkeys != nil {
+			// Dual pane - checked first, as the pane keys take over from
+			// refresh (F5) and expand (Tab) while both panes are shown
+			if r.hotkeys.DualPane.Matches(k) {
+				return UIEvent{Action: ActionToggleDualPane}
+			}
+			if state.DualPane {
+				if r.hotkeys.SwitchPane.Matches(k) {
+					return UIEvent{Action: ActionSwitchPane}
+				}
+				if r.hotkeys.CopyToPane.Matches(k) {
+					if state.SelectedIndex >= 0 {
+						return UIEvent{Action: ActionCopyToPane, Paths: r.collectSelectedPaths(state)}
+					}
+					continue
+				}
+				if r.hotkeys.MoveToPane.Matches(k) {
+					if state.SelectedIndex >= 0 {
+						return UIEvent{Action: ActionMoveToPane, Paths: r.collectSelectedPaths(state)}
+					}
+					continue
+				}
+			}
+
 			// File operations
 			if r.hotkeys.Copy.Matches(k) && state.SelectedIndex >= 0 {
 				paths := r.collectSelectedPaths(state)
@@ -104,6 +127,9 @@ func (r *Renderer) processGlobalInput(gtx layout.Context, state *State, keyTag e
 					return UIEvent{Action: ActionPermanentDelete, Paths: paths}
 				}
 			}
+			if r.hotkeys.Rename.Matches(k) && r.ShowBatchRename(state) {
+				continue
+			}
 			if r.hotkeys.Rename.Matches(k) && state.SelectedIndex >= 0 {
 				entry := state.Entries[state.SelectedIndex]
 				r.StartRename(state.SelectedIndex, entry.Path, entry.Name, entry.IsDir)
@@ -120,6 +146,12 @@ func (r *Renderer) processGlobalInput(gtx layout.Context, state *State, keyTag e
 			if r.hotkeys.SelectAll.Matches(k) && len(state.Entries) > 0 {
 				return UIEvent{Action: ActionSelectAll}
 			}
+			if r.hotkeys.Undo.Matches(k) {
+				return UIEvent{Action: ActionUndo}
+			}
+			if r.hotkeys.Redo.Matches(k) {
+				return UIEvent{Action: ActionRedo}
+			}
 
 			// Navigation
 			if r.hotkeys.Back.Matches(k) && state.CanBack {
@@ -146,7 +178,8 @@ func (r *Renderer) processGlobalInput(gtx layout.Context, state *State, keyTag e
 				if r.previewVisible {
 					r.HidePreview()
 				} else if state.SelectedIndex >= 0 && state.SelectedIndex < len(state.Entries) {
-					r.ShowPreview(state.Entries[state.SelectedIndex].Path)
+					entry := &state.Entries[state.SelectedIndex]
+					r.ShowPreviewHits(entry.Path, entry.Hits)
 				}
 				continue
 			}
@@ -400,11 +433,12 @@ func (r *Renderer) buildHotkeyFilters(keyTag event.Tag) []event.Filter {
 	// Create a filter for each hotkey
 	hotkeys := []config.Hotkey{
 		r.hotkeys.Copy, r.hotkeys.Cut, r.hotkeys.Paste, r.hotkeys.Delete, r.hotkeys.PermanentDelete,
-		r.hotkeys.Rename, r.hotkeys.NewFile, r.hotkeys.NewFolder, r.hotkeys.SelectAll,
+		r.hotkeys.Rename, r.hotkeys.NewFile, r.hotkeys.NewFolder, r.hotkeys.SelectAll, r.hotkeys.Undo, r.hotkeys.Redo,
 		r.hotkeys.Back, r.hotkeys.Forward, r.hotkeys.Up, r.hotkeys.Home, r.hotkeys.Refresh,
 		r.hotkeys.FocusSearch, r.hotkeys.TogglePreview, r.hotkeys.ToggleHidden, r.hotkeys.ToggleViewMode, r.hotkeys.Escape,
 		r.hotkeys.NewTab, r.hotkeys.CloseTab, r.hotkeys.NextTab, r.hotkeys.PrevTab,
 		r.hotkeys.Tab1, r.hotkeys.Tab2, r.hotkeys.Tab3, r.hotkeys.Tab4, r.hotkeys.Tab5, r.hotkeys.Tab6,
+		r.hotkeys.DualPane, r.hotkeys.SwitchPane, r.hotkeys.CopyToPane, r.hotkeys.MoveToPane,
 	}
 
 	// Use a map to deduplicate filters with same key+modifiers
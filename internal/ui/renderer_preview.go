@@ -4,20 +4,26 @@ import (
 	"encoding/json"
 	"fmt"
 	"image"
-	"os"
 	"path/filepath"
 	"strings"
 
+	"gioui.org/layout"
 	"gioui.org/op/paint"
 
 	"github.com/justyntemme/razor/internal/debug"
+	"github.com/justyntemme/razor/internal/fs"
+	"github.com/justyntemme/razor/internal/search"
 )
 
+// previewHitContext is how many lines stay visible above the first match
+const previewHitContext = 3
+
 // File preview loading and state management
 
 // ShowPreview loads and displays the preview pane for the given file
 func (r *Renderer) ShowPreview(path string) error {
 	debug.Log(debug.UI, "ShowPreview called for: %s", path)
+	r.previewHits = nil
 
 	// Check file extension
 	ext := strings.ToLower(filepath.Ext(path))
@@ -56,7 +62,7 @@ func (r *Renderer) ShowPreview(path string) error {
 	}
 
 	// Check file size
-	info, err := os.Stat(path)
+	info, err := fs.Stat(path)
 	if err != nil {
 		r.previewError = fmt.Sprintf("Cannot access file: %v", err)
 		r.previewVisible = true
@@ -102,11 +108,28 @@ func (r *Renderer) ShowPreview(path string) error {
 	return r.loadTextPreview(path, ext)
 }
 
+// ShowPreviewHits shows the preview of a content-search result, scrolled to
+// its first matching line with the matches highlighted. Without hits it is
+// the same as ShowPreview.
+func (r *Renderer) ShowPreviewHits(path string, hits []search.ExternalSearchResult) error {
+	err := r.ShowPreview(path)
+	// Line numbers only hold for the raw text (JSON is reformatted)
+	if err != nil || len(hits) == 0 || !r.previewVisible || r.previewIsImage || r.previewIsJSON || r.previewContent == "" {
+		return err
+	}
+	r.previewHits = hits
+	if (r.previewIsMarkdown && r.previewMarkdownRender) || (r.previewIsOrgmode && r.previewOrgmodeRender) {
+		return nil
+	}
+	r.previewScroll.Position = layout.Position{First: max(hits[0].Line-1-previewHitContext, 0)}
+	return nil
+}
+
 // loadImagePreview loads an image file for preview
 func (r *Renderer) loadImagePreview(path string) error {
 	debug.Log(debug.UI, "loadImagePreview: loading %s", path)
 
-	file, err := os.Open(path)
+	file, err := fs.Open(path)
 	if err != nil {
 		debug.Log(debug.UI, "loadImagePreview: cannot open file: %v", err)
 		r.previewError = fmt.Sprintf("Cannot open file: %v", err)
@@ -163,7 +186,7 @@ func (r *Renderer) loadImagePreview(path string) error {
 
 // loadTextPreview loads a text file for preview
 func (r *Renderer) loadTextPreview(path, ext string) error {
-	data, err := os.ReadFile(path)
+	data, err := fs.ReadFile(path)
 	if err != nil {
 		r.previewError = fmt.Sprintf("Cannot read file: %v", err)
 		r.previewVisible = true
@@ -216,6 +239,7 @@ func (r *Renderer) HidePreview() {
 	r.previewPath = ""
 	r.previewContent = ""
 	r.previewError = ""
+	r.previewHits = nil
 	r.previewIsImage = false
 	r.previewImage = paint.ImageOp{}
 	r.previewImageSize = image.Point{}
@@ -235,6 +259,8 @@ func (r *Renderer) SetRecentView(isRecent bool) {
 	r.isRecentView = isRecent
 	if isRecent {
 		r.isTrashView = false // Can't be in both views
+		r.isDuplicatesView = false
+		r.isCompareView = false
 	}
 }
 
@@ -6,8 +6,10 @@ import (
 	"image/color"
 	"io"
 	"path/filepath"
+	"strconv"
 	"strings"
 
+	"gioui.org/f32"
 	"gioui.org/font"
 	"gioui.org/io/event"
 	"gioui.org/io/key"
@@ -23,6 +25,8 @@ import (
 	"gioui.org/widget/material"
 
 	"github.com/justyntemme/razor/internal/debug"
+	"github.com/justyntemme/razor/internal/fs"
+	"github.com/justyntemme/razor/internal/search"
 )
 
 // File list row rendering - columns, rows, favorites, drives
@@ -616,10 +620,90 @@ func (r *Renderer) renderRowContent(gtx layout.Context, item *UIEntry, isRenamin
 				}),
 			)
 
-			return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx, children...)
+			if (len(item.Hits) == 0 && item.DupGroup == 0 && item.Compare == fs.CompareNone) || isRenaming {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx, children...)
+			}
+
+			// Content-search results list their matching lines under the name
+			rows := []layout.FlexChild{layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx, children...)
+			})}
+			hitIndent := indentWidth + unit.Dp(27)
+			if showCheckbox {
+				hitIndent += unit.Dp(44) // checkbox + spacer + divider + spacer
+			}
+			// Duplicates show their group and folder, since copies often share a name
+			if item.DupGroup > 0 {
+				rows = append(rows, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return layout.Inset{Top: unit.Dp(2), Left: hitIndent}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						lbl := material.Caption(r.Theme, fmt.Sprintf("Group %d · %s", item.DupGroup, filepath.Dir(item.Path)))
+						lbl.Color, lbl.MaxLines = colGray, 1
+						return lbl.Layout(gtx)
+					})
+				}))
+			}
+			// Compared paths show how the two folders differ there
+			if item.Compare != fs.CompareNone {
+				rows = append(rows, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return layout.Inset{Top: unit.Dp(2), Left: hitIndent}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						lbl := material.Caption(r.Theme, item.Compare.String())
+						lbl.Color, lbl.MaxLines = colAccent, 1
+						switch item.Compare {
+						case fs.CompareIdentical:
+							lbl.Color = colGray
+						case fs.CompareDiffers:
+							lbl.Color = colDanger
+						}
+						return lbl.Layout(gtx)
+					})
+				}))
+			}
+			for _, hit := range item.Hits {
+				rows = append(rows, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return layout.Inset{Top: unit.Dp(2), Left: hitIndent}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						return r.layoutHitLine(gtx, hit)
+					})
+				}))
+			}
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx, rows...)
 		})
 }
 
+// layoutHitLine shows one matching line of a content-search result as
+// "line: text" with the match in bold on a highlight
+func (r *Renderer) layoutHitLine(gtx layout.Context, hit search.ExternalSearchResult) layout.Dimensions {
+	label := func(s string, col color.NRGBA) material.LabelStyle {
+		lbl := material.Caption(r.Theme, s)
+		lbl.Font.Typeface = "monospace"
+		lbl.Color, lbl.MaxLines = col, 1
+		return lbl
+	}
+	start, end := hit.Start, hit.End
+	if start >= end || end > len(hit.Content) {
+		start, end = 0, 0
+	}
+
+	return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+		layout.Rigid(label(strconv.Itoa(hit.Line)+": ", colDisabled).Layout),
+		layout.Rigid(label(hit.Content[:start], colGray).Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if start == end {
+				return layout.Dimensions{}
+			}
+			lbl := label(hit.Content[start:end], colBlack)
+			lbl.Font.Weight = font.Bold
+			return layout.Stack{}.Layout(gtx,
+				layout.Expanded(func(gtx layout.Context) layout.Dimensions {
+					paint.FillShape(gtx.Ops, colMatchBg, clip.Rect{Max: gtx.Constraints.Min}.Op())
+					return layout.Dimensions{Size: gtx.Constraints.Min}
+				}),
+				layout.Stacked(lbl.Layout),
+			)
+		}),
+		layout.Flexed(1, label(hit.Content[end:], colGray).Layout),
+	)
+}
+
 // renderFavoriteRow renders a favorite item. Returns dimensions, left-clicked, right-clicked, click position, and drop event.
 func (r *Renderer) renderFavoriteRow(gtx layout.Context, fav *FavoriteItem) (layout.Dimensions, bool, bool, image.Point, *UIEvent) {
 	// Check for left-click BEFORE layout
@@ -689,28 +773,34 @@ func (r *Renderer) renderFavoriteRow(gtx layout.Context, fav *FavoriteItem) (lay
 		defer clip.Rect{Max: gtx.Constraints.Max}.Push(gtx.Ops).Pop()
 		event.Op(gtx.Ops, &fav.RightClickTag)
 
-		// Register as drop target with PassOp so clicks still work
-		passStack := pointer.PassOp{}.Push(gtx.Ops)
-		event.Op(gtx.Ops, &fav.DropTag)
-		passStack.Pop()
+		// Register as drop target with PassOp so clicks still work (not for saved searches)
+		if fav.Type != FavoriteTypeSearch {
+			passStack := pointer.PassOp{}.Push(gtx.Ops)
+			event.Op(gtx.Ops, &fav.DropTag)
+			passStack.Pop()
+		}
 
 		// Record content first to get actual dimensions
 		macro := op.Record(gtx.Ops)
 		contentDims := layout.Inset{Top: unit.Dp(8), Bottom: unit.Dp(8), Left: unit.Dp(12), Right: unit.Dp(12)}.Layout(gtx,
 			func(gtx layout.Context) layout.Dimensions {
 				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
-					// Icon for trash
+					// Icon for trash and saved searches
 					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-						if fav.Type == FavoriteTypeTrash {
-							size := gtx.Dp(14)
+						size := gtx.Dp(14)
+						switch fav.Type {
+						case FavoriteTypeTrash:
 							r.drawTrashIcon(gtx.Ops, size, colGray)
-							return layout.Dimensions{Size: image.Pt(size, size)}
+						case FavoriteTypeSearch:
+							r.drawSearchIcon(gtx.Ops, size, colAccent)
+						default:
+							return layout.Dimensions{}
 						}
-						return layout.Dimensions{}
+						return layout.Dimensions{Size: image.Pt(size, size)}
 					}),
-					// Spacer between icon and text (only for trash)
+					// Spacer between icon and text (only for trash and saved searches)
 					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-						if fav.Type == FavoriteTypeTrash {
+						if fav.Type != FavoriteTypeNormal {
 							return layout.Spacer{Width: unit.Dp(8)}.Layout(gtx)
 						}
 						return layout.Dimensions{}
@@ -747,6 +837,31 @@ func (r *Renderer) renderFavoriteRow(gtx layout.Context, fav *FavoriteItem) (lay
 	return dims, leftClicked, rightClicked, clickPos, dropEvent
 }
 
+// drawSearchIcon draws a magnifying glass icon
+func (r *Renderer) drawSearchIcon(ops *op.Ops, size int, iconColor color.NRGBA) {
+	s := float32(size)
+
+	// Lens (outline)
+	lens := clip.Ellipse{
+		Min: image.Pt(1, 1),
+		Max: image.Pt(int(s*0.7), int(s*0.7)),
+	}
+	paint.FillShape(ops, iconColor, clip.Stroke{
+		Path:  lens.Path(ops),
+		Width: s * 0.12,
+	}.Op())
+
+	// Handle
+	var path clip.Path
+	path.Begin(ops)
+	path.MoveTo(f32.Pt(s*0.62, s*0.62))
+	path.LineTo(f32.Pt(s*0.92, s*0.92))
+	paint.FillShape(ops, iconColor, clip.Stroke{
+		Path:  path.End(),
+		Width: s * 0.14,
+	}.Op())
+}
+
 // drawTrashIcon draws a simple trash can icon
 func (r *Renderer) drawTrashIcon(ops *op.Ops, size int, iconColor color.NRGBA) {
 	s := float32(size)
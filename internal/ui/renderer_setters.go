@@ -110,6 +110,8 @@ func (r *Renderer) SetTrashView(active bool) {
 	r.isTrashView = active
 	if active {
 		r.isRecentView = false // Can't be in both views
+		r.isDuplicatesView = false
+		r.isCompareView = false
 	}
 }
 
@@ -118,6 +120,42 @@ func (r *Renderer) IsTrashView() bool {
 	return r.isTrashView
 }
 
+// SetDuplicatesView sets whether Find Duplicates results are shown
+func (r *Renderer) SetDuplicatesView(active bool) {
+	r.isDuplicatesView = active
+	if active {
+		r.isRecentView = false
+		r.isTrashView = false
+		r.isCompareView = false
+	}
+}
+
+// IsDuplicatesView returns whether Find Duplicates results are shown
+func (r *Renderer) IsDuplicatesView() bool {
+	return r.isDuplicatesView
+}
+
+// SetCompareView sets whether a folder comparison is shown
+func (r *Renderer) SetCompareView(active bool) {
+	r.isCompareView = active
+	if active {
+		r.isRecentView = false
+		r.isTrashView = false
+		r.isDuplicatesView = false
+	}
+}
+
+// IsCompareView returns whether a folder comparison is shown
+func (r *Renderer) IsCompareView() bool {
+	return r.isCompareView
+}
+
+// SwapPanes exchanges the scroll positions of the file list and the other
+// pane when focus moves between the panes of dual-pane mode
+func (r *Renderer) SwapPanes() {
+	r.listState.Position, r.otherPaneList.Position = r.otherPaneList.Position, r.listState.Position
+}
+
 // trackVisibleImage checks if a file path is an image and adds it to the visible list.
 // This is called during list layout for each visible item.
 func (r *Renderer) trackVisibleImage(path string) {
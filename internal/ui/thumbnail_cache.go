@@ -3,7 +3,6 @@ package ui
 import (
 	"container/list"
 	"image"
-	"os"
 	"strings"
 	"sync"
 
@@ -11,6 +10,7 @@ import (
 	"golang.org/x/image/draw"
 
 	"github.com/justyntemme/razor/internal/debug"
+	"github.com/justyntemme/razor/internal/fs"
 )
 
 // ThumbnailCache provides an LRU cache for image thumbnails.
@@ -150,7 +150,7 @@ func (tc *ThumbnailCache) loadThumbnail(path string) {
 	debug.Log(debug.UI, "ThumbnailCache: loading %s", path)
 
 	// Open and decode the image
-	file, err := os.Open(path)
+	file, err := fs.Open(path)
 	if err != nil {
 		debug.Log(debug.UI, "ThumbnailCache: failed to open %s: %v", path, err)
 		return
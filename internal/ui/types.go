@@ -14,6 +14,9 @@ import (
 	"gioui.org/op/paint"
 	"gioui.org/unit"
 	"gioui.org/widget"
+
+	"github.com/justyntemme/razor/internal/fs"
+	"github.com/justyntemme/razor/internal/search"
 )
 
 type UIAction int
@@ -82,9 +85,10 @@ const (
 	ActionFocusSearch
 	ActionJumpToLetter // Jump to file starting with letter (uses NewIndex for target)
 	// Trash actions
-	ActionShowTrash       // Show trash view
-	ActionEmptyTrash      // Empty all trash
-	ActionPermanentDelete // Delete permanently (Shift+Delete)
+	ActionShowTrash        // Show trash view
+	ActionEmptyTrash       // Empty all trash
+	ActionPermanentDelete  // Delete permanently (Shift+Delete)
+	ActionRestoreFromTrash // Restore trashed items to their original location (uses Paths)
 	// Tree view actions
 	ActionExpandDir   // Expand a directory inline (uses Path)
 	ActionCollapseDir // Collapse an expanded directory (uses Path)
@@ -92,6 +96,47 @@ const (
 	ActionOpenTerminal // Open terminal in directory (uses Path)
 	// View mode action
 	ActionChangeViewMode // Change between list/grid view
+	// Undo/redo actions
+	ActionUndo // Undo the last file operation
+	ActionRedo // Redo the last undone file operation
+	// Background job actions (use JobID)
+	ActionPauseJob
+	ActionResumeJob
+	ActionCancelJob
+	ActionDismissJob // Remove a failed job from the jobs panel
+	// Operation report actions
+	ActionRetryReport // Retry the failed items listed in the report
+	// Batch rename
+	ActionBatchRename // Rename Paths with the Rename rules
+	// Saved searches (use SavedSearch)
+	ActionSaveSearch        // Save the query in Path under the name SavedSearch
+	ActionRunSavedSearch    // Run a saved search in its directory
+	ActionRemoveSavedSearch // Remove a saved search from the sidebar
+	// Duplicate finder
+	ActionFindDuplicates    // Find duplicate files below Path
+	ActionResolveDuplicates // Apply Dedupe to the duplicate groups of Paths
+	// Dual pane
+	ActionToggleDualPane // Show or hide the second pane
+	ActionSwitchPane     // Move focus to the other pane
+	ActionCopyToPane     // Copy Paths into the other pane's directory
+	ActionMoveToPane     // Move Paths into the other pane's directory
+	// Folder comparison
+	ActionCompareDirs // Compare the two folders in Paths (left first), by content if CompareContents
+	ActionPlanSync    // Show a dry run of syncing Paths (every compared path if empty) in direction Sync
+	ActionRunSync     // Run the sync shown in the dry run
+	// Archives
+	ActionCompress        // Write Paths to a new archive named FileName in the current directory
+	ActionExtractHere     // Extract the archive Path into its folder
+	ActionExtractToFolder // Extract the archive Path into a folder named after it
+)
+
+// DedupeMode is what Resolve Duplicates does with each group of identical files
+type DedupeMode int
+
+const (
+	DedupeKeepNewest DedupeMode = iota // Trash all but the newest file
+	DedupeKeepOldest                   // Trash all but the oldest file
+	DedupeHardlink                     // Replace all but the oldest file with hardlinks to it
 )
 
 type ClipOp int
@@ -201,8 +246,14 @@ func parseBreadcrumbSegments(fullPath string, maxSegments int) []BreadcrumbSegme
 	// Split the path
 	cleanPath := filepath.Clean(fullPath)
 
-	// Handle Unix root or Windows drive
-	if filepath.IsAbs(cleanPath) {
+	// Handle the server of remote storage, Unix root or Windows drive
+	if root, _, ok := fs.SplitRemotePath(cleanPath); ok {
+		segments = append(segments, BreadcrumbSegment{
+			Name: fs.DisplayPath(root),
+			Path: root,
+		})
+		cleanPath = strings.TrimPrefix(cleanPath[len(root):], sep)
+	} else if filepath.IsAbs(cleanPath) {
 		vol := filepath.VolumeName(cleanPath)
 		if vol != "" {
 			// Windows: C:\
@@ -447,6 +498,13 @@ type UIEvent struct {
 	TabIndex           int      // Tab index for tab operations
 	TerminalApp        string   // Selected terminal application ID
 	ViewMode           ViewMode // View mode (list/grid)
+	JobID              int64    // Background job for job panel actions
+	Verify             bool     // Paste: verify checksums of the copies
+	Rename             fs.RenameRules // Batch rename: rules applied to Paths
+	SavedSearch        string         // Name of a saved search
+	Dedupe             DedupeMode     // Resolve duplicates: what to do with each group
+	CompareContents    bool           // Compare folders by content hashes rather than size and date
+	Sync               fs.SyncMode    // Sync direction for ActionPlanSync
 }
 
 type UIEntry struct {
@@ -454,6 +512,9 @@ type UIEntry struct {
 	IsDir      bool
 	Size       int64
 	ModTime    time.Time
+	Hits       []search.ExternalSearchResult // Matching lines of a content-search result
+	DupGroup   int                           // Set of identical files in the duplicates view (0 elsewhere)
+	Compare    fs.CompareStatus              // How the compared folders differ at this path (compare view only)
 	Touch      Touchable   // Combined click, right-click, and drag handling
 	DropTag    struct{}    // Unique tag for drop target registration (address is unique per entry)
 	Checkbox   widget.Bool // For multi-select mode
@@ -480,11 +541,13 @@ type FavoriteItemType int
 const (
 	FavoriteTypeNormal FavoriteItemType = iota // Regular user favorite
 	FavoriteTypeTrash                          // System trash entry
+	FavoriteTypeSearch                         // Saved search, run in Path when clicked
 )
 
 type FavoriteItem struct {
 	Name, Path    string
 	Type          FavoriteItemType // Type of favorite (normal, trash, etc.)
+	Query         string           // Search query of a saved search
 	Clickable     widget.Clickable
 	RightClickTag int
 	DropTag       struct{} // Unique tag for drop target registration (address is unique per entry)
@@ -497,6 +560,48 @@ type ProgressState struct {
 	Total   int64
 }
 
+// JobStatus is the lifecycle state of a background file operation
+type JobStatus int
+
+const (
+	JobQueued JobStatus = iota
+	JobRunning
+	JobPaused
+	JobFailed
+)
+
+// JobInfo is a snapshot of a background file operation for the jobs panel
+type JobInfo struct {
+	ID         int64
+	Label      string
+	Status     JobStatus
+	Current    string // Name of the item being processed
+	BytesDone  int64
+	BytesTotal int64
+	FilesDone  int
+	FilesTotal int
+	Throughput float64       // Bytes per second (excluding time spent paused)
+	ETA        time.Duration // Zero when unknown
+	Err        string        // Failure reason for JobFailed
+}
+
+// OperationReport is the summary dialog shown after a multi-file operation that had failures
+type OperationReport struct {
+	Active    bool
+	Title     string
+	Message   string       // One-line summary above the lists
+	Succeeded int          // Number of items that went through
+	Skipped   []ReportItem // Items left alone (conflict skipped, copy into itself, ...)
+	Failed    []ReportItem // Items that failed, with the error
+	CanRetry  bool         // Whether "Retry Failed" is offered
+}
+
+// ReportItem is one path in an OperationReport
+type ReportItem struct {
+	Path   string
+	Reason string
+}
+
 type DriveItem struct {
 	Name, Path string
 	Clickable  widget.Clickable
@@ -510,9 +615,10 @@ type SearchHistoryItem struct {
 
 // DetectedDirective represents a parsed search directive for visual display
 type DetectedDirective struct {
-	Type  string // "contents", "ext", "size", "modified", "filename"
-	Value string // The value after the colon
-	Full  string // Full directive string e.g. "contents:foo"
+	Type    string // "contents", "ext", "size", "modified", "filename", "path", ...
+	Value   string // The value after the colon
+	Full    string // Full directive string e.g. "contents:foo"
+	Negated bool   // Written as -contents:foo
 }
 
 // parseDirectivesForDisplay extracts directives from search text for visual feedback
@@ -521,21 +627,33 @@ func parseDirectivesForDisplay(text string) ([]DetectedDirective, string) {
 	var remaining []string
 
 	// Known directive prefixes
-	knownDirectives := []string{"contents:", "ext:", "size:", "modified:", "filename:", "recursive:", "depth:"}
+	knownDirectives := []string{"contents:", "ext:", "size:", "modified:", "filename:", "recursive:", "depth:", "ignore:", "index:",
+		"type:", "perm:", "owner:", "group:", "path:", "created:", "accessed:", "empty:"}
 
 	parts := strings.Fields(text)
 	for _, part := range parts {
 		found := false
+		// Look past grouping parentheses and negation, e.g. "(-ext:go"
+		term := strings.TrimLeft(part, "(")
+		negated := strings.HasPrefix(term, "-")
+		term = strings.TrimLeft(term, "-")
+		// Text modifiers belong to the directive that follows, e.g. "case:contents:TODO"
+		for _, modifier := range []string{"case:", "regex:", "re:"} {
+			if strings.HasPrefix(strings.ToLower(term), modifier) && strings.Contains(term[len(modifier):], ":") {
+				term = term[len(modifier):]
+			}
+		}
 		for _, prefix := range knownDirectives {
-			if strings.HasPrefix(strings.ToLower(part), prefix) {
+			if strings.HasPrefix(strings.ToLower(term), prefix) {
 				dirType := strings.TrimSuffix(prefix, ":")
-				value := part[len(prefix):]
-				// For recursive, empty value is OK (defaults to depth 10)
-				if value != "" || dirType == "recursive" || dirType == "depth" {
+				value := strings.TrimRight(term[len(prefix):], ")")
+				// recursive:, index: and empty: work without a value
+				if value != "" || dirType == "recursive" || dirType == "depth" || dirType == "index" || dirType == "empty" {
 					directives = append(directives, DetectedDirective{
-						Type:  dirType,
-						Value: value,
-						Full:  part,
+						Type:    dirType,
+						Value:   value,
+						Full:    part,
+						Negated: negated,
 					})
 					found = true
 					break
@@ -561,12 +679,45 @@ type State struct {
 	FavList         []FavoriteItem
 	Clipboard       *Clipboard
 	Progress        ProgressState
+	Jobs            []JobInfo // Queued, running, paused and failed background jobs
 	DeleteTargets   []string // Paths to delete (supports multi-select)
 	Drives          []DriveItem
 	IsSearchResult  bool
 	SearchQuery     string
 	Conflict        ConflictState // File conflict dialog state
+	Report          OperationReport // Summary dialog for finished operations
 	// External drag state (for drag from Finder/other apps)
 	ExternalDragActive bool        // True when external drag is in progress
 	ExternalDragPos    image.Point // Current external drag position
+	// Dual-pane mode: the focused pane is drawn from the fields above,
+	// the other one from OtherPane
+	DualPane   bool
+	RightFocus bool     // The right pane has focus
+	OtherPane  PaneView // Pane without focus
+	// Folder comparison: the folders of the compare view, and the dry run
+	// of a sync waiting to be confirmed
+	Compare  CompareView
+	SyncPlan SyncPlan
+}
+
+// PaneView is the read-only contents of the pane without focus in dual-pane mode
+type PaneView struct {
+	Path          string
+	Entries       []UIEntry
+	SelectedIndex int
+}
+
+// CompareView names the folders whose comparison the compare view shows
+type CompareView struct {
+	Left, Right string
+	ByContent   bool // Compared by content hashes rather than size and date
+}
+
+// SyncPlan is the dry run of a folder sync, listed for confirmation
+type SyncPlan struct {
+	Active  bool
+	Title   string
+	Message string       // One-line summary above the lists
+	Copies  []ReportItem // Paths to copy, with the direction as the reason
+	Skipped []ReportItem // Differing paths the sync leaves alone
 }